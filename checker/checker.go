@@ -0,0 +1,618 @@
+/*
+Package checker implements an optional static type checker for GoLox programs.
+
+Type annotations are optional: variables, parameters, return types and the
+fields of classes can be annotated, and the interpreter ignores the annotations.
+The checker infers the types of the rest of the program from the literals, the
+operators and the declarations, and reports what would fail at runtime:
+
+  - operands of the wrong type, with the messages of the runtime errors
+  - calls to values that are not functions or classes, and calls with the
+    wrong number of arguments
+  - values assigned to annotated variables, parameters, returned from annotated
+    functions or assigned to declared fields that do not match the annotation
+  - annotations naming unknown types
+
+The checking is gradual. The values the checker knows nothing about, such as the
+parameters without annotations or the results of native functions, are of the
+any type, which is compatible with every other type. A variable declared without
+an annotation takes the type of its initializer, until it is assigned a value of
+another type. Nothing is reported for code using only values of the any type, so
+a program without annotations is only checked where the types are certain.
+*/
+package checker
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+	"strconv"
+)
+
+// Checker is a visitor that infers the types of the AST and checks them
+type Checker struct {
+	scopes   []map[string]*variable
+	function *function // The innermost function enclosing the current statement, nil at the top level
+	errors   []*loxerr.Error
+}
+
+// A variable in scope. Only the annotated variables keep their type when they
+// are assigned a value of another type
+type variable struct {
+	typ       Type
+	annotated bool
+}
+
+// The function whose body is being checked
+type function struct {
+	result  Type   // The annotated return type, nil if the result is inferred
+	returns []Type // The types of the returned values, to infer the result
+}
+
+// New creates a new Checker
+func New() *Checker {
+	return &Checker{}
+}
+
+// Check infers the types of the given statements and returns the mismatches
+// found in them
+func (c *Checker) Check(statements []stmt.Stmt) []*loxerr.Error {
+	c.errors = nil
+	c.function = nil
+	c.scopes = []map[string]*variable{{}}
+	c.checkStatements(statements)
+
+	return c.errors
+}
+
+// VisitBlockStmt implements the stmt.Visitor interface
+func (c *Checker) VisitBlockStmt(s *stmt.Block) interface{} {
+	c.checkBlock(s.Statements)
+	return nil
+}
+
+// VisitBreakStmt implements the stmt.Visitor interface
+func (c *Checker) VisitBreakStmt(_ *stmt.Break) interface{} {
+	return nil
+}
+
+// VisitClassStmt implements the stmt.Visitor interface
+//
+// The signatures of the members are known before their bodies are checked, so
+// that the methods can call each other through this
+func (c *Checker) VisitClassStmt(s *stmt.Class) interface{} {
+	cls := newClass(s.Name.Lexeme)
+
+	if s.Superclass != nil {
+		if superclass, ok := c.checkExpr(s.Superclass).(classType); ok {
+			cls.superclass = superclass.class
+		} else {
+			cls.open = true
+		}
+	}
+
+	// The members mixed in from a trait the checker does not know are not known
+	var traits []*class
+	for _, trait := range s.Traits {
+		if t, ok := c.checkExpr(trait).(traitType); ok {
+			traits = append(traits, t.class)
+		} else {
+			cls.open = true
+		}
+	}
+
+	c.declare(s.Name, classType{cls}, false)
+
+	for _, field := range s.Fields {
+		cls.fields[field.Name.Lexeme] = c.resolveType(field.Type)
+	}
+
+	// The members the class declares itself replace the ones of the traits
+	for _, trait := range traits {
+		cls.mixin(trait)
+	}
+
+	c.checkMembers(s.Members, cls, instanceType{cls}, classType{cls})
+
+	return nil
+}
+
+// VisitTraitStmt implements the stmt.Visitor interface
+//
+// A trait is mixed into classes the checker does not know of when it checks
+// the trait, so this is of the any type in its methods. The signatures of its
+// members are mixed into the classes using it
+func (c *Checker) VisitTraitStmt(s *stmt.Trait) interface{} {
+	cls := newClass(s.Name.Lexeme)
+	c.declare(s.Name, traitType{cls}, false)
+	c.checkMembers(s.Members, cls, anyType, anyType)
+
+	return nil
+}
+
+// Checks the members of a class or a trait, collecting their signatures into
+// the class. This is of the instance type in the methods, getters and setters,
+// and of the class type in the static methods
+func (c *Checker) checkMembers(members stmt.Members, cls *class, instance, static Type) {
+	signatures := make(map[*stmt.Function]*functionType)
+
+	for _, method := range members.Methods {
+		signatures[method] = c.signature(method.Name.Lexeme, method.Params, method.ReturnType, method.Generator, method.Async)
+		cls.methods[method.Name.Lexeme] = signatures[method]
+	}
+
+	for _, method := range members.StaticMethods {
+		signatures[method] = c.signature(method.Name.Lexeme, method.Params, method.ReturnType, method.Generator, method.Async)
+		cls.static[method.Name.Lexeme] = signatures[method]
+	}
+
+	for _, getter := range members.Getters {
+		signatures[getter] = c.signature(getter.Name.Lexeme, getter.Params, getter.ReturnType, getter.Generator, getter.Async)
+		if _, ok := cls.fields[getter.Name.Lexeme]; !ok {
+			cls.fields[getter.Name.Lexeme] = anyType
+		}
+	}
+
+	for _, setter := range members.Setters {
+		signatures[setter] = c.signature(setter.Name.Lexeme, setter.Params, setter.ReturnType, setter.Generator, setter.Async)
+		if len(signatures[setter].params) > 0 {
+			cls.setters[setter.Name.Lexeme] = signatures[setter].params[0]
+		}
+	}
+
+	c.beginScope()
+	c.scope()["this"] = &variable{typ: instance}
+	for _, methods := range [][]*stmt.Function{members.Methods, members.Getters, members.Setters} {
+		for _, method := range methods {
+			c.checkFunction(signatures[method], method.Params, method.Body, method.ReturnType, method.Name.Lexeme == "init")
+		}
+	}
+
+	// A getter without a return type gives the type it returns, unless a field
+	// of the same name is declared
+	for _, getter := range members.Getters {
+		if cls.fields[getter.Name.Lexeme] == anyType {
+			cls.fields[getter.Name.Lexeme] = signatures[getter].result
+		}
+	}
+
+	c.scope()["this"] = &variable{typ: static}
+	for _, method := range members.StaticMethods {
+		c.checkFunction(signatures[method], method.Params, method.Body, method.ReturnType, false)
+	}
+	c.endScope()
+}
+
+// VisitContinueStmt implements the stmt.Visitor interface
+func (c *Checker) VisitContinueStmt(_ *stmt.Continue) interface{} {
+	return nil
+}
+
+// VisitExpressionStmt implements the stmt.Visitor interface
+func (c *Checker) VisitExpressionStmt(s *stmt.Expression) interface{} {
+	c.checkExpr(s.Expression)
+	return nil
+}
+
+// VisitForInStmt implements the stmt.Visitor interface
+//
+// The loop variables take the types of the keys and the values of the iterable:
+// the indexes and the elements of a list, the keys and the values of a map, the
+// integers of a range and the characters of a string
+func (c *Checker) VisitForInStmt(s *stmt.ForIn) interface{} {
+	iterable := c.checkExpr(s.Iterable)
+
+	key, value := Type(anyType), Type(anyType)
+	switch t := iterable.(type) {
+	case *listType:
+		key, value = numberType, t.element
+	case *mapType:
+		key, value = t.key, t.value
+		if s.Key == nil {
+			value = t.key
+		}
+	case basicType:
+		switch t {
+		case rangeType:
+			key, value = numberType, numberType
+		case stringType:
+			key, value = numberType, stringType
+		case numberType, boolType, nullType:
+			c.error(s.Keyword, "Only lists, maps, strings, ranges and iterables can be iterated.")
+		}
+	}
+
+	c.beginScope()
+	if s.Key != nil {
+		c.declare(s.Key, key, false)
+	}
+	c.declare(s.Name, value, false)
+	c.checkStmt(s.Body)
+	c.endScope()
+
+	return nil
+}
+
+// VisitFunctionStmt implements the stmt.Visitor interface
+func (c *Checker) VisitFunctionStmt(s *stmt.Function) interface{} {
+	signature := c.signature(s.Name.Lexeme, s.Params, s.ReturnType, s.Generator, s.Async)
+	c.declare(s.Name, signature, false)
+	c.checkFunction(signature, s.Params, s.Body, s.ReturnType, false)
+
+	return nil
+}
+
+// VisitIfStmt implements the stmt.Visitor interface
+func (c *Checker) VisitIfStmt(s *stmt.If) interface{} {
+	c.checkExpr(s.Condition)
+	c.checkStmt(s.ThenBranch)
+	c.checkStmt(s.ElseBranch)
+
+	return nil
+}
+
+// VisitImportStmt implements the stmt.Visitor interface
+//
+// Modules are checked on their own, their exports are of the any type
+func (c *Checker) VisitImportStmt(s *stmt.Import) interface{} {
+	if s.Alias != nil {
+		c.declare(s.Alias, anyType, false)
+	}
+
+	for _, name := range s.Names {
+		c.declare(name, anyType, false)
+	}
+
+	return nil
+}
+
+// VisitPrintStmt implements the stmt.Visitor interface
+func (c *Checker) VisitPrintStmt(s *stmt.Print) interface{} {
+	c.checkExpr(s.Expression)
+	return nil
+}
+
+// VisitReturnStmt implements the stmt.Visitor interface
+func (c *Checker) VisitReturnStmt(s *stmt.Return) interface{} {
+	value := Type(nullType)
+	if s.Value != nil {
+		value = c.checkExpr(s.Value)
+	}
+
+	if c.function == nil {
+		return nil
+	}
+
+	if c.function.result != nil && !assignable(c.function.result, value) {
+		c.error(s.Keyword, "Can't return "+value.String()+" from a function returning "+c.function.result.String()+".")
+	}
+	c.function.returns = append(c.function.returns, value)
+
+	return nil
+}
+
+// VisitThrowStmt implements the stmt.Visitor interface
+func (c *Checker) VisitThrowStmt(s *stmt.Throw) interface{} {
+	c.checkExpr(s.Value)
+	return nil
+}
+
+// VisitTryStmt implements the stmt.Visitor interface
+func (c *Checker) VisitTryStmt(s *stmt.Try) interface{} {
+	c.checkBlock(s.Body)
+
+	if s.CatchName != nil {
+		c.beginScope()
+		c.declare(s.CatchName, anyType, false)
+		c.checkStatements(s.Catch)
+		c.endScope()
+	}
+
+	c.checkBlock(s.Finally)
+
+	return nil
+}
+
+// VisitVarStmt implements the stmt.Visitor interface
+//
+// An annotated variable without an initializer starts as null, like any other
+// variable, and is checked when it is assigned
+func (c *Checker) VisitVarStmt(s *stmt.Var) interface{} {
+	value := Type(nullType)
+	if s.Initializer != nil {
+		value = c.checkExpr(s.Initializer)
+	}
+
+	if s.Pattern != nil {
+		c.declarePattern(s.Pattern)
+		return nil
+	}
+
+	if s.Type != nil {
+		annotation := c.resolveType(s.Type)
+		if s.Initializer != nil {
+			c.checkAssignment(s.Name, annotation, value)
+		}
+		c.declare(s.Name, annotation, true)
+		return nil
+	}
+
+	// A variable starting as null is most likely assigned something else later
+	if value == nullType {
+		value = anyType
+	}
+	c.declare(s.Name, value, false)
+
+	return nil
+}
+
+// VisitWhileStmt implements the stmt.Visitor interface
+func (c *Checker) VisitWhileStmt(s *stmt.While) interface{} {
+	c.checkExpr(s.Condition)
+	c.checkStmt(s.Body)
+	c.checkExpr(s.Increment)
+
+	return nil
+}
+
+// VisitYieldStmt implements the stmt.Visitor interface
+func (c *Checker) VisitYieldStmt(s *stmt.Yield) interface{} {
+	c.checkExpr(s.Value)
+	return nil
+}
+
+// Returns the signature of a function from the annotations of its parameters
+// and of its result. Calling a generator gives a generator, and calling an
+// async function a promise of its result. A result without an annotation is of
+// the any type until the body of the function is checked
+func (c *Checker) signature(name string, params []*expr.Param, returnType *expr.Type, generator, async bool) *functionType {
+	signature := &functionType{name: name, result: anyType}
+
+	for _, param := range params {
+		typ := Type(anyType)
+		if param.Type != nil {
+			typ = c.resolveType(param.Type)
+		}
+
+		if param.Rest {
+			signature.rest = typ
+			continue
+		}
+
+		signature.params = append(signature.params, typ)
+		signature.names = append(signature.names, param.Name.Lexeme)
+		if param.Default == nil {
+			signature.required++
+		}
+	}
+
+	if returnType != nil {
+		signature.result = c.resolveType(returnType)
+	}
+
+	switch {
+	case generator:
+		signature.result = generatorType
+	case async:
+		signature.result = &promiseType{value: signature.result}
+	}
+
+	return signature
+}
+
+// Checks the default values of the parameters and the body of a function. The
+// result of a function without a return type, which is not a generator, is
+// inferred from the values it returns
+func (c *Checker) checkFunction(signature *functionType, params []*expr.Param, body []stmt.Stmt, returnType *expr.Type, initializer bool) {
+	enclosing := c.function
+	c.function = &function{}
+	if returnType != nil {
+		c.function.result = c.resolveType(returnType)
+	}
+
+	c.beginScope()
+	for idx, param := range params {
+		if param.Rest {
+			c.declare(param.Name, &listType{element: signature.rest}, param.Type != nil)
+			continue
+		}
+
+		if param.Default != nil {
+			c.checkAssignment(param.Name, signature.params[idx], c.checkExpr(param.Default))
+		}
+		c.declare(param.Name, signature.params[idx], param.Type != nil)
+	}
+	c.checkStatements(body)
+	c.endScope()
+
+	if returnType == nil && signature.result != generatorType && !initializer {
+		returns := c.function.returns
+		if !alwaysReturns(body) {
+			returns = append(returns, nullType)
+		}
+
+		result := union(returns...)
+		if p, ok := signature.result.(*promiseType); ok {
+			p.value = result
+		} else {
+			signature.result = result
+		}
+	}
+
+	c.function = enclosing
+}
+
+// Reports whether the statements always end with a return or a throw statement,
+// in which case the function never returns null by reaching the end of its body
+func alwaysReturns(statements []stmt.Stmt) bool {
+	for _, s := range statements {
+		switch s := s.(type) {
+		case *stmt.Return, *stmt.Throw:
+			return true
+		case *stmt.Block:
+			if alwaysReturns(s.Statements) {
+				return true
+			}
+		case *stmt.If:
+			if s.ElseBranch != nil && alwaysReturns([]stmt.Stmt{s.ThenBranch}) && alwaysReturns([]stmt.Stmt{s.ElseBranch}) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Returns the type an annotation stands for. Unknown names are reported and
+// stand for the any type
+func (c *Checker) resolveType(annotation *expr.Type) Type {
+	if annotation.Name == nil {
+		alternatives := make([]Type, len(annotation.Alternatives))
+		for idx, alternative := range annotation.Alternatives {
+			alternatives[idx] = c.resolveType(alternative)
+		}
+		return union(alternatives...)
+	}
+
+	name := annotation.Name.Lexeme
+	arguments := make([]Type, len(annotation.Arguments))
+	for idx, argument := range annotation.Arguments {
+		arguments[idx] = c.resolveType(argument)
+	}
+
+	// The number of type arguments each parameterized type takes, besides none
+	expected := map[string]int{"list": 1, "map": 2, "promise": 1}[name]
+	if name == "fun" || name == "function" {
+		expected = len(arguments)
+	}
+	if len(arguments) > 0 && len(arguments) != expected {
+		c.error(annotation.Name, "Type '"+name+"' takes "+strconv.Itoa(expected)+" type arguments but got "+strconv.Itoa(len(arguments))+".")
+		return anyType
+	}
+
+	argument := func(idx int) Type {
+		if idx < len(arguments) {
+			return arguments[idx]
+		}
+		return anyType
+	}
+
+	switch name {
+	case "list":
+		return &listType{element: argument(0)}
+	case "map":
+		return &mapType{key: argument(0), value: argument(1)}
+	case "promise":
+		return &promiseType{value: argument(0)}
+	case "fun", "function":
+		if len(arguments) == 0 {
+			return &functionType{result: anyType, unchecked: true}
+		}
+		params := arguments[:len(arguments)-1]
+		return &functionType{params: params, required: len(params), result: arguments[len(arguments)-1]}
+	}
+
+	if basic, ok := basicTypes[name]; ok {
+		return basic
+	}
+
+	if v := c.lookup(name); v != nil {
+		if cls, ok := v.typ.(classType); ok {
+			return instanceType{cls.class}
+		}
+	}
+
+	c.error(annotation.Name, "Unknown type '"+name+"'.")
+
+	return anyType
+}
+
+// Reports a value of the source type assigned to the variable or parameter of
+// the target type that does not accept it
+func (c *Checker) checkAssignment(name *token.Token, target, source Type) {
+	if !assignable(target, source) {
+		c.error(name, "Can't assign "+source.String()+" to '"+name.Lexeme+"' of type "+target.String()+".")
+	}
+}
+
+// Checks the statements of a block in a scope of their own
+func (c *Checker) checkBlock(statements []stmt.Stmt) {
+	c.beginScope()
+	c.checkStatements(statements)
+	c.endScope()
+}
+
+func (c *Checker) checkStatements(statements []stmt.Stmt) {
+	for _, s := range statements {
+		c.checkStmt(s)
+	}
+}
+
+func (c *Checker) checkStmt(s stmt.Stmt) {
+	if s != nil {
+		s.Accept(c)
+	}
+}
+
+// Returns the type of an expression, the any type if there is none
+func (c *Checker) checkExpr(e expr.Expr) Type {
+	if e == nil {
+		return anyType
+	}
+
+	return e.Accept(c).(Type)
+}
+
+func (c *Checker) beginScope() {
+	c.scopes = append(c.scopes, make(map[string]*variable))
+}
+
+func (c *Checker) endScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *Checker) scope() map[string]*variable {
+	return c.scopes[len(c.scopes)-1]
+}
+
+// Declares a variable of the given type in the innermost scope
+func (c *Checker) declare(name *token.Token, typ Type, annotated bool) {
+	c.scope()[name.Lexeme] = &variable{typ: typ, annotated: annotated}
+}
+
+// Declares the variables bound by a pattern in the innermost scope, all of the
+// any type
+func (c *Checker) declarePattern(pattern expr.Pattern) {
+	switch p := pattern.(type) {
+	case *expr.BindingPattern:
+		c.declare(p.Name, anyType, false)
+	case *expr.ListPattern:
+		for _, element := range p.Elements {
+			c.declarePattern(element)
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			c.declare(p.Rest, anyType, false)
+		}
+	case *expr.InstancePattern:
+		for _, field := range p.Fields {
+			c.declarePattern(field.Pattern)
+		}
+	}
+}
+
+// Looks up a variable in the enclosing scopes. Returns nil for the variables
+// that are not declared yet, such as the built-in functions
+func (c *Checker) lookup(name string) *variable {
+	for idx := len(c.scopes) - 1; idx >= 0; idx-- {
+		if v, ok := c.scopes[idx][name]; ok {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) error(t *token.Token, message string) {
+	c.errors = append(c.errors, loxerr.New(t, message))
+}
@@ -0,0 +1,179 @@
+package checker
+
+import (
+	"golox/lexer"
+	"golox/parser"
+	"testing"
+)
+
+func TestChecker_Check(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         string
+		expectedErrors []string
+	}{
+		{name: "Annotated variable", source: "var x: number = 1; x = 2; var s: string; s = \"a\";"},
+		{name: "Unannotated code", source: "fun f(a, b) { return a + b; } var x = f(1, 2) + f(\"a\", \"b\"); x = \"now a string\";"},
+		{name: "Inferred types", source: "var x = 1; var y: number = x * 2; var s: string = \"a\" + \"b\"; var b: bool = x < y;"},
+		{name: "Unions", source: "var x: number | null = null; x = 1; var y: number | string = \"a\"; var z: number | string | null = y;"},
+		{name: "Lists and maps", source: "var xs: list[number] = [1, 2]; var first: number = xs[0]; var m: map[string, list[number]] = {\"a\": xs}; m[\"b\"] = [];"},
+		{name: "Classes", source: "class A { x: number; init(x: number) { this.x = x; } get(): number { return this.x; } } var a: A = A(1); var n: number = a.get() + a.x;"},
+		{name: "Subclasses", source: "class A {} class B < A {} var a: A = B(); fun f(a: A) {} f(B());"},
+		{name: "Function types", source: "fun twice(f: fun[number, number], x: number): number { return f(f(x)); } twice((x) => x * 2, 1); var g: fun = twice;"},
+		{name: "Async functions", source: "async fun f(): number { return 1; } var p: promise[number] = f(); var n: number = await f();"},
+		{name: "Loop variables", source: "for (var i in 1..3) { var n: number = i; } for (var k, v in {\"a\": true}) { var s: string = k; var b: bool = v; }"},
+		{name: "Inferred results", source: "fun f(x) { if (x) { return 1; } else { return 2; } } var n: number = f(true);"},
+		{name: "Unknown superclass", source: "class E < Error {} E(\"message\");"},
+		{name: "Variadic functions", source: "fun f(a: number, b = 2, ...rest: string) {} f(1); f(1, 2, \"a\", \"b\"); f(b: 3, a: 1);"},
+		{name: "Overloaded operators", source: "class V { __add(other) { return this; } } var v = V() + 1;"},
+		{name: "Arrow function annotations", source: "var f = (a: number): number => a * 2; var n: number = f(1); var g: fun[string, string] = (s: string) => s;"},
+		{name: "Trait methods", source: "trait Named { name(): string { return \"n\"; } } class A with Named { name(): number { return 1; } } var n: number = A().name();"},
+		{
+			name:           "Variable of the wrong type",
+			source:         "var x: number = \"one\";\nx = true;",
+			expectedErrors: []string{"[Pos 1:5] Error at 'x': Can't assign string to 'x' of type number.", "[Pos 2:1] Error at 'x': Can't assign bool to 'x' of type number."},
+		},
+		{
+			name:           "Binary operands",
+			source:         "var s = \"a\" + 1; var n = 1 - \"a\"; var r = 1..\"a\";",
+			expectedErrors: []string{"[Pos 1:13] Error at '+': Operands must be two numbers or two strings.", "[Pos 1:28] Error at '-': Operands must be numbers.", "[Pos 1:44] Error at '..': Operands must be integers."},
+		},
+		{
+			name:           "Unary operands",
+			source:         "-\"a\"; ~true; var s = \"a\"; s++;",
+			expectedErrors: []string{"[Pos 1:1] Error at '-': Operand must be a number.", "[Pos 1:7] Error at '~': Operand must be an integer.", "[Pos 1:28] Error at '++': Operand must be a number."},
+		},
+		{
+			name:   "Arguments",
+			source: "fun add(a: number, b: number): number { return a + b; }\nadd(1, \"two\");\nadd(1);\nadd(1, 2, 3);",
+			expectedErrors: []string{
+				"[Pos 2:13] Error at ')': Can't pass string to parameter 'b' of type number.",
+				"[Pos 3:6] Error at ')': Missing argument for parameter 'b' of add.",
+				"[Pos 4:12] Error at ')': Expected 2 arguments but got 3.",
+			},
+		},
+		{
+			name:           "Constructor arguments",
+			source:         "class P { init(x: number) {} } P(\"a\"); class Q {} Q(1);",
+			expectedErrors: []string{"[Pos 1:37] Error at ')': Can't pass string to parameter 'x' of type number.", "[Pos 1:54] Error at ')': Expected 0 arguments but got 1."},
+		},
+		{
+			name:           "Named arguments",
+			source:         "fun f(a: number, b: number = 1) {} f(c: 1); f(1, b: \"x\");",
+			expectedErrors: []string{"[Pos 1:38] Error at 'c': Unexpected named argument 'c' for f.", "[Pos 1:42] Error at ')': Missing argument for parameter 'a' of f.", "[Pos 1:50] Error at 'b': Can't pass string to parameter 'b' of type number."},
+		},
+		{
+			name:           "Calling a value that is not a function",
+			source:         "var n = 1; n(); [](); \"f\"();",
+			expectedErrors: []string{"[Pos 1:14] Error at ')': Can only call functions and classes.", "[Pos 1:20] Error at ')': Can only call functions and classes.", "[Pos 1:27] Error at ')': Can only call functions and classes."},
+		},
+		{
+			name:           "Return type",
+			source:         "fun f(): string { return 1; }\nfun g(): number { return; }",
+			expectedErrors: []string{"[Pos 1:19] Error at 'return': Can't return number from a function returning string.", "[Pos 2:19] Error at 'return': Can't return null from a function returning number."},
+		},
+		{
+			name:           "Inferred result",
+			source:         "fun f() { return \"a\"; } var n: number = f(); fun g(x) { if (x) return 1; } var m: number = g(true);",
+			expectedErrors: []string{"[Pos 1:29] Error at 'n': Can't assign string to 'n' of type number.", "[Pos 1:80] Error at 'm': Can't assign number | null to 'm' of type number."},
+		},
+		{
+			name:           "Field types",
+			source:         "class P { x: number; init() { this.x = \"a\"; } } var p = P(); p.x = null;",
+			expectedErrors: []string{"[Pos 1:36] Error at 'x': Can't assign string to field 'x' of type number.", "[Pos 1:64] Error at 'x': Can't assign null to field 'x' of type number."},
+		},
+		{
+			name:           "Inherited field types",
+			source:         "class A { x: number; } class B < A {} B().x = \"a\";",
+			expectedErrors: []string{"[Pos 1:43] Error at 'x': Can't assign string to field 'x' of type number."},
+		},
+		{
+			name:           "Parameter types",
+			source:         "fun f(a: number = \"a\") { a = \"b\"; }",
+			expectedErrors: []string{"[Pos 1:7] Error at 'a': Can't assign string to 'a' of type number.", "[Pos 1:26] Error at 'a': Can't assign string to 'a' of type number."},
+		},
+		{
+			name:           "Element types",
+			source:         "var xs: list[number] = [1, \"a\"]; var ys = [1]; ys[0] = \"b\"; var m = {\"a\": 1}; m[\"b\"] = true;",
+			expectedErrors: []string{"[Pos 1:5] Error at 'xs': Can't assign list[number | string] to 'xs' of type list[number].", "[Pos 1:52] Error at ']': Can't assign string to an element of list[number].", "[Pos 1:84] Error at ']': Can't assign bool to a value of map[string, number]."},
+		},
+		{
+			name:           "Subclass expected",
+			source:         "class A {} class B {} var a: A = B();",
+			expectedErrors: []string{"[Pos 1:27] Error at 'a': Can't assign B to 'a' of type A."},
+		},
+		{
+			name:           "Function type",
+			source:         "var f: fun[number, string] = (x: number) => x;",
+			expectedErrors: []string{"[Pos 1:5] Error at 'f': Can't assign fun[number, number] to 'f' of type fun[number, string]."},
+		},
+		{
+			name:           "Await",
+			source:         "async fun f() { return 1; } var s: string = await f();",
+			expectedErrors: []string{"[Pos 1:33] Error at 's': Can't assign number to 's' of type string."},
+		},
+		{
+			name:           "Compound assignment",
+			source:         "var s: string = \"a\"; s += 1; var n: number = 1; n += \"a\";",
+			expectedErrors: []string{"[Pos 1:24] Error at '+=': Operands must be two numbers or two strings.", "[Pos 1:51] Error at '+=': Operands must be two numbers or two strings."},
+		},
+		{
+			name:           "Iterating a number",
+			source:         "for (var x in 5) {}",
+			expectedErrors: []string{"[Pos 1:12] Error at 'in': Only lists, maps, strings, ranges and iterables can be iterated."},
+		},
+		{
+			name:           "Indexing",
+			source:         "var n = 1; n[0]; n[0] = 1;",
+			expectedErrors: []string{"[Pos 1:15] Error at ']': Only lists, strings and maps can be indexed.", "[Pos 1:21] Error at ']': Only lists and maps support index assignment."},
+		},
+		{
+			name:           "Setter parameter types",
+			source:         "class C { set celsius(c: number) {} } class D < C {} var c = C(); c.celsius = 1; c.celsius = \"hot\"; D().celsius = null;",
+			expectedErrors: []string{"[Pos 1:84] Error at 'celsius': Can't assign string to setter 'celsius' taking number.", "[Pos 1:105] Error at 'celsius': Can't assign null to setter 'celsius' taking number."},
+		},
+		{
+			name:           "Trait members",
+			source:         "trait Greets { greet(name: string): string { return \"hi \" + name; } set volume(v: number) {} }\nclass A with Greets {}\nvar n: number = A().greet(\"x\");\nA().greet(1);\nA().volume = \"loud\";",
+			expectedErrors: []string{"[Pos 3:5] Error at 'n': Can't assign string to 'n' of type number.", "[Pos 4:12] Error at ')': Can't pass number to parameter 'name' of type string.", "[Pos 5:5] Error at 'volume': Can't assign string to setter 'volume' taking number."},
+		},
+		{
+			name:           "Arrow function return type",
+			source:         "var f = (a: number): string => a;",
+			expectedErrors: []string{"[Pos 1:29] Error at '=>': Can't return number from a function returning string."},
+		},
+		{
+			name:           "Unknown type",
+			source:         "var x: Foo; fun f(a: list[Bar]) {}",
+			expectedErrors: []string{"[Pos 1:8] Error at 'Foo': Unknown type 'Foo'.", "[Pos 1:27] Error at 'Bar': Unknown type 'Bar'."},
+		},
+		{
+			name:           "Type arguments",
+			source:         "var x: map[string];",
+			expectedErrors: []string{"[Pos 1:8] Error at 'map': Type 'map' takes 2 type arguments but got 1."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.source)
+			l.ScanTokens()
+
+			statements, parseErrors := parser.New(l.Tokens).ParseProgram()
+			if len(parseErrors) > 0 {
+				t.Fatalf("Parse errors: %v", parseErrors)
+			}
+
+			errs := New().Check(statements)
+			if len(errs) != len(tt.expectedErrors) {
+				t.Fatalf("Expected %d errors but got %v", len(tt.expectedErrors), errs)
+			}
+
+			for idx, err := range errs {
+				if err.Error() != tt.expectedErrors[idx] {
+					t.Errorf("Expected error '%s' but got '%s'", tt.expectedErrors[idx], err.Error())
+				}
+			}
+		})
+	}
+}
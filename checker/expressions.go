@@ -0,0 +1,551 @@
+package checker
+
+import (
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+	"strconv"
+)
+
+// The binary operators applied by the compound assignments
+var compoundOperators = map[token.Type]token.Type{
+	token.PLUS_EQUAL:    token.PLUS,
+	token.MINUS_EQUAL:   token.MINUS,
+	token.STAR_EQUAL:    token.STAR,
+	token.SLASH_EQUAL:   token.SLASH,
+	token.PERCENT_EQUAL: token.PERCENT,
+}
+
+// VisitAssignExpr implements the expr.Visitor interface
+func (c *Checker) VisitAssignExpr(e *expr.Assign) interface{} {
+	value := c.checkExpr(e.Value)
+	c.assign(e.Name, value)
+
+	return value
+}
+
+// VisitBinaryExpr implements the expr.Visitor interface
+func (c *Checker) VisitBinaryExpr(e *expr.Binary) interface{} {
+	left := c.checkExpr(e.Left)
+	right := c.checkExpr(e.Right)
+
+	return c.binary(e.Operator, e.Operator.Type, left, right)
+}
+
+// Returns the type of a binary operation, reporting operands that can't be of
+// the types the operator needs. Instances can overload the operators, so any
+// operand that might be an instance makes the result unknown
+func (c *Checker) binary(operator *token.Token, op token.Type, left, right Type) Type {
+	switch op {
+	case token.EQUAL_EQUAL, token.BANG_EQUAL:
+		return boolType
+	}
+
+	if mightBeInstance(left) || mightBeInstance(right) {
+		return anyType
+	}
+
+	numbers := mightBe(left, numberType) && mightBe(right, numberType)
+
+	switch op {
+	case token.PLUS:
+		var results []Type
+		if numbers {
+			results = append(results, numberType)
+		}
+		if mightBe(left, stringType) && mightBe(right, stringType) {
+			results = append(results, stringType)
+		}
+		if len(results) == 0 {
+			c.error(operator, "Operands must be two numbers or two strings.")
+		}
+		return union(results...)
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LESS_LESS, token.GREATER_GREATER:
+		if !numbers {
+			c.error(operator, "Operands must be integers.")
+		}
+		return numberType
+	case token.DOT_DOT, token.DOT_DOT_LESS:
+		if !numbers {
+			c.error(operator, "Operands must be integers.")
+		}
+		return rangeType
+	}
+
+	if !numbers {
+		c.error(operator, "Operands must be numbers.")
+	}
+
+	switch op {
+	case token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL:
+		return boolType
+	}
+
+	return numberType
+}
+
+// VisitCallExpr implements the expr.Visitor interface
+func (c *Checker) VisitCallExpr(e *expr.Call) interface{} {
+	callee := c.checkExpr(e.Callee)
+
+	arguments := make([]Type, len(e.Arguments))
+	for idx, argument := range e.Arguments {
+		arguments[idx] = c.checkExpr(argument)
+	}
+
+	named := make([]Type, len(e.Named))
+	for idx, argument := range e.Named {
+		named[idx] = c.checkExpr(argument.Value)
+	}
+
+	var signature *functionType
+	switch t := callee.(type) {
+	case *functionType:
+		signature = t
+	case classType:
+		signature = t.constructor()
+	case basicType:
+		if t != anyType {
+			c.error(e.Paren, "Can only call functions and classes.")
+		}
+		return anyType
+	case *listType, *mapType, *promiseType:
+		c.error(e.Paren, "Can only call functions and classes.")
+		return anyType
+	default:
+		// Instances can be called through their __call method
+		return anyType
+	}
+
+	if !signature.unchecked {
+		c.checkArguments(e, signature, arguments, named)
+	}
+
+	return signature.result
+}
+
+// Checks the number and the types of the arguments of a call, reporting the
+// mismatches with the messages of the runtime errors
+func (c *Checker) checkArguments(e *expr.Call, signature *functionType, arguments, named []Type) {
+	fixed := len(signature.params)
+	if len(arguments) > fixed && signature.rest == nil {
+		if signature.required == fixed {
+			c.error(e.Paren, "Expected "+strconv.Itoa(fixed)+" arguments but got "+strconv.Itoa(len(arguments))+".")
+		} else {
+			c.error(e.Paren, "Expected at most "+strconv.Itoa(fixed)+" arguments but got "+strconv.Itoa(len(arguments))+".")
+		}
+		return
+	}
+
+	given := make([]bool, fixed)
+	for idx, argument := range arguments {
+		expected := signature.rest
+		if idx < fixed {
+			expected, given[idx] = signature.params[idx], true
+		}
+
+		if !assignable(expected, argument) {
+			c.error(e.Paren, "Can't pass "+argument.String()+" to "+signature.parameter(idx)+" of type "+expected.String()+".")
+		}
+	}
+
+	c.checkNamedArguments(e, signature, named, given)
+
+	for idx := 0; idx < signature.required; idx++ {
+		if given[idx] {
+			continue
+		}
+
+		if idx < len(signature.names) {
+			c.error(e.Paren, "Missing argument for parameter '"+signature.names[idx]+"' of "+signature.name+".")
+		} else {
+			c.error(e.Paren, "Expected "+strconv.Itoa(fixed)+" arguments but got "+strconv.Itoa(len(arguments))+".")
+		}
+		return
+	}
+}
+
+// Checks the types of the named arguments of a call, marking the parameters
+// they are passed to as given
+func (c *Checker) checkNamedArguments(e *expr.Call, signature *functionType, named []Type, given []bool) {
+	for idx, argument := range e.Named {
+		position := -1
+		for i, name := range signature.names {
+			if name == argument.Name.Lexeme {
+				position = i
+			}
+		}
+
+		if position < 0 {
+			if len(signature.names) > 0 || signature.rest != nil {
+				c.error(argument.Name, "Unexpected named argument '"+argument.Name.Lexeme+"' for "+signature.name+".")
+			}
+			continue
+		}
+
+		given[position] = true
+		if !assignable(signature.params[position], named[idx]) {
+			c.error(argument.Name, "Can't pass "+named[idx].String()+" to "+signature.parameter(position)+" of type "+signature.params[position].String()+".")
+		}
+	}
+}
+
+// VisitSpawnExpr implements the expr.Visitor interface
+func (c *Checker) VisitSpawnExpr(e *expr.Spawn) interface{} {
+	c.VisitCallExpr(e.Call)
+	return taskType
+}
+
+// VisitAwaitExpr implements the expr.Visitor interface
+//
+// Awaiting a promise gives the value it is fulfilled with, awaiting any other
+// value gives the value
+func (c *Checker) VisitAwaitExpr(e *expr.Await) interface{} {
+	value := c.checkExpr(e.Value)
+	if p, ok := value.(*promiseType); ok {
+		return p.value
+	}
+
+	return value
+}
+
+// VisitGetExpr implements the expr.Visitor interface
+//
+// The properties of instances can be set anywhere, only the declared fields,
+// the getters and the methods of a class are known
+func (c *Checker) VisitGetExpr(e *expr.Get) interface{} {
+	return property(c.checkExpr(e.Object), e.Name)
+}
+
+// Returns the type of a property of an object, the any type if it is unknown
+func property(object Type, name *token.Token) Type {
+	switch o := object.(type) {
+	case instanceType:
+		if member := o.member(name.Lexeme); member != nil {
+			return member
+		}
+	case classType:
+		for cls := o.class; cls != nil; cls = cls.superclass {
+			if method, ok := cls.static[name.Lexeme]; ok {
+				return method
+			}
+		}
+	}
+
+	return anyType
+}
+
+// VisitGroupingExpr implements the expr.Visitor interface
+func (c *Checker) VisitGroupingExpr(e *expr.Grouping) interface{} {
+	return c.checkExpr(e.Expression)
+}
+
+// VisitLiteralExpr implements the expr.Visitor interface
+func (c *Checker) VisitLiteralExpr(e *expr.Literal) interface{} {
+	switch e.Value.(type) {
+	case nil:
+		return nullType
+	case bool:
+		return boolType
+	case float64:
+		return numberType
+	case string:
+		return stringType
+	}
+
+	return anyType
+}
+
+// VisitLogicalExpr implements the expr.Visitor interface
+//
+// Logical operators return one of their operands
+func (c *Checker) VisitLogicalExpr(e *expr.Logical) interface{} {
+	left := c.checkExpr(e.Left)
+	right := c.checkExpr(e.Right)
+
+	return union(left, right)
+}
+
+// VisitSetExpr implements the expr.Visitor interface
+func (c *Checker) VisitSetExpr(e *expr.Set) interface{} {
+	object := c.checkExpr(e.Object)
+	value := c.checkExpr(e.Value)
+	c.setProperty(object, e.Name, value)
+
+	return value
+}
+
+// Reports a value assigned to a property of an instance that the parameter of
+// its setter, or the type of its declared field, does not accept
+func (c *Checker) setProperty(object Type, name *token.Token, value Type) {
+	instance, ok := object.(instanceType)
+	if !ok {
+		return
+	}
+
+	for cls := instance.class; cls != nil; cls = cls.superclass {
+		if setter, ok := cls.setters[name.Lexeme]; ok {
+			if !assignable(setter, value) {
+				c.error(name, "Can't assign "+value.String()+" to setter '"+name.Lexeme+"' taking "+setter.String()+".")
+			}
+			return
+		}
+		if field, ok := cls.fields[name.Lexeme]; ok {
+			if !assignable(field, value) {
+				c.error(name, "Can't assign "+value.String()+" to field '"+name.Lexeme+"' of type "+field.String()+".")
+			}
+			return
+		}
+	}
+}
+
+// VisitSuperExpr implements the expr.Visitor interface
+func (c *Checker) VisitSuperExpr(_ *expr.Super) interface{} {
+	return anyType
+}
+
+// VisitThisExpr implements the expr.Visitor interface
+func (c *Checker) VisitThisExpr(_ *expr.This) interface{} {
+	if v := c.lookup("this"); v != nil {
+		return v.typ
+	}
+
+	return anyType
+}
+
+// VisitUnaryExpr implements the expr.Visitor interface
+func (c *Checker) VisitUnaryExpr(e *expr.Unary) interface{} {
+	right := c.checkExpr(e.Right)
+
+	switch e.Operator.Type {
+	case token.BANG:
+		return boolType
+	case token.MINUS:
+		if mightBeInstance(right) {
+			return anyType
+		}
+		if !mightBe(right, numberType) {
+			c.error(e.Operator, "Operand must be a number.")
+		}
+	case token.TILDE:
+		if !mightBe(right, numberType) {
+			c.error(e.Operator, "Operand must be an integer.")
+		}
+	}
+
+	return numberType
+}
+
+// VisitVariableExpr implements the expr.Visitor interface
+func (c *Checker) VisitVariableExpr(e *expr.Variable) interface{} {
+	if v := c.lookup(e.Name.Lexeme); v != nil {
+		return v.typ
+	}
+
+	return anyType
+}
+
+// VisitTernaryExpr implements the expr.Visitor interface
+func (c *Checker) VisitTernaryExpr(e *expr.Ternary) interface{} {
+	c.checkExpr(e.Condition)
+	trueBranch := c.checkExpr(e.TrueBranch)
+	falseBranch := c.checkExpr(e.FalseBranch)
+
+	return union(trueBranch, falseBranch)
+}
+
+// VisitListExpr implements the expr.Visitor interface
+//
+// The element type is the union of the types of the elements, an empty list
+// can hold anything
+func (c *Checker) VisitListExpr(e *expr.List) interface{} {
+	elements := make([]Type, len(e.Elements))
+	for idx, element := range e.Elements {
+		elements[idx] = c.checkExpr(element)
+	}
+
+	return &listType{element: union(elements...)}
+}
+
+// VisitIndexExpr implements the expr.Visitor interface
+func (c *Checker) VisitIndexExpr(e *expr.Index) interface{} {
+	object := c.checkExpr(e.Object)
+	c.checkExpr(e.Index)
+
+	switch o := object.(type) {
+	case *listType:
+		return o.element
+	case *mapType:
+		return o.value
+	case basicType:
+		switch o {
+		case stringType:
+			return stringType
+		case numberType, boolType, nullType:
+			c.error(e.Bracket, "Only lists, strings and maps can be indexed.")
+		}
+	}
+
+	return anyType
+}
+
+// VisitSetIndexExpr implements the expr.Visitor interface
+func (c *Checker) VisitSetIndexExpr(e *expr.SetIndex) interface{} {
+	object := c.checkExpr(e.Object)
+	c.checkExpr(e.Index)
+	value := c.checkExpr(e.Value)
+
+	switch o := object.(type) {
+	case *listType:
+		if !assignable(o.element, value) {
+			c.error(e.Bracket, "Can't assign "+value.String()+" to an element of "+o.String()+".")
+		}
+	case *mapType:
+		if !assignable(o.value, value) {
+			c.error(e.Bracket, "Can't assign "+value.String()+" to a value of "+o.String()+".")
+		}
+	case basicType:
+		if o != anyType {
+			c.error(e.Bracket, "Only lists and maps support index assignment.")
+		}
+	}
+
+	return value
+}
+
+// VisitSliceExpr implements the expr.Visitor interface
+func (c *Checker) VisitSliceExpr(e *expr.Slice) interface{} {
+	object := c.checkExpr(e.Object)
+	c.checkExpr(e.Start)
+	c.checkExpr(e.End)
+
+	if _, ok := object.(*listType); ok || object == stringType {
+		return object
+	}
+
+	return anyType
+}
+
+// VisitMapExpr implements the expr.Visitor interface
+func (c *Checker) VisitMapExpr(e *expr.Map) interface{} {
+	keys := make([]Type, len(e.Keys))
+	values := make([]Type, len(e.Values))
+	for idx := range e.Keys {
+		keys[idx] = c.checkExpr(e.Keys[idx])
+		values[idx] = c.checkExpr(e.Values[idx])
+	}
+
+	return &mapType{key: union(keys...), value: union(values...)}
+}
+
+// VisitCompoundAssignExpr implements the expr.Visitor interface
+func (c *Checker) VisitCompoundAssignExpr(e *expr.CompoundAssign) interface{} {
+	target, assign := c.checkTarget(e.Target)
+	value := c.checkExpr(e.Value)
+
+	result := c.binary(e.Operator, compoundOperators[e.Operator.Type], target, value)
+	assign(result)
+
+	return result
+}
+
+// VisitUpdateExpr implements the expr.Visitor interface
+func (c *Checker) VisitUpdateExpr(e *expr.Update) interface{} {
+	if !mightBe(c.checkExpr(e.Target), numberType) {
+		c.error(e.Operator, "Operand must be a number.")
+	}
+
+	return numberType
+}
+
+// VisitLambdaExpr implements the expr.Visitor interface
+func (c *Checker) VisitLambdaExpr(e *expr.Lambda) interface{} {
+	signature := c.signature("anonymous", e.Params, e.ReturnType, e.Generator, e.Async)
+	c.checkFunction(signature, e.Params, stmt.Statements(e.Body), e.ReturnType, false)
+
+	return signature
+}
+
+// VisitMatchExpr implements the expr.Visitor interface
+//
+// A match evaluates to the body of one of its arms, an arm with a block body to null
+func (c *Checker) VisitMatchExpr(e *expr.Match) interface{} {
+	c.checkExpr(e.Subject)
+
+	results := make([]Type, len(e.Arms))
+	for idx, arm := range e.Arms {
+		c.beginScope()
+		c.declarePattern(arm.Pattern)
+		c.checkExpr(arm.Guard)
+
+		if arm.Block != nil {
+			c.checkStatements(stmt.Statements(arm.Block))
+			results[idx] = nullType
+		} else {
+			results[idx] = c.checkExpr(arm.Body)
+		}
+		c.endScope()
+	}
+
+	return union(results...)
+}
+
+// VisitDestructureExpr implements the expr.Visitor interface
+func (c *Checker) VisitDestructureExpr(e *expr.Destructure) interface{} {
+	value := c.checkExpr(e.Value)
+	c.destructure(e.Pattern, value)
+
+	return value
+}
+
+// Checks the targets of a destructuring assignment. The targets of a list take
+// its element type
+func (c *Checker) destructure(pattern *expr.ListPattern, value Type) {
+	element := Type(anyType)
+	if list, ok := value.(*listType); ok {
+		element = list.element
+	}
+
+	for _, p := range pattern.Elements {
+		switch p := p.(type) {
+		case *expr.TargetPattern:
+			_, assign := c.checkTarget(p.Target)
+			assign(element)
+		case *expr.ListPattern:
+			c.destructure(p, element)
+		}
+	}
+}
+
+// Checks the target of an assignment, a variable, a property or an index.
+// Returns the type of the target and a function checking the assignment of a
+// value to it
+func (c *Checker) checkTarget(target expr.Expr) (Type, func(value Type)) {
+	switch t := target.(type) {
+	case *expr.Variable:
+		return c.checkExpr(t), func(value Type) {
+			c.assign(t.Name, value)
+		}
+	case *expr.Get:
+		object := c.checkExpr(t.Object)
+		return property(object, t.Name), func(value Type) {
+			c.setProperty(object, t.Name, value)
+		}
+	}
+
+	return c.checkExpr(target), func(Type) {}
+}
+
+// Assigns a value of the given type to a variable. An annotated variable must
+// accept the value, any other variable that does not is of the any type from
+// then on
+func (c *Checker) assign(name *token.Token, value Type) {
+	v := c.lookup(name.Lexeme)
+	switch {
+	case v == nil:
+	case v.annotated:
+		c.checkAssignment(name, v.typ, value)
+	case !assignable(v.typ, value):
+		v.typ = anyType
+	}
+}
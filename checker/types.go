@@ -0,0 +1,420 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type is the static type of an expression. The any type stands for the values
+// the checker knows nothing about, it is compatible with every other type
+type Type interface {
+	String() string
+}
+
+// A type without parameters, named like its annotation
+type basicType string
+
+const (
+	anyType       basicType = "any"
+	numberType    basicType = "number"
+	stringType    basicType = "string"
+	boolType      basicType = "bool"
+	nullType      basicType = "null"
+	rangeType     basicType = "range"
+	channelType   basicType = "channel"
+	generatorType basicType = "generator"
+	iteratorType  basicType = "iterator"
+	taskType      basicType = "task"
+)
+
+func (t basicType) String() string {
+	return string(t)
+}
+
+// The basic types by the names they are annotated with. The runtime calls
+// booleans "boolean", so both names are accepted
+var basicTypes = map[string]basicType{
+	"any":       anyType,
+	"number":    numberType,
+	"string":    stringType,
+	"bool":      boolType,
+	"boolean":   boolType,
+	"null":      nullType,
+	"range":     rangeType,
+	"channel":   channelType,
+	"generator": generatorType,
+	"iterator":  iteratorType,
+	"task":      taskType,
+}
+
+// A list whose elements are all of the element type, annotated "list[T]"
+type listType struct {
+	element Type
+}
+
+func (t *listType) String() string {
+	return "list[" + t.element.String() + "]"
+}
+
+// A map from keys of the key type to values of the value type, annotated "map[K, V]"
+type mapType struct {
+	key, value Type
+}
+
+func (t *mapType) String() string {
+	return "map[" + t.key.String() + ", " + t.value.String() + "]"
+}
+
+// A promise fulfilled with a value of the value type, annotated "promise[T]"
+type promiseType struct {
+	value Type
+}
+
+func (t *promiseType) String() string {
+	return "promise[" + t.value.String() + "]"
+}
+
+// The signature of a function. A function annotated "fun" without arguments is
+// unchecked, it can be called with any arguments. The result of a function
+// declared without a return type is inferred once its body is checked
+type functionType struct {
+	name      string
+	params    []Type   // Types of the parameters before the rest parameter
+	names     []string // Names of the parameters, empty for annotated function types
+	required  int      // Number of parameters without a default value
+	rest      Type     // Element type of the rest parameter, nil if there is none
+	result    Type
+	unchecked bool
+}
+
+func (t *functionType) String() string {
+	if t.unchecked {
+		return "fun"
+	}
+
+	parts := make([]string, 0, len(t.params)+1)
+	for _, param := range t.params {
+		parts = append(parts, param.String())
+	}
+
+	return "fun[" + strings.Join(append(parts, t.result.String()), ", ") + "]"
+}
+
+// Describes the parameter at the index in the messages, by its name if the
+// function has named parameters
+func (t *functionType) parameter(idx int) string {
+	if idx < len(t.names) {
+		return "parameter '" + t.names[idx] + "'"
+	}
+
+	return "argument " + strconv.Itoa(idx+1)
+}
+
+// A class declared in the checked program
+type class struct {
+	name       string
+	superclass *class
+	fields     map[string]Type          // Declared types of the properties of the instances
+	methods    map[string]*functionType // Methods and getters of the instances
+	setters    map[string]Type          // Types of the parameters of the setters
+	static     map[string]*functionType // Static methods of the class
+	open       bool                     // Whether the class extends a class or mixes in traits the checker does not know
+}
+
+// Creates a class without members
+func newClass(name string) *class {
+	return &class{
+		name:    name,
+		fields:  make(map[string]Type),
+		methods: make(map[string]*functionType),
+		setters: make(map[string]Type),
+		static:  make(map[string]*functionType),
+	}
+}
+
+// Mixes the members of a trait into the class, except the ones the class
+// declares itself
+func (c *class) mixin(trait *class) {
+	for name, field := range trait.fields {
+		if _, ok := c.fields[name]; !ok {
+			c.fields[name] = field
+		}
+	}
+	for name, method := range trait.methods {
+		if _, ok := c.methods[name]; !ok {
+			c.methods[name] = method
+		}
+	}
+	for name, setter := range trait.setters {
+		if _, ok := c.setters[name]; !ok {
+			c.setters[name] = setter
+		}
+	}
+	for name, method := range trait.static {
+		if _, ok := c.static[name]; !ok {
+			c.static[name] = method
+		}
+	}
+}
+
+// Looks up a member of the instances of the class, in the class or in one of its
+// superclasses. Returns nil if none of them declares it
+func (c *class) member(name string) Type {
+	for ; c != nil; c = c.superclass {
+		if field, ok := c.fields[name]; ok {
+			return field
+		}
+		if method, ok := c.methods[name]; ok {
+			return method
+		}
+	}
+
+	return nil
+}
+
+// Returns the initializer of the instances, nil if there is none. The
+// initializer inherited from an unknown class is unchecked
+func (c *class) initializer() *functionType {
+	for ; c != nil; c = c.superclass {
+		if init, ok := c.methods["init"]; ok {
+			return init
+		}
+		if c.open {
+			return &functionType{result: anyType, unchecked: true}
+		}
+	}
+
+	return nil
+}
+
+// Reports whether the class is the other class or one of its subclasses
+func (c *class) extends(other *class) bool {
+	for ; c != nil; c = c.superclass {
+		if c == other {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The type of a trait itself, whose members are mixed into the classes using it
+type traitType struct {
+	*class
+}
+
+func (t traitType) String() string {
+	return "trait " + t.name
+}
+
+// The type of a class itself, calling it creates an instance
+type classType struct {
+	*class
+}
+
+func (t classType) String() string {
+	return "class " + t.name
+}
+
+// Returns the signature of a call to the class, the one of its initializer
+// returning an instance
+func (t classType) constructor() *functionType {
+	signature := &functionType{name: t.name, names: []string{}, result: instanceType{t.class}}
+	if init := t.initializer(); init != nil {
+		signature.params, signature.names, signature.required = init.params, init.names, init.required
+		signature.rest, signature.unchecked = init.rest, init.unchecked
+	}
+
+	return signature
+}
+
+// The type of the instances of a class and of its subclasses, annotated with
+// the name of the class
+type instanceType struct {
+	*class
+}
+
+func (t instanceType) String() string {
+	return t.name
+}
+
+// A value of any of the alternatives, annotated "A | B"
+type unionType struct {
+	alternatives []Type
+}
+
+func (t *unionType) String() string {
+	parts := make([]string, len(t.alternatives))
+	for idx, alternative := range t.alternatives {
+		parts[idx] = alternative.String()
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// Returns the union of the types, flattening the unions among them and leaving
+// out the duplicates. The union of a single type is the type itself
+func union(types ...Type) Type {
+	var alternatives []Type
+
+	var add func(t Type)
+	add = func(t Type) {
+		if u, ok := t.(*unionType); ok {
+			for _, alternative := range u.alternatives {
+				add(alternative)
+			}
+			return
+		}
+
+		for _, existing := range alternatives {
+			if same(existing, t) {
+				return
+			}
+		}
+		alternatives = append(alternatives, t)
+	}
+
+	for _, t := range types {
+		if t == anyType {
+			return anyType
+		}
+		add(t)
+	}
+
+	switch len(alternatives) {
+	case 0:
+		return anyType
+	case 1:
+		return alternatives[0]
+	}
+
+	return &unionType{alternatives: alternatives}
+}
+
+// Reports whether two types are the same
+func same(a, b Type) bool {
+	return assignable(a, b) && assignable(b, a)
+}
+
+// Reports whether a value of the source type can be used where the target type
+// is expected. The any type is compatible with every type both ways, a union
+// source needs every alternative to be assignable and a union target one of its
+// alternatives to accept the source. Lists, maps and promises are covariant in
+// their element types, and instances of a subclass can be used in place of the
+// instances of its superclasses
+func assignable(target, source Type) bool {
+	if target == anyType || source == anyType {
+		return true
+	}
+
+	if u, ok := source.(*unionType); ok {
+		for _, alternative := range u.alternatives {
+			if !assignable(target, alternative) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch t := target.(type) {
+	case *unionType:
+		for _, alternative := range t.alternatives {
+			if assignable(alternative, source) {
+				return true
+			}
+		}
+		return false
+	case basicType:
+		return t == source
+	case *listType:
+		s, ok := source.(*listType)
+		return ok && assignable(t.element, s.element)
+	case *mapType:
+		s, ok := source.(*mapType)
+		return ok && assignable(t.key, s.key) && assignable(t.value, s.value)
+	case *promiseType:
+		s, ok := source.(*promiseType)
+		return ok && assignable(t.value, s.value)
+	case *functionType:
+		return assignableFunction(t, source)
+	case classType:
+		s, ok := source.(classType)
+		return ok && s.extends(t.class)
+	case instanceType:
+		s, ok := source.(instanceType)
+		return ok && s.extends(t.class)
+	}
+
+	return false
+}
+
+// Reports whether the source can be used as a function of the target type. A
+// function accepts the arguments of the target if it takes as many parameters
+// and its parameters accept the ones of the target
+func assignableFunction(target *functionType, source Type) bool {
+	var s *functionType
+	switch source := source.(type) {
+	case *functionType:
+		s = source
+	case classType:
+		s = source.constructor()
+	default:
+		return false
+	}
+
+	if target.unchecked || s.unchecked {
+		return true
+	}
+
+	if len(target.params) < s.required || (len(target.params) > len(s.params) && s.rest == nil) {
+		return false
+	}
+
+	for idx, param := range target.params {
+		expected := s.rest
+		if idx < len(s.params) {
+			expected = s.params[idx]
+		}
+		if !assignable(expected, param) {
+			return false
+		}
+	}
+
+	return assignable(target.result, s.result)
+}
+
+// Reports whether a value of the type might be of the basic type. The any type
+// might be anything, a union if one of its alternatives might
+func mightBe(t Type, basic basicType) bool {
+	switch t := t.(type) {
+	case basicType:
+		return t == anyType || t == basic
+	case *unionType:
+		for _, alternative := range t.alternatives {
+			if mightBe(alternative, basic) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Reports whether a value of the type might be an instance, which can overload
+// the operators and be called with special methods
+func mightBeInstance(t Type) bool {
+	switch t := t.(type) {
+	case basicType:
+		return t == anyType
+	case instanceType:
+		return true
+	case *unionType:
+		for _, alternative := range t.alternatives {
+			if mightBeInstance(alternative) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -8,10 +8,37 @@ import (
 	"golox/token"
 )
 
+// Kind classifies a runtime error. The program catches a runtime error as an
+// instance of the error class named after its kind
+type Kind int
+
+// The kinds of runtime errors
+const (
+	RuntimeError Kind = iota
+	TypeError
+	NameError
+	IndexError
+	StopIteration
+)
+
+var kindNames = [...]string{
+	RuntimeError:  "RuntimeError",
+	TypeError:     "TypeError",
+	NameError:     "NameError",
+	IndexError:    "IndexError",
+	StopIteration: "StopIteration",
+}
+
+// String returns the name of the error class of the kind
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
 // Error represents an error
 type Error struct {
 	Message string
 	Token   *token.Token
+	Kind    Kind // The kind of a runtime error, RuntimeError for other errors
 }
 
 // New creates a new error
@@ -22,10 +49,64 @@ func New(t *token.Token, message string) *Error {
 	}
 }
 
+// NewKind creates a new runtime error of the given kind
+func NewKind(t *token.Token, kind Kind, message string) *Error {
+	return &Error{
+		Message: message,
+		Token:   t,
+		Kind:    kind,
+	}
+}
+
 func (e *Error) Error() string {
-	if e.Token.Type == token.EOF {
-		return fmt.Sprintf("[Pos %d:%d] Error at end: %s", e.Token.Line, e.Token.Column, e.Message)
+	return format("Error", e.Token, e.Message)
+}
+
+// KindError is an error of the given kind returned by a native function, it has
+// no position until it is reported at the call of the function
+type KindError struct {
+	Kind Kind
+	Err  error
+}
+
+// Errorf formats an error of the given kind, as fmt.Errorf does
+func Errorf(kind Kind, format string, args ...interface{}) error {
+	return &KindError{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *KindError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the formatted error
+func (e *KindError) Unwrap() error {
+	return e.Err
+}
+
+// Warning represents a problem that does not stop the program from running,
+// such as code that can never be executed
+type Warning struct {
+	Message string
+	Token   *token.Token
+}
+
+// NewWarning creates a new warning
+func NewWarning(t *token.Token, message string) *Warning {
+	return &Warning{
+		Message: message,
+		Token:   t,
+	}
+}
+
+func (w *Warning) String() string {
+	return format("Warning", w.Token, w.Message)
+}
+
+// Formats a message reported at the position of the token
+func format(kind string, t *token.Token, message string) string {
+	if t.Type == token.EOF {
+		return fmt.Sprintf("[Pos %d:%d] %s at end: %s", t.Line, t.Column, kind, message)
 	}
 
-	return fmt.Sprintf("[Pos %d:%d] Error at '%s': %s", e.Token.Line, e.Token.Column, e.Token.Lexeme, e.Message)
+	return fmt.Sprintf("[Pos %d:%d] %s at '%s': %s", t.Line, t.Column, kind, t.Lexeme, message)
 }
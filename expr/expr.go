@@ -27,6 +27,18 @@ type Visitor interface {
 	VisitUnaryExpr(expr *Unary) interface{}
 	VisitVariableExpr(expr *Variable) interface{}
 	VisitTernaryExpr(expr *Ternary) interface{}
+	VisitListExpr(expr *List) interface{}
+	VisitIndexExpr(expr *Index) interface{}
+	VisitSetIndexExpr(expr *SetIndex) interface{}
+	VisitSliceExpr(expr *Slice) interface{}
+	VisitMapExpr(expr *Map) interface{}
+	VisitCompoundAssignExpr(expr *CompoundAssign) interface{}
+	VisitUpdateExpr(expr *Update) interface{}
+	VisitLambdaExpr(expr *Lambda) interface{}
+	VisitMatchExpr(expr *Match) interface{}
+	VisitDestructureExpr(expr *Destructure) interface{}
+	VisitSpawnExpr(expr *Spawn) interface{}
+	VisitAwaitExpr(expr *Await) interface{}
 }
 
 // Assign represents an assignment expression
@@ -56,7 +68,14 @@ func (e *Binary) Accept(v Visitor) interface{} {
 type Call struct {
 	Callee    Expr
 	Paren     *token.Token
-	Arguments []Expr
+	Arguments []Expr           // Positional arguments
+	Named     []*NamedArgument // Named arguments, which always follow the positional ones
+}
+
+// NamedArgument is an argument passed by the name of its parameter, such as "b: 2"
+type NamedArgument struct {
+	Name  *token.Token
+	Value Expr
 }
 
 // Accept implements the Expr interface
@@ -172,3 +191,302 @@ type Ternary struct {
 func (e *Ternary) Accept(v Visitor) interface{} {
 	return v.VisitTernaryExpr(e)
 }
+
+// List represents a list literal expression
+type List struct {
+	Elements []Expr
+}
+
+// Accept implements the Expr interface
+func (e *List) Accept(v Visitor) interface{} {
+	return v.VisitListExpr(e)
+}
+
+// Index represents an index get expression
+type Index struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+}
+
+// Accept implements the Expr interface
+func (e *Index) Accept(v Visitor) interface{} {
+	return v.VisitIndexExpr(e)
+}
+
+// SetIndex represents an index set expression
+type SetIndex struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+	Value   Expr
+}
+
+// Accept implements the Expr interface
+func (e *SetIndex) Accept(v Visitor) interface{} {
+	return v.VisitSetIndexExpr(e)
+}
+
+// Slice represents a slice expression. Start and End are nil when omitted
+type Slice struct {
+	Object  Expr
+	Bracket *token.Token
+	Start   Expr
+	End     Expr
+}
+
+// Accept implements the Expr interface
+func (e *Slice) Accept(v Visitor) interface{} {
+	return v.VisitSliceExpr(e)
+}
+
+// Map represents a map literal expression. Keys and Values are of the same length
+type Map struct {
+	Brace  *token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+// Accept implements the Expr interface
+func (e *Map) Accept(v Visitor) interface{} {
+	return v.VisitMapExpr(e)
+}
+
+// CompoundAssign represents an assignment combined with a binary operator, such
+// as "x += 1". The target is a Variable, Get or Index expression, and the operator
+// is the compound assignment token, such as "+="
+type CompoundAssign struct {
+	Target   Expr
+	Operator *token.Token
+	Value    Expr
+}
+
+// Accept implements the Expr interface
+func (e *CompoundAssign) Accept(v Visitor) interface{} {
+	return v.VisitCompoundAssignExpr(e)
+}
+
+// Update represents an increment or a decrement, such as "++x" or "x--". The
+// target is a Variable, Get or Index expression. A prefix update evaluates to the
+// new value of the target, a postfix one to the old value
+type Update struct {
+	Target   Expr
+	Operator *token.Token
+	Prefix   bool
+}
+
+// Accept implements the Expr interface
+func (e *Update) Accept(v Visitor) interface{} {
+	return v.VisitUpdateExpr(e)
+}
+
+// Param represents a parameter of a function or a lambda. The default value is
+// nil for required parameters. A rest parameter collects the remaining positional
+// arguments into a list, it can only be the last parameter
+type Param struct {
+	Name    *token.Token
+	Type    *Type // The annotated type, nil if the parameter has none
+	Default Expr
+	Rest    bool
+}
+
+// Block is a block of statements in the body of a lambda or of a match arm.
+// Statements can't be referred to in this package, which the stmt package
+// depends on, so a block is a *stmt.Block, the only implementation of this
+// interface. stmt.Statements returns its statements
+type Block interface {
+	BlockNode() // Marks the statement blocks
+}
+
+// Lambda represents an anonymous function expression, either "fun (a) { ... }" or
+// the arrow shorthand "(a) => ...". The keyword is the "fun" or "=>" token. An
+// arrow function with an expression body is parsed into a block with a single
+// return statement
+type Lambda struct {
+	Keyword    *token.Token
+	Params     []*Param
+	Body       Block
+	Generator  bool  // Whether the body yields
+	Async      bool  // Whether the function is async, calling it then returns a promise
+	ReturnType *Type // The annotated return type, nil if the function has none
+}
+
+// Accept implements the Expr interface
+func (e *Lambda) Accept(v Visitor) interface{} {
+	return v.VisitLambdaExpr(e)
+}
+
+// Match represents a match expression, which evaluates the body of the first arm
+// whose pattern matches the subject and whose guard, if any, is truthy
+type Match struct {
+	Keyword *token.Token
+	Subject Expr
+	Arms    []*MatchArm
+}
+
+// Accept implements the Expr interface
+func (e *Match) Accept(v Visitor) interface{} {
+	return v.VisitMatchExpr(e)
+}
+
+// MatchArm represents an arm of a match expression. The guard is nil for arms
+// without one.
+//
+// The body of an arm is either an expression, whose value is the value of the
+// match, or a block, in which case the match evaluates to null
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expr
+	Body    Expr  // The expression body, nil for an arm with a block
+	Block   Block // The block body, nil for an arm with an expression
+}
+
+// Pattern is the interface that all the patterns of match arms implement.
+// Patterns are not expressions, they are inspected with a type switch
+type Pattern interface {
+	pattern()
+}
+
+// LiteralPattern matches a value equal to a number, string, boolean or null literal
+type LiteralPattern struct {
+	Token *token.Token
+	Value interface{}
+}
+
+// WildcardPattern matches any value without binding it, written "_"
+type WildcardPattern struct {
+	Token *token.Token
+}
+
+// BindingPattern matches any value and binds it to a variable
+type BindingPattern struct {
+	Name *token.Token
+}
+
+// ListPattern matches a list whose elements match the element patterns. Without
+// a rest, the list must have exactly as many elements as there are patterns.
+// With one, such as "[first, ...others]", the remaining elements are bound to the
+// rest as a new list, or ignored if the rest is "_"
+type ListPattern struct {
+	Bracket  *token.Token
+	Elements []Pattern
+	Rest     *token.Token
+}
+
+// InstancePattern matches an instance of a class, or of one of its subclasses,
+// whose properties match the field patterns, such as "Point{x: 0, y}". A field
+// without a pattern binds the property to a variable with the same name.
+//
+// The class is nil for patterns like "{x, y}", which match any instance by its
+// properties and any map by its string keys
+type InstancePattern struct {
+	Class  *Variable
+	Brace  *token.Token
+	Fields []*FieldPattern
+}
+
+// FieldPattern represents a property of an InstancePattern
+type FieldPattern struct {
+	Name    *token.Token
+	Pattern Pattern
+}
+
+// AlternativePattern matches a value matching any of the alternatives, such as
+// "1 | 2 | 3"
+type AlternativePattern struct {
+	Alternatives []Pattern
+}
+
+// TargetPattern assigns the value to a variable, a property or an index. It is
+// only found in the patterns of Destructure expressions
+type TargetPattern struct {
+	Target Expr
+}
+
+// PatternToken returns the first token of a pattern, where errors about it are
+// reported
+func PatternToken(pattern Pattern) *token.Token {
+	switch p := pattern.(type) {
+	case *LiteralPattern:
+		return p.Token
+	case *WildcardPattern:
+		return p.Token
+	case *BindingPattern:
+		return p.Name
+	case *ListPattern:
+		return p.Bracket
+	case *InstancePattern:
+		if p.Class != nil {
+			return p.Class.Name
+		}
+		return p.Brace
+	case *AlternativePattern:
+		return PatternToken(p.Alternatives[0])
+	case *TargetPattern:
+		switch target := p.Target.(type) {
+		case *Variable:
+			return target.Name
+		case *Get:
+			return target.Name
+		case *Index:
+			return target.Bracket
+		}
+	}
+
+	return nil
+}
+
+func (*LiteralPattern) pattern()     {}
+func (*WildcardPattern) pattern()    {}
+func (*BindingPattern) pattern()     {}
+func (*ListPattern) pattern()        {}
+func (*InstancePattern) pattern()    {}
+func (*AlternativePattern) pattern() {}
+func (*TargetPattern) pattern()      {}
+
+// Destructure represents an assignment to the targets of a list pattern, such as
+// "[a, b] = [b, a]". The value is evaluated before any target is assigned
+type Destructure struct {
+	Pattern *ListPattern
+	Equals  *token.Token
+	Value   Expr
+}
+
+// Accept implements the Expr interface
+func (e *Destructure) Accept(v Visitor) interface{} {
+	return v.VisitDestructureExpr(e)
+}
+
+// Spawn represents a call run concurrently in a new task, such as "spawn f(x)".
+// The callee and the arguments are evaluated before the task starts
+type Spawn struct {
+	Keyword *token.Token
+	Call    *Call
+}
+
+// Accept implements the Expr interface
+func (e *Spawn) Accept(v Visitor) interface{} {
+	return v.VisitSpawnExpr(e)
+}
+
+// Await represents an await expression, such as "await p", waiting for the
+// promise it evaluates to
+type Await struct {
+	Keyword *token.Token
+	Value   Expr
+}
+
+// Accept implements the Expr interface
+func (e *Await) Accept(v Visitor) interface{} {
+	return v.VisitAwaitExpr(e)
+}
+
+// Type represents a type annotation, such as "number", "list[string]" or
+// "number | null". The arguments are the element types of a list or the key and
+// value types of a map, the alternatives make up a union and leave the name nil.
+// Annotations are only read by the static checker, the interpreter ignores them
+type Type struct {
+	Name         *token.Token
+	Arguments    []*Type
+	Alternatives []*Type
+}
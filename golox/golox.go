@@ -0,0 +1,357 @@
+/*
+Package golox provides an API for embedding the GoLox language into Go applications.
+
+A VM wraps a single interpreter whose global state persists between evaluations,
+so a host application can load a script once and then call into it:
+
+	vm := golox.NewVM(golox.Options{Stdout: &out})
+
+	if _, err := vm.Eval(`fun greet(name) { return "Hello " + name; }`); err != nil {
+		return err
+	}
+
+	greeting, err := vm.Call("greet", "world")
+
+Every error, including parse and resolution errors, runtime errors and panics raised by native
+functions, is returned to the host instead of escaping as a panic.
+
+Check runs the static type checker over a source without running it, reporting
+the mismatches with the optional type annotations of the program:
+
+	if err := golox.Check(`var x: number = "one";`); err != nil {
+		...
+	}
+
+Scripts from untrusted sources can be sandboxed with Options.Limits and the
+context accepting variants of the methods, EvalContext and CallContext, and with
+Options.ImportRoot keeping their imports from reading any file of the host. When
+a limit is hit, an *interpreter.LimitError is returned:
+
+	vm := golox.NewVM(golox.Options{Limits: interpreter.Limits{MaxSteps: 1_000_000, Timeout: time.Second}})
+
+	_, err := vm.EvalContext(ctx, "while (true) {}")
+	if errors.Is(err, interpreter.ErrStepLimit) {
+		...
+	}
+*/
+package golox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golox/checker"
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/interpreter"
+	"golox/lexer"
+	"golox/optimizer"
+	"golox/parser"
+	"golox/resolver"
+	"golox/stdlib"
+	"golox/stmt"
+	"io"
+	"os"
+)
+
+// Value is any value of the Lox language, see interpreter.Value
+type Value = interpreter.Value
+
+// Options configure a VM
+type Options struct {
+	Stdout io.Writer          // Destination of print statements, defaults to os.Stdout
+	Stderr io.Writer          // Destination of error reports, defaults to os.Stderr
+	Limits interpreter.Limits // Limits for every Eval and Call, only the call depth is limited by default
+
+	// Clock that the timers and the clock function use, defaults to the system
+	// clock. An interpreter.FakeClock makes the timers fire without waiting
+	Clock interpreter.Clock
+
+	// Directories that imports are looked up from when the module is not found
+	// relative to the importing file
+	SearchPath []string
+
+	// Directory that the imported files must be inside, others are not found.
+	// Set it when running untrusted scripts, which can otherwise import, and so
+	// read, any file of the host. An empty directory allows every file
+	ImportRoot string
+
+	// Whether the evaluated sources are rewritten by the optimizer before they
+	// are executed, see package optimizer. Imported modules are not optimized
+	Optimize bool
+}
+
+// DefaultMaxCallDepth is the call depth limit of a VM whose options leave it
+// unset. Without a limit, an unbounded recursion would overflow the Go stack and
+// crash the host process
+const DefaultMaxCallDepth = 10_000
+
+// VM is an embeddable GoLox virtual machine
+type VM struct {
+	interpreter *interpreter.Interpreter
+	stderr      io.Writer
+	optimizer   *optimizer.Optimizer // Nil unless Options.Optimize is set
+}
+
+// NewVM creates a new VM with the given options. The standard library is
+// registered into the globals of the VM
+func NewVM(options Options) *VM {
+	if options.Stdout == nil {
+		options.Stdout = os.Stdout
+	}
+
+	if options.Stderr == nil {
+		options.Stderr = os.Stderr
+	}
+
+	if options.Limits.MaxCallDepth == 0 {
+		options.Limits.MaxCallDepth = DefaultMaxCallDepth
+	}
+
+	i := interpreter.New()
+	i.SetStdout(options.Stdout)
+	i.SetStderr(options.Stderr)
+	i.SetLimits(options.Limits)
+	i.SetSearchPath(options.SearchPath...)
+	i.SetImportRoot(options.ImportRoot)
+	if options.Clock != nil {
+		i.SetClock(options.Clock)
+	}
+	stdlib.Register(i)
+
+	vm := &VM{interpreter: i, stderr: options.Stderr}
+	if options.Optimize {
+		vm.optimizer = optimizer.New()
+	}
+
+	return vm
+}
+
+// Interpreter returns the interpreter of the VM, for lower level access
+func (vm *VM) Interpreter() *interpreter.Interpreter {
+	return vm.interpreter
+}
+
+// Eval runs the given source code. If the last statement of the source is an
+// expression statement, its value is returned. A source consisting of a single
+// expression without the terminating semicolon is evaluated as well
+func (vm *VM) Eval(source string) (Value, error) {
+	return vm.EvalContext(context.Background(), source)
+}
+
+// EvalContext runs the given source code like Eval. If the context is done before
+// the execution finishes, an *interpreter.LimitError is returned
+func (vm *VM) EvalContext(ctx context.Context, source string) (result Value, err error) {
+	defer vm.recoverPanic(&err)
+
+	statements, err := vm.parse(source)
+	if err != nil {
+		expression, ok := parseExpression(source)
+		if !ok {
+			return vm.report(nil, err)
+		}
+
+		// The expression is resolved like the statement it would be with a semicolon
+		if statements, err = vm.resolve([]stmt.Stmt{&stmt.Expression{Expression: expression}}); err != nil {
+			return vm.report(nil, err)
+		}
+	}
+
+	return vm.report(vm.interpreter.ExecuteContext(ctx, statements))
+}
+
+// EvalFile runs the source code of the given file like Eval. Relative imports of
+// the file are resolved against the directory of the file
+func (vm *VM) EvalFile(path string) (Value, error) {
+	return vm.EvalFileContext(context.Background(), path)
+}
+
+// EvalFileContext runs the source code of the given file like EvalFile. If the
+// context is done before the execution finishes, an *interpreter.LimitError is returned
+func (vm *VM) EvalFileContext(ctx context.Context, path string) (Value, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return vm.report(nil, err)
+	}
+
+	vm.interpreter.SetScriptPath(path)
+
+	return vm.EvalContext(ctx, string(source))
+}
+
+// Call calls the global function with the given name. The arguments are converted
+// into Lox values with interpreter.ToValue
+func (vm *VM) Call(name string, args ...interface{}) (Value, error) {
+	return vm.CallContext(context.Background(), name, args...)
+}
+
+// CallContext calls the global function with the given name like Call. If the
+// context is done before the call returns, an *interpreter.LimitError is returned
+func (vm *VM) CallContext(ctx context.Context, name string, args ...interface{}) (result Value, err error) {
+	defer vm.recoverPanic(&err)
+
+	callee, ok := vm.interpreter.Globals().Lookup(name)
+	if !ok {
+		return vm.report(nil, fmt.Errorf("undefined function '%s'", name))
+	}
+
+	arguments := make([]Value, len(args))
+	for idx, arg := range args {
+		if arguments[idx], err = interpreter.ToValue(arg); err != nil {
+			return vm.report(nil, fmt.Errorf("argument %d: %w", idx+1, err))
+		}
+	}
+
+	return vm.report(vm.interpreter.CallContext(ctx, callee, arguments))
+}
+
+// SetGlobal defines a global variable. The value is converted into a Lox value
+// with interpreter.ToValue
+func (vm *VM) SetGlobal(name string, value interface{}) error {
+	converted, err := interpreter.ToValue(value)
+	if err != nil {
+		return err
+	}
+
+	vm.interpreter.Globals().Define(name, converted)
+
+	return nil
+}
+
+// GetGlobal returns the value of a global variable. The second return value
+// reports whether the variable is defined
+func (vm *VM) GetGlobal(name string) (Value, bool) {
+	return vm.interpreter.Globals().Lookup(name)
+}
+
+// Close releases the goroutines of the suspended generators, which can't always
+// be garbage collected, by ending the generators. It must not be called while
+// the VM is running, see Interpreter.Close
+func (vm *VM) Close() {
+	vm.interpreter.Close()
+}
+
+// RegisterNative defines a native function in the globals, see Interpreter.RegisterNative
+func (vm *VM) RegisterNative(name string, arity int, fn interpreter.NativeFunc) {
+	vm.interpreter.RegisterNative(name, arity, fn)
+}
+
+// RegisterFunc defines a Go function in the globals, see Interpreter.RegisterFunc
+func (vm *VM) RegisterFunc(name string, fn interface{}) error {
+	return vm.interpreter.RegisterFunc(name, fn)
+}
+
+// Check type checks the given source code without running it. The parse and
+// resolution errors are returned like the ones of Eval, otherwise all the type
+// errors found by the checker are joined into a single error
+func Check(source string) error {
+	l := lexer.New(source)
+	l.ScanTokens()
+
+	statements, parseErrors := parser.New(l.Tokens).ParseProgram()
+	if len(parseErrors) > 0 {
+		return joinErrors(parseErrors)
+	}
+
+	if resolveErrors := resolver.New().Resolve(statements); len(resolveErrors) > 0 {
+		return joinErrors(resolveErrors)
+	}
+
+	if typeErrors := checker.New().Check(statements); len(typeErrors) > 0 {
+		return joinErrors(typeErrors)
+	}
+
+	return nil
+}
+
+// CheckFile type checks the source code of the given file like Check
+func CheckFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return Check(string(source))
+}
+
+// Writes the error, if any, to the error output of the VM and passes the results through
+func (vm *VM) report(result Value, err error) (Value, error) {
+	if err != nil {
+		//nolint:errcheck // Reporting is best effort, the error is returned anyway
+		fmt.Fprintln(vm.stderr, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Converts a panic into an error so that it never escapes into the host
+func (vm *VM) recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		_, *err = vm.report(nil, fmt.Errorf("internal error: %v", r))
+	}
+}
+
+// Parses the source code into resolved statements, joining all the parse errors
+func (vm *VM) parse(source string) ([]stmt.Stmt, error) {
+	l := lexer.New(source)
+	l.ScanTokens()
+
+	statements, parseErrors := parser.New(l.Tokens).ParseProgram()
+	if len(parseErrors) > 0 {
+		return nil, joinErrors(parseErrors)
+	}
+
+	return vm.resolve(statements)
+}
+
+// Resolves the statements, joining all the resolution errors, and optimizes them
+// if the VM is set to. The warnings of the resolver are written to the error
+// output of the VM
+func (vm *VM) resolve(statements []stmt.Stmt) ([]stmt.Stmt, error) {
+	r := resolver.New()
+	if resolveErrors := r.Resolve(statements); len(resolveErrors) > 0 {
+		return nil, joinErrors(resolveErrors)
+	}
+
+	for _, warning := range r.Warnings() {
+		//nolint:errcheck // Reporting is best effort
+		fmt.Fprintln(vm.stderr, warning)
+	}
+
+	if vm.optimizer != nil {
+		statements = vm.optimizer.OptimizeStatements(statements)
+	}
+
+	return statements, nil
+}
+
+func joinErrors(loxErrors []*loxerr.Error) error {
+	errs := make([]error, len(loxErrors))
+	for idx, loxErr := range loxErrors {
+		errs[idx] = loxErr
+	}
+
+	return errors.Join(errs...)
+}
+
+// Parses the source code as a single expression. The second return value
+// reports whether the whole source is a valid expression
+func parseExpression(source string) (expression expr.Expr, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isParseErr := r.(*loxerr.Error); !isParseErr {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+
+	l := lexer.New(source)
+	l.ScanTokens()
+
+	p := parser.New(l.Tokens)
+	expression = p.Parse()
+
+	return expression, p.AtEnd()
+}
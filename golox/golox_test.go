@@ -0,0 +1,570 @@
+package golox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"golox/interpreter"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestVM() (*VM, *bytes.Buffer) {
+	var out bytes.Buffer
+	return NewVM(Options{Stdout: &out, Stderr: io.Discard}), &out
+}
+
+func TestVM_Eval(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected Value
+		output   string
+	}{
+		{name: "Single expression", source: "1 + 2 * 3", expected: 7.0},
+		{name: "Last expression statement", source: "var a = \"lox\"; a + \"!\";", expected: "lox!"},
+		{name: "Statements only", source: "var a = 1;", expected: nil},
+		{name: "Print", source: "print 1 + 1; print \"two\";", output: "2\ntwo\n"},
+		{
+			name:     "Closures",
+			source:   "fun counter() { var i = 0; fun inc() { i = i + 1; return i; } return inc; } var c = counter(); c(); c();",
+			expected: 2.0,
+		},
+		{
+			name:     "Control flow",
+			source:   "var sum = 0; for (var i = 0; i < 5; i = i + 1) { if (i == 2) sum = sum + 10; else sum = sum + i; } sum;",
+			expected: 18.0,
+		},
+		{
+			name: "Classes and inheritance",
+			source: `class Animal { init(name) { this.name = name; } speak() { return this.name + " makes a sound"; } }
+				class Dog < Animal { speak() { return super.speak() + ": woof"; } }
+				Dog("Rex").speak();`,
+			expected: "Rex makes a sound: woof",
+		},
+		{
+			name:     "Type annotations are ignored",
+			source:   "class P { x: number; init(x: number) { this.x = x; } } fun f(p: P): string { return p.x; } var s: string = f(P(1)); s;",
+			expected: 1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm, out := newTestVM()
+
+			actual, err := vm.Eval(tt.source)
+			if err != nil {
+				t.Fatalf("Eval() returned an error: %v", err)
+			}
+
+			if actual != tt.expected {
+				t.Errorf("Eval() = %v, want %v", actual, tt.expected)
+			}
+
+			if out.String() != tt.output {
+				t.Errorf("Output = %q, want %q", out.String(), tt.output)
+			}
+		})
+	}
+}
+
+func TestVM_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Parse error", source: "var = 1;", expectedErr: "Expect variable name."},
+		{name: "Multiple parse errors", source: "var = 1; print;", expectedErr: "Expect expression."},
+		{name: "Runtime error", source: "-\"a\";", expectedErr: "Operand must be a number."},
+		{name: "Undefined variable", source: "print missing;", expectedErr: "Undefined variable 'missing'."},
+		{name: "Resolution error", source: "if (true) break;", expectedErr: "Can't use 'break' outside of a loop."},
+		{name: "Resolution error in an expression", source: "() => { break; }", expectedErr: "Can't use 'break' outside of a loop."},
+		{name: "Return outside of a function", source: "return 1;", expectedErr: "Error at 'return': Can't return from top-level code."},
+		{name: "Uncaught exception", source: "throw TypeError(\"bad\");", expectedErr: "Error at 'throw': Uncaught TypeError: bad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			vm := NewVM(Options{Stdout: io.Discard, Stderr: &stderr})
+
+			_, err := vm.Eval(tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+
+			if !strings.Contains(stderr.String(), tt.expectedErr) {
+				t.Errorf("Expected the error to be reported to stderr, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestVM_Warnings(t *testing.T) {
+	var stderr bytes.Buffer
+	vm := NewVM(Options{Stdout: io.Discard, Stderr: &stderr})
+
+	result, err := vm.Eval("match (2) { _ => \"any\", 2 => \"two\" }")
+	if err != nil {
+		t.Fatalf("Eval() returned an error: %v", err)
+	}
+
+	if result != "any" {
+		t.Errorf("Eval() = %v, want any", result)
+	}
+
+	expected := "[Pos 1:25] Warning at '2': Unreachable match arm, an earlier arm matches all its values.\n"
+	if stderr.String() != expected {
+		t.Errorf("Expected the warning %q on stderr but got %q", expected, stderr.String())
+	}
+}
+
+func TestVM_Optimize(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected Value
+	}{
+		{source: "2 * 3 + 1", expected: 7.0},
+		{source: "var x = 0; if (false) x = 1; else x = 2; x;", expected: 2.0},
+		{source: "fun f(n) { return n * (1 + 1); } f(21);", expected: 42.0},
+		{source: "var s = \"a\" + \"b\"; s;", expected: "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			vm := NewVM(Options{Stdout: io.Discard, Stderr: io.Discard, Optimize: true})
+
+			actual, err := vm.Eval(tt.source)
+			if err != nil {
+				t.Fatalf("Eval() returned an error: %v", err)
+			}
+
+			if actual != tt.expected {
+				t.Errorf("Eval() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+
+	vm := NewVM(Options{Stdout: io.Discard, Stderr: io.Discard, Optimize: true})
+	if _, err := vm.Eval("var x = 1 + \"a\";"); err == nil || !strings.Contains(err.Error(), "[Pos 1:11] Error at '+'") {
+		t.Errorf("Expected the runtime error at its position but got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check("fun add(a: number, b: number): number { return a + b; } var n: number = add(1, 2);"); err != nil {
+		t.Errorf("Check() returned an error: %v", err)
+	}
+
+	err := Check("var x: number = \"one\";\nfun f(): string { return 1; }")
+	expected := "[Pos 1:5] Error at 'x': Can't assign string to 'x' of type number.\n" +
+		"[Pos 2:19] Error at 'return': Can't return number from a function returning string."
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected the errors %q but got %v", expected, err)
+	}
+
+	if err := Check("var = 1;"); err == nil || !strings.Contains(err.Error(), "Expect variable name.") {
+		t.Errorf("Expected a parse error but got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "main.lox")
+	if err := os.WriteFile(path, []byte("-\"a\";"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckFile(path); err == nil || !strings.Contains(err.Error(), "Operand must be a number.") {
+		t.Errorf("Expected a type error but got %v", err)
+	}
+}
+
+func TestVM_CallAndGlobals(t *testing.T) {
+	vm, _ := newTestVM()
+
+	if err := vm.SetGlobal("greeting", "Hello"); err != nil {
+		t.Fatalf("SetGlobal() returned an error: %v", err)
+	}
+
+	if _, err := vm.Eval(`fun greet(name) { return greeting + " " + name; } var answer = 42;`); err != nil {
+		t.Fatalf("Eval() returned an error: %v", err)
+	}
+
+	result, err := vm.Call("greet", "world")
+	if err != nil || result != "Hello world" {
+		t.Errorf("Call() = %v, %v, want Hello world", result, err)
+	}
+
+	if answer, ok := vm.GetGlobal("answer"); !ok || answer != 42.0 {
+		t.Errorf("GetGlobal() = %v, %v, want 42", answer, ok)
+	}
+
+	if _, err := vm.Call("greet"); err == nil {
+		t.Errorf("Expected an arity error")
+	}
+
+	if _, err := vm.Call("missing"); err == nil {
+		t.Errorf("Expected an error when calling an undefined function")
+	}
+}
+
+func TestVM_PanicsDoNotEscape(t *testing.T) {
+	vm, _ := newTestVM()
+	vm.RegisterNative("crash", 0, func(_ []Value) (Value, error) {
+		panic("native bug")
+	})
+
+	if _, err := vm.Eval("crash();"); err == nil || !strings.Contains(err.Error(), "native bug") {
+		t.Errorf("Expected the panic to be returned as an error, got %v", err)
+	}
+
+	if _, err := vm.Eval("1 + 1;"); err != nil {
+		t.Errorf("VM is not usable after a recovered panic: %v", err)
+	}
+}
+
+func TestVM_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   interpreter.Limits
+		source   string
+		expected error
+	}{
+		{
+			name:     "Step limit",
+			limits:   interpreter.Limits{MaxSteps: 10_000},
+			source:   "while (true) {}",
+			expected: interpreter.ErrStepLimit,
+		},
+		{
+			name:     "Call depth limit",
+			limits:   interpreter.Limits{MaxCallDepth: 100},
+			source:   "fun f(n) { return f(n + 1); } f(0);",
+			expected: interpreter.ErrCallDepthLimit,
+		},
+		{
+			name:     "Default call depth limit",
+			source:   "fun f(n) { return f(n + 1); } f(0);",
+			expected: interpreter.ErrCallDepthLimit,
+		},
+		{
+			name:     "Time limit",
+			limits:   interpreter.Limits{Timeout: 10 * time.Millisecond},
+			source:   "while (true) {}",
+			expected: interpreter.ErrTimeout,
+		},
+		{
+			name:     "Allocation limit",
+			limits:   interpreter.Limits{MaxAllocationBytes: 1 << 20},
+			source:   "var s = \"ab\"; while (true) { s = s + s; }",
+			expected: interpreter.ErrAllocationLimit,
+		},
+		{
+			name:     "Steps of a range iteration",
+			limits:   interpreter.Limits{MaxSteps: 100_000},
+			source:   "list(0..<5000000);",
+			expected: interpreter.ErrStepLimit,
+		},
+		{
+			name:     "Allocation of the elements of a list",
+			limits:   interpreter.Limits{MaxAllocationBytes: 1 << 20},
+			source:   "list(0..<5000000);",
+			expected: interpreter.ErrAllocationLimit,
+		},
+		{
+			name:     "Limit errors can't be caught",
+			limits:   interpreter.Limits{MaxSteps: 10_000},
+			source:   "try { while (true) {} } catch (e) {} finally { print 1; }",
+			expected: interpreter.ErrStepLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM(Options{Stdout: io.Discard, Stderr: io.Discard, Limits: tt.limits})
+
+			_, err := vm.Eval(tt.source)
+
+			var limitErr *interpreter.LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v but got %v", tt.expected, err)
+			}
+
+			// The budget is reset for the next evaluation
+			if result, err := vm.Eval("1 + 1"); err != nil || result != 2.0 {
+				t.Errorf("Eval() after a limit error = %v, %v", result, err)
+			}
+		})
+	}
+}
+
+func TestVM_Cancel(t *testing.T) {
+	vm, _ := newTestVM()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := vm.EvalContext(ctx, "while (true) {}")
+	if !errors.Is(err, interpreter.ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the execution to be canceled but got %v", err)
+	}
+}
+
+func TestVM_CallContext(t *testing.T) {
+	vm, _ := newTestVM()
+
+	if _, err := vm.Eval("fun spin() { while (true) {} }"); err != nil {
+		t.Fatalf("Eval() returned an error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := vm.CallContext(ctx, "spin"); !errors.Is(err, interpreter.ErrTimeout) {
+		t.Fatalf("Expected a timeout but got %v", err)
+	}
+}
+
+func TestVM_Clock(t *testing.T) {
+	clock := interpreter.NewFakeClock(time.Unix(100, 0))
+	vm := NewVM(Options{Stdout: io.Discard, Stderr: io.Discard, Clock: clock})
+
+	result, err := vm.Eval("var start = clock(); await sleep(2500); clock() - start;")
+	if err != nil {
+		t.Fatalf("Eval() returned an error: %v", err)
+	}
+
+	if result != 2.5 {
+		t.Errorf("Expected 2.5 seconds to pass on the fake clock but got %v", result)
+	}
+}
+
+func TestVM_Modules(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		main        string
+		output      string
+		expectedErr string
+	}{
+		{
+			name:   "Import a module",
+			files:  map[string]string{"lib/math.lox": "fun add(a, b) { return a + b; } var pi = 3;"},
+			main:   `import "lib/math.lox" as math; print math.add(1, math.pi);`,
+			output: "4\n",
+		},
+		{
+			name:   "Import selected names",
+			files:  map[string]string{"lib/math.lox": "fun add(a, b) { return a + b; } var pi = 3;"},
+			main:   `from "lib/math" import add, pi; print add(pi, 1);`,
+			output: "4\n",
+		},
+		{
+			name:   "Modules run once",
+			files:  map[string]string{"m.lox": `print "loaded"; var n = 1;`},
+			main:   `import "m.lox" as a; import "./m.lox" as b; from "m" import n; print a == b;`,
+			output: "loaded\ntrue\n",
+		},
+		{
+			name:   "Modules have their own globals",
+			files:  map[string]string{"m.lox": `var x = "module"; fun getX() { return x; }`},
+			main:   `var x = "main"; from "m" import getX; print getX(); print x;`,
+			output: "module\nmain\n",
+		},
+		{
+			name:   "Natives are visible in modules",
+			files:  map[string]string{"m.lox": `var kind = type(1); class E < Error {}`},
+			main:   `import "m" as m; print m.kind; try { throw m.E("x"); } catch (e) { print e.message; }`,
+			output: "number\nx\n",
+		},
+		{
+			name: "Imports relative to the importing file",
+			files: map[string]string{
+				"lib/a.lox": `from "b.lox" import b; var a = "a" + b;`,
+				"lib/b.lox": `var b = "b";`,
+			},
+			main:   `from "lib/a" import a; print a;`,
+			output: "ab\n",
+		},
+		{
+			name:   "Search path",
+			files:  map[string]string{"vendor/util.lox": `var version = 2;`},
+			main:   `import "util" as util; print util.version;`,
+			output: "2\n",
+		},
+		{
+			name:   "Import errors are catchable",
+			main:   `try { import "missing" as m; } catch (e) { print e.message; }`,
+			output: "Can't find module 'missing'.\n",
+		},
+		{
+			name:        "Missing module",
+			main:        `import "missing.lox" as m;`,
+			expectedErr: "Can't find module 'missing.lox'.",
+		},
+		{
+			name:        "Undefined export",
+			files:       map[string]string{"m.lox": `var x = 1;`},
+			main:        `from "m" import x, y;`,
+			expectedErr: "Undefined export 'y' in module 'm'.",
+		},
+		{
+			name:        "Private names are not exported",
+			files:       map[string]string{"m.lox": `var _secret = 1;`},
+			main:        `import "m" as m; m._secret;`,
+			expectedErr: "Undefined export '_secret' in module 'm'.",
+		},
+		{
+			name:        "Syntax error in a module",
+			files:       map[string]string{"bad.lox": "var = 1;"},
+			main:        `import "bad.lox" as bad;`,
+			expectedErr: "Error in module 'bad.lox': [Pos 1:5] Expect variable name.",
+		},
+		{
+			name: "Import cycle",
+			files: map[string]string{
+				"a.lox": `import "b.lox" as b;`,
+				"b.lox": `import "a.lox" as a;`,
+			},
+			main:        `import "a.lox" as a;`,
+			expectedErr: "Import cycle: a.lox -> b.lox -> a.lox.",
+		},
+		{
+			name:        "Import cycle through the main script",
+			files:       map[string]string{"a.lox": `import "main.lox" as main;`},
+			main:        `import "a.lox" as a;`,
+			expectedErr: "main.lox -> a.lox -> main.lox.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"main.lox": tt.main}
+			for name, source := range tt.files {
+				files[name] = source
+			}
+
+			for name, source := range files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var out bytes.Buffer
+			vm := NewVM(Options{Stdout: &out, Stderr: io.Discard, SearchPath: []string{filepath.Join(dir, "vendor")}})
+
+			_, err := vm.EvalFile(filepath.Join(dir, "main.lox"))
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("EvalFile() returned an error: %v", err)
+			}
+
+			if out.String() != tt.output {
+				t.Errorf("Output = %q, want %q", out.String(), tt.output)
+			}
+		})
+	}
+}
+
+func TestVM_ModuleWarnings(t *testing.T) {
+	dir := t.TempDir()
+	module := "var kind = match (2) { _ => \"any\", 2 => \"two\" };"
+	if err := os.WriteFile(filepath.Join(dir, "m.lox"), []byte(module), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.lox"), []byte(`import "m" as m; print m.kind;`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	vm := NewVM(Options{Stdout: &out, Stderr: &stderr})
+
+	if _, err := vm.EvalFile(filepath.Join(dir, "main.lox")); err != nil {
+		t.Fatalf("EvalFile() returned an error: %v", err)
+	}
+
+	if out.String() != "any\n" {
+		t.Errorf("Output = %q, want %q", out.String(), "any\n")
+	}
+
+	expected := "Warning in module 'm': [Pos 1:36] Warning at '2': Unreachable match arm, an earlier arm matches all its values.\n"
+	if stderr.String() != expected {
+		t.Errorf("Expected the warning %q on stderr but got %q", expected, stderr.String())
+	}
+}
+
+func TestVM_ImportRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	files := map[string]string{
+		"secret.lox":     "var secret = 1;",
+		"root/lib.lox":   "var kind = \"lib\";",
+		"root/main.lox":  "",
+		"root/notes.txt": "top secret = ;",
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret.lox"), filepath.Join(root, "link.lox")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Inside the root", source: `import "lib" as lib; lib.kind;`},
+		{name: "Parent directory", source: `import "../secret" as s;`, expectedErr: "Can't find module '../secret'."},
+		{name: "Absolute path", source: `import "` + filepath.Join(dir, "secret.lox") + `" as s;`, expectedErr: "Can't find module"},
+		{name: "Symbolic link", source: `import "link" as s;`, expectedErr: "Can't find module 'link'."},
+		{name: "File that isn't a module", source: `import "notes.txt" as n;`, expectedErr: "Error in module 'notes.txt': [Pos 1:5] Expect ';' after expression."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewVM(Options{Stdout: io.Discard, Stderr: io.Discard, ImportRoot: root})
+			vm.Interpreter().SetScriptPath(filepath.Join(root, "main.lox"))
+
+			_, err := vm.Eval(tt.source)
+
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Eval() returned an error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+
+			// The content of the files is not revealed
+			if strings.Contains(err.Error(), "secret") && !strings.Contains(err.Error(), "Can't find module") {
+				t.Errorf("Expected the error not to reveal the file but got '%v'", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/token"
+)
+
+// Promise is the eventual outcome of an asynchronous operation, such as a call
+// to an async function or a sleep. A pending promise is settled once, either
+// fulfilled with a value or rejected with an error. The callbacks given to then
+// and catchError, and the async functions awaiting the promise, are run by the
+// event loop after it settles
+type Promise struct {
+	state     promiseState
+	value     Value                  // The value the promise is fulfilled with
+	failure   interface{}            // The error the promise is rejected with, raised again by await
+	resolved  bool                   // Whether the outcome is decided, possibly by another promise
+	handled   bool                   // Whether a callback or an await takes care of the outcome
+	callbacks []func(i *Interpreter) // Queued as jobs of the event loop once the promise settles
+}
+
+type promiseState int
+
+const (
+	promisePending promiseState = iota
+	promiseFulfilled
+	promiseRejected
+)
+
+// Creates a pending promise
+func (i *Interpreter) newPendingPromise() *Promise {
+	i.allocate(functionSize)
+
+	return &Promise{}
+}
+
+// Get returns the built-in method of the promise with the given name
+func (p *Promise) Get(name *token.Token) Value {
+	if method, ok := promiseMethods[name.Lexeme]; ok {
+		return method.bind(p)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the promise
+func (p *Promise) String() string {
+	switch p.state {
+	case promiseFulfilled:
+		return "<promise fulfilled>"
+	case promiseRejected:
+		return "<promise rejected>"
+	}
+
+	return "<promise pending>"
+}
+
+// Built-in methods of promises, receiving the promise they were called on
+var promiseMethods = map[string]*method{
+	"then":       {name: "then", arity: 1, fn: promiseThen},
+	"catchError": {name: "catchError", arity: 1, fn: promiseCatch},
+}
+
+// then(fn) calls fn with the value of the promise once it is fulfilled, and
+// returns a promise of the result. A rejection is passed through
+func promiseThen(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	return receiver.(*Promise).chain(i, arguments[0], promiseFulfilled), nil
+}
+
+// catchError(fn) calls fn with the error of the promise once it is rejected, and
+// returns a promise of the result. A fulfillment is passed through
+func promiseCatch(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	return receiver.(*Promise).chain(i, arguments[0], promiseRejected), nil
+}
+
+// Returns a promise of the result of calling the callback with the outcome of
+// the promise, if it settles in the given state. Otherwise the returned promise
+// settles like this one
+func (p *Promise) chain(i *Interpreter, callback Value, state promiseState) *Promise {
+	next := i.newPendingPromise()
+	location := i.callSite()
+
+	p.onSettle(i, func(i *Interpreter) {
+		if p.state != state {
+			next.adopt(i, p)
+			return
+		}
+
+		argument := p.value
+		if state == promiseRejected {
+			argument, _ = i.caughtValue(p.failure)
+		}
+
+		result, failure := i.capture(func() Value {
+			result, err := i.callValue(callback, []Value{argument})
+			if err != nil {
+				raise(location, err)
+			}
+			return result
+		})
+
+		if failure != nil {
+			next.reject(i, failure)
+		} else {
+			next.resolve(i, result)
+		}
+	})
+
+	return next
+}
+
+// Fulfills the promise with the value. Resolving with another promise makes
+// this one settle like it. Does nothing if the outcome is already decided
+func (p *Promise) resolve(i *Interpreter, value Value) {
+	if p.resolved {
+		return
+	}
+	p.resolved = true
+
+	if other, ok := value.(*Promise); ok {
+		other.onSettle(i, func(i *Interpreter) {
+			p.adopt(i, other)
+		})
+		return
+	}
+
+	p.settle(i, promiseFulfilled, value, nil)
+}
+
+// Rejects the promise with an error the program can catch. Does nothing if the
+// outcome is already decided
+func (p *Promise) reject(i *Interpreter, failure interface{}) {
+	if p.resolved {
+		return
+	}
+	p.resolved = true
+
+	p.settle(i, promiseRejected, nil, failure)
+}
+
+// Settles the promise like another, settled, promise
+func (p *Promise) adopt(i *Interpreter, other *Promise) {
+	p.resolved = true
+	p.settle(i, other.state, other.value, other.failure)
+}
+
+// Records the outcome of the promise and queues its callbacks
+func (p *Promise) settle(i *Interpreter, state promiseState, value Value, failure interface{}) {
+	p.state, p.value, p.failure = state, value, failure
+
+	if state == promiseRejected {
+		i.loop.rejected = append(i.loop.rejected, p)
+	}
+
+	for _, callback := range p.callbacks {
+		i.loop.enqueue(i, callback)
+	}
+	p.callbacks = nil
+}
+
+// Registers a callback to run as a job of the event loop once the promise
+// settles, right away if it already has. The outcome is then handled
+func (p *Promise) onSettle(i *Interpreter, callback func(i *Interpreter)) {
+	p.handled = true
+
+	if p.state == promisePending {
+		p.callbacks = append(p.callbacks, callback)
+		return
+	}
+
+	i.loop.enqueue(i, callback)
+}
+
+// Promise(executor) creates a promise and calls executor with the resolve and
+// reject functions settling it. An error raised by the executor rejects it
+func newPromise(i *Interpreter, arguments []Value) (Value, error) {
+	p := i.newPendingPromise()
+	location := i.callSite()
+
+	resolve := &builtin{name: "resolve", arity: 1, fn: func(i *Interpreter, arguments []Value) (Value, error) {
+		p.resolve(i, arguments[0])
+		return nil, nil
+	}}
+	reject := &builtin{name: "reject", arity: 1, fn: func(i *Interpreter, arguments []Value) (Value, error) {
+		p.reject(i, i.exception(arguments[0], i.callSite()))
+		return nil, nil
+	}}
+
+	_, failure := i.capture(func() Value {
+		result, err := i.callValue(arguments[0], []Value{resolve, reject})
+		if err != nil {
+			raise(location, err)
+		}
+		return result
+	})
+
+	if failure != nil {
+		p.reject(i, failure)
+	}
+
+	return p, nil
+}
+
+// Runs fn and returns the error it raised, if the program can catch it. Any
+// other panic, such as a LimitError, is raised again
+func (i *Interpreter) capture(fn func() Value) (value Value, failure interface{}) {
+	depth := len(i.frames)
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case *Exception, *loxerr.Error:
+				failure = r
+				i.frames = i.frames[:depth]
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	return fn(), nil
+}
+
+// A call to an async function. The body runs on a goroutine of its own with a
+// copy of the interpreter, taking turns with whoever resumes it, like the body
+// of a generator. It runs until it awaits a promise, and is resumed by a job of
+// the event loop once the promise settles. The promise of the call settles with
+// the outcome of the body
+type coroutine struct {
+	function    *Function
+	environment *Environment // The environment of the body, holding the arguments
+	interpreter *Interpreter // The copy of the interpreter running the body
+	promise     *Promise
+	location    *token.Token // The call that started the body
+	order       int          // Position among the async calls of the execution, the order they are closed in
+	started     bool
+	done        bool // Whether the body has ended or has been closed
+	closing     bool // Whether the body is unwinding from a close, only used by the body
+	resume      chan awaited
+	suspended   chan suspension
+}
+
+// The outcome of the promise awaited by the body, given back to the await
+// expression the body is suspended at
+type awaited struct {
+	value   Value
+	failure interface{}
+	exit    bool // Whether the body must be closed instead
+	dropped bool // Whether the closed body skips its finally blocks
+}
+
+// The promise the body awaits, or how it ended
+type suspension struct {
+	promise *Promise // The awaited promise, nil once the body has ended
+	value   Value
+	failure interface{} // Rejects the promise of the call if the program can catch it, otherwise raised again
+}
+
+// Raised at the await expression an async function is suspended at when the
+// execution ends, unwinding its body. Its finally blocks run, unless the
+// execution was stopped by a limit
+type asyncExit struct {
+	dropped bool
+}
+
+// Calls an async function whose arguments are already bound in the environment.
+// The body runs right away, up to its first await
+func (i *Interpreter) callAsync(function *Function, environment *Environment) *Promise {
+	i.allocate(functionSize)
+
+	c := &coroutine{
+		function:    function,
+		environment: environment,
+		promise:     i.newPendingPromise(),
+		location:    i.callSite(),
+		resume:      make(chan awaited),
+		suspended:   make(chan suspension),
+	}
+	c.interpreter = i.fork()
+	c.interpreter.coroutine = c
+	i.loop.track(c)
+	go c.run()
+
+	c.step(i, awaited{})
+
+	return c.promise
+}
+
+// Runs the body until it awaits or ends, resuming it with the outcome of the
+// promise it awaited last
+func (c *coroutine) step(i *Interpreter, outcome awaited) {
+	if c.done {
+		return
+	}
+
+	s := c.switchTo(i, outcome)
+	if s.promise != nil {
+		s.promise.onSettle(i, func(i *Interpreter) {
+			c.step(i, awaited{value: s.promise.value, failure: s.promise.failure})
+		})
+		return
+	}
+
+	c.done = true
+	delete(i.loop.coroutines, c)
+
+	switch s.failure.(type) {
+	case nil:
+		c.promise.resolve(i, s.value)
+	case *Exception, *loxerr.Error:
+		c.promise.reject(i, s.failure)
+	default:
+		panic(s.failure)
+	}
+}
+
+// Resumes the body with the outcome and waits for it to suspend again. The body
+// continues the stack and the budget of whoever resumes it. The first step
+// continues the call itself
+func (c *coroutine) switchTo(i *Interpreter, outcome awaited) suspension {
+	body := c.interpreter
+	body.budget = i.budget
+	body.frames = i.frames[:len(i.frames):len(i.frames)]
+	if c.started {
+		defer i.enterCall()()
+		body.frames = append(body.frames, callFrame{callee: c.function, site: c.location})
+	}
+	c.started = true
+
+	c.resume <- outcome
+	s := <-c.suspended
+	i.budget = body.budget
+
+	// A suspended body must not keep the values of its caller alive
+	body.frames = nil
+
+	return s
+}
+
+// Runs the body on its goroutine, once it is resumed for the first time
+func (c *coroutine) run() {
+	var s suspension
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(asyncExit); !ok {
+				s.failure = r
+			}
+		}
+		c.suspended <- s
+	}()
+
+	<-c.resume
+	s.value, _ = c.interpreter.executeFunctionBody(c.function.declaration.Body, c.environment)
+}
+
+// Suspends the body until the promise settles, returning its value or raising
+// its error
+func (c *coroutine) await(p *Promise) Value {
+	c.suspended <- suspension{promise: p}
+
+	outcome := <-c.resume
+	switch {
+	case outcome.exit:
+		c.closing = !outcome.dropped
+		panic(asyncExit{dropped: outcome.dropped})
+	case outcome.failure != nil:
+		panic(outcome.failure)
+	}
+
+	return outcome.value
+}
+
+// Unwinds a suspended body from the await it is suspended at. Its finally blocks
+// run, unless it is dropped, and the error one of them raises is returned
+func (c *coroutine) close(i *Interpreter, dropped bool) interface{} {
+	if c.done {
+		return nil
+	}
+	c.done = true
+
+	return c.switchTo(i, awaited{exit: true, dropped: dropped}).failure
+}
+
+// VisitAwaitExpr implements the expr.Visitor interface
+//
+// An async function is suspended until the promise settles. At the top level,
+// the event loop runs until it does. Awaiting any other value gives the value
+func (i *Interpreter) VisitAwaitExpr(e *expr.Await) interface{} {
+	value := i.evaluate(e.Value)
+
+	p, ok := value.(*Promise)
+	if !ok {
+		return value
+	}
+
+	if i.coroutine != nil {
+		if i.coroutine.closing {
+			panic(loxerr.New(e.Keyword, "Can't await in an async function being closed."))
+		}
+		return i.coroutine.await(p)
+	}
+
+	p.handled = true
+	i.runLoop(e.Keyword, func() bool {
+		return p.state != promisePending
+	})
+
+	if p.state == promiseRejected {
+		panic(p.failure)
+	}
+
+	return p.value
+}
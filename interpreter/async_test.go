@@ -0,0 +1,173 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpreter_Async(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Await a call", source: "async fun add(a, b) { return a + b; } await add(1, 2);", expected: "3"},
+		{name: "Representation", source: "async fun f() { await sleep(1); } var p = f(); var before = str(p); await p; [before, p];", expected: "[<promise pending>, <promise fulfilled>]"},
+		{name: "Await any value", source: "await 1;", expected: "1"},
+		{name: "Body runs until the first await", source: "var log = []; async fun f() { log.push(1); await sleep(0); log.push(3); } var p = f(); log.push(2); await p; log;", expected: "[1, 2, 3]"},
+		{name: "Settled promises resume in order", source: "var log = []; async fun f(n) { await sleep(0); log.push(n); } var a = f(1); var b = f(2); log.push(0); await a; await b; log;", expected: "[0, 1, 2]"},
+		{name: "Timers fire by due time", source: "var log = []; setTimeout(fun() { log.push(2); }, 20); setTimeout(fun() { log.push(1); }, 10); setTimeout(fun() { log.push(3); }, 20); await sleep(30); log;", expected: "[1, 2, 3]"},
+		{name: "Interval", source: "var n = 0; var id; id = setInterval(fun() { n++; if (n == 3) clearInterval(id); }, 10); await sleep(100); n;", expected: "3"},
+		{name: "Cleared timeout", source: "var fired = false; var id = setTimeout(fun() { fired = true; }, 10); clearTimeout(id); await sleep(20); fired;", expected: "false"},
+		{name: "Concurrent sleeps", source: "var log = []; async fun wait(ms) { await sleep(ms); log.push(ms); } var a = wait(30); var b = wait(10); await a; await b; log;", expected: "[10, 30]"},
+		{name: "Then", source: "async fun f() { return 2; } await f().then((x) => x * 10).then((x) => x + 1);", expected: "21"},
+		{name: "Catch", source: "async fun f() { throw \"boom\"; } await f().catchError((e) => \"caught \" + e);", expected: "caught boom"},
+		{name: "Then passes rejections through", source: "async fun f() { throw \"boom\"; } await f().then((x) => 1).catchError((e) => e);", expected: "boom"},
+		{name: "Caught runtime error", source: "async fun f() { return 1 + null; } await f().catchError((e) => e.message);", expected: "Operands must be two numbers or two strings."},
+		{name: "Await rethrows", source: "async fun f() { await sleep(1); throw \"boom\"; } var e; try { await f(); } catch (error) { e = error; } e;", expected: "boom"},
+		{name: "Await in a try", source: "async fun fail() { throw \"boom\"; } async fun f() { try { await fail(); } catch (e) { return \"caught \" + e; } } await f();", expected: "caught boom"},
+		{name: "Promise constructor", source: "var p = Promise(fun(resolve, reject) { setTimeout(fun() { resolve(42); }, 10); }); await p;", expected: "42"},
+		{name: "Promise rejection", source: "var p = Promise(fun(resolve, reject) { reject(\"no\"); resolve(1); }); await p.catchError((e) => e);", expected: "no"},
+		{name: "Resolving with a promise", source: "async fun inner() { await sleep(5); return \"inner\"; } async fun outer() { return inner(); } await outer();", expected: "inner"},
+		{name: "Async lambda", source: "var f = async (x) => x * 2; await f(21);", expected: "42"},
+		{name: "Async method", source: "class A { async get(x) { await sleep(1); return x; } } await A().get(\"a\");", expected: "a"},
+		{name: "Static async method", source: "class A { class async make() { return A(); } } type(await A.make());", expected: "instance"},
+		{name: "Work left at the end runs", source: "var done = false; async fun f() { await sleep(10); done = true; } f(); 1;", expected: "1"},
+		{name: "Promise settled by a task", source: "var settle; var p = Promise(fun(resolve, reject) { settle = resolve; }); var c = Chan(); fun f() { c.receive(); settle(7); } spawn f(); c.send(1); await p;", expected: "7"},
+		{name: "Await in a loop", source: "async fun sum(n) { var total = 0; for (var i in 1..n) { await sleep(i); total += i; } return total; } await sum(4);", expected: "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+			i.SetClock(NewFakeClock(time.Unix(0, 0)))
+			i.RegisterNative("str", 1, func(arguments []Value) (Value, error) {
+				return Stringify(arguments[0]), nil
+			})
+			i.RegisterNative("type", 1, func(arguments []Value) (Value, error) {
+				return TypeName(arguments[0]), nil
+			})
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_AsyncErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Unhandled rejection", source: "async fun f() { throw \"boom\"; } f(); 1;", expectedErr: "Uncaught boom"},
+		{name: "Uncaught await", source: "async fun f() { throw \"boom\"; } await f();", expectedErr: "Uncaught boom"},
+		{name: "Failing timer", source: "setTimeout(fun() { return 1 + null; }, 10);", expectedErr: "Operands must be two numbers or two strings."},
+		{name: "Promise never settles", source: "var p = Promise(fun(resolve, reject) {});\nawait p;", expectedErr: "[Pos 2:1] Error at 'await': Deadlock, every task is blocked."},
+		{name: "Negative delay", source: "sleep(-1);", expectedErr: "Delay must be a non-negative number."},
+		{name: "Empty interval", source: "setInterval(fun() {}, 0);", expectedErr: "Interval must be a positive number."},
+		{name: "Timer without a function", source: "setTimeout(1, 10);", expectedErr: "Expected a function to call."},
+		{name: "Callback arity", source: "async fun f() { return 1; } await f().then(fun() {});", expectedErr: "Expected 0 arguments but got 1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetClock(NewFakeClock(time.Unix(0, 0)))
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_FakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+
+	i := New()
+	i.SetClock(clock)
+
+	start := time.Now()
+	if _, err := run(t, i, "await sleep(60 * 60 * 1000); setTimeout(fun() {}, 500);"); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the fake clock not to wait but it took %v", elapsed)
+	}
+
+	if now := clock.Now(); !now.Equal(time.Unix(3600, 500_000_000)) {
+		t.Errorf("Expected the fake clock to be at 3600.5s but got %v", now)
+	}
+}
+
+func TestInterpreter_AsyncLimits(t *testing.T) {
+	// A timer waiting on the system clock is stopped by the time limit
+	i := New()
+	i.SetLimits(Limits{Timeout: 20 * time.Millisecond})
+
+	_, err := run(t, i, "await sleep(10000);")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a timeout error but got %v", err)
+	}
+
+	// The async calls still waiting when the execution ends are closed in the
+	// order they were made, running their finally blocks but nothing else
+	before := runtime.NumGoroutine()
+
+	waiting := "var log = []; async fun f(n) { try { await Promise(fun(resolve, reject) {}); log.push(0); } finally { log.push(n); } } "
+
+	i = New()
+	if _, err := run(t, i, waiting+"f(1); f(2);"); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	if log, _ := i.Globals().Lookup("log"); Stringify(log) != "[1, 2]" {
+		t.Errorf("Expected the finally blocks to run but got %v", Stringify(log))
+	}
+
+	// An execution stopped by a limit drops them without running their finally
+	// blocks
+	i = New()
+	i.SetLimits(Limits{MaxSteps: 1000})
+	if _, err := run(t, i, waiting+"f(1); while (true) {}"); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("Expected a step limit error but got %v", err)
+	}
+
+	if log, _ := i.Globals().Lookup("log"); Stringify(log) != "[]" {
+		t.Errorf("Expected the finally block not to run but got %v", Stringify(log))
+	}
+
+	// The error of a finally block fails the execution, and a finally block can't
+	// await while its function is being closed
+	for source, expected := range map[string]string{
+		"async fun g() { try { await Promise(fun(resolve, reject) {}); } finally { throw \"cleanup\"; } } g();": "cleanup",
+		"async fun g() { try { await Promise(fun(resolve, reject) {}); } finally { await sleep(1); } } g();":    "Can't await in an async function being closed.",
+	} {
+		if _, err := run(t, New(), source); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q but got %v", expected, err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected %d goroutines after the execution but got %d", before, after)
+	}
+}
@@ -0,0 +1,75 @@
+package interpreter
+
+import "golox/token"
+
+// Value is any value a Lox program can work with. At runtime the values are
+// represented with the following Go types:
+//
+//   - null    → nil
+//   - boolean → bool
+//   - number  → float64
+//   - string  → string
+//   - list    → *List
+//   - map     → *Map
+//   - callables implement the Callable interface
+type Value = interface{}
+
+// Callable is implemented by every value that can be called from Lox
+type Callable interface {
+	// Arity returns the number of arguments the callable expects, or Variadic
+	// when any number of arguments is accepted
+	Arity() int
+	// Call invokes the callable with already evaluated arguments. A returned error
+	// is reported as a runtime error at the call site
+	Call(i *Interpreter, arguments []Value) (Value, error)
+}
+
+// Variadic is the arity of callables that accept any number of arguments
+const Variadic = -1
+
+// An argument passed by the name of its parameter
+type namedArgument struct {
+	name  *token.Token
+	value Value
+}
+
+// Implemented by the callables declared in Lox, functions and classes. They bind
+// the arguments to their parameters themselves, which lets them accept named
+// arguments and report missing or unexpected arguments by parameter name
+type parameterCallable interface {
+	Callable
+	callNamed(i *Interpreter, arguments []Value, named []namedArgument) (Value, error)
+}
+
+// A built-in method of a runtime type, such as the methods of lists
+type method struct {
+	name  string
+	arity int
+	fn    func(i *Interpreter, receiver Value, arguments []Value) (Value, error)
+}
+
+// Binds the method to the value it is called on
+func (m *method) bind(receiver Value) *boundMethod {
+	return &boundMethod{method: m, receiver: receiver}
+}
+
+// A built-in method bound to the value it is called on
+type boundMethod struct {
+	method   *method
+	receiver Value
+}
+
+// Arity implements the Callable interface
+func (b *boundMethod) Arity() int {
+	return b.method.arity
+}
+
+// Call implements the Callable interface
+func (b *boundMethod) Call(i *Interpreter, arguments []Value) (Value, error) {
+	return b.method.fn(i, b.receiver, arguments)
+}
+
+// String returns the string representation of the method
+func (b *boundMethod) String() string {
+	return "<native method " + b.method.name + ">"
+}
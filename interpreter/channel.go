@@ -0,0 +1,198 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+	"strconv"
+)
+
+// Chan is a channel, through which tasks send values to each other. A channel
+// buffers up to its capacity values, a send waits while the buffer is full. A
+// channel without capacity is unbuffered: a send waits until its value is
+// received. A receive waits until a value is sent, or returns null once the
+// channel is closed and its buffer is empty
+type Chan struct {
+	capacity int
+	buffer   []Value
+	sent     int64 // Number of values sent, the sequence number of the last one
+	received int64 // Number of values received
+	closed   bool
+}
+
+// Get returns the built-in method of the channel with the given name
+func (c *Chan) Get(name *token.Token) Value {
+	if method, ok := chanMethods[name.Lexeme]; ok {
+		return method.bind(c)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the channel
+func (c *Chan) String() string {
+	return "<channel " + strconv.Itoa(len(c.buffer)) + "/" + strconv.Itoa(c.capacity) + ">"
+}
+
+// Built-in methods of channels, receiving the channel they were called on
+var chanMethods = map[string]*method{
+	"send":     {name: "send", arity: 1, fn: chanSend},
+	"receive":  {name: "receive", arity: 0, fn: chanReceive},
+	"close":    {name: "close", arity: 0, fn: chanClose},
+	"iterator": {name: "iterator", arity: 0, fn: chanIterator},
+}
+
+func chanSend(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	receiver.(*Chan).send(i, arguments[0], i.callSite())
+	return nil, nil
+}
+
+func chanReceive(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	value, _ := receiver.(*Chan).receive(i, i.callSite())
+	return value, nil
+}
+
+func chanClose(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	c := receiver.(*Chan)
+	if c.closed {
+		panic(loxerr.New(i.callSite(), "Can't close a closed channel."))
+	}
+
+	c.closed = true
+	i.scheduler.notify()
+
+	return nil, nil
+}
+
+// Returns an iterator over the values received from a channel, until it is
+// closed
+func chanIterator(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	c := receiver.(*Chan)
+	location := i.callSite()
+
+	i.allocate(functionSize)
+
+	return &Iterator{kind: "channel", next: func() (Value, bool) {
+		return c.receive(i, location)
+	}}, nil
+}
+
+// Sends a value, as a call at the given location, waiting for room in the
+// buffer, or for the value to be received if the channel is unbuffered
+func (c *Chan) send(i *Interpreter, value Value, location *token.Token) {
+	i.scheduler.wait(i, location, func() bool {
+		return c.closed || len(c.buffer) < max(c.capacity, 1)
+	})
+
+	if c.closed {
+		panic(loxerr.New(location, "Can't send on a closed channel."))
+	}
+
+	i.allocate(elementSize)
+	c.buffer = append(c.buffer, value)
+	c.sent++
+	i.scheduler.notify()
+
+	if c.capacity == 0 {
+		sequence := c.sent
+		i.scheduler.wait(i, location, func() bool {
+			return c.received >= sequence
+		})
+	}
+}
+
+// Receives a value, as a call at the given location, waiting for one to be
+// sent. The second return value is false once the channel is closed and empty
+func (c *Chan) receive(i *Interpreter, location *token.Token) (Value, bool) {
+	i.scheduler.wait(i, location, c.ready)
+
+	return c.take(i)
+}
+
+// Reports whether a receive would not wait
+func (c *Chan) ready() bool {
+	return len(c.buffer) > 0 || c.closed
+}
+
+// Takes the oldest value of the buffer of a ready channel
+func (c *Chan) take(i *Interpreter) (Value, bool) {
+	if len(c.buffer) == 0 {
+		return nil, false
+	}
+
+	value := c.buffer[0]
+	c.buffer[0] = nil
+	c.buffer = c.buffer[1:]
+	c.received++
+	i.scheduler.notify()
+
+	return value, true
+}
+
+// Defines the functions creating and selecting channels
+func (i *Interpreter) loadChannels() {
+	i.builtins.Define("Chan", &builtin{name: "Chan", arity: Variadic, fn: newChan})
+	i.builtins.Define("select", &builtin{name: "select", arity: 1, fn: selectChan})
+}
+
+// Chan(capacity) creates a channel, unbuffered if the capacity is omitted
+func newChan(i *Interpreter, arguments []Value) (Value, error) {
+	if len(arguments) > 1 {
+		return nil, kindError(loxerr.TypeError, "Expected 0 or 1 arguments but got "+strconv.Itoa(len(arguments))+".")
+	}
+
+	capacity := int64(0)
+	if len(arguments) == 1 {
+		n, ok := toInteger(arguments[0])
+		if !ok || n < 0 {
+			panic(loxerr.New(i.callSite(), "Channel capacity must be a non-negative integer."))
+		}
+		capacity = n
+	}
+
+	i.allocate(listSize)
+
+	return &Chan{capacity: int(capacity)}, nil
+}
+
+// select(channels) waits until one of the channels is ready and receives from
+// it, returning the channel and the received value as a list. Channels are
+// tried in order, a closed channel is ready and gives null
+func selectChan(i *Interpreter, arguments []Value) (Value, error) {
+	channels, ok := channelsOf(arguments[0])
+	if !ok {
+		panic(loxerr.NewKind(i.callSite(), loxerr.TypeError, "Expected a list of channels."))
+	}
+
+	var selected *Chan
+	i.scheduler.wait(i, i.callSite(), func() bool {
+		for _, c := range channels {
+			if c.ready() {
+				selected = c
+				return true
+			}
+		}
+		return false
+	})
+
+	value, _ := selected.take(i)
+	i.allocate(listSize + 2*elementSize)
+
+	return NewList([]Value{selected, value}), nil
+}
+
+// Returns the channels of a non-empty list holding only channels
+func channelsOf(value Value) ([]*Chan, bool) {
+	list, ok := value.(*List)
+	if !ok || len(list.Elements) == 0 {
+		return nil, false
+	}
+
+	channels := make([]*Chan, len(list.Elements))
+	for idx, element := range list.Elements {
+		if channels[idx], ok = element.(*Chan); !ok {
+			return nil, false
+		}
+	}
+
+	return channels, true
+}
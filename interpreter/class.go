@@ -0,0 +1,254 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/stmt"
+	"golox/token"
+)
+
+// Class is the runtime representation of a class declared in Lox
+type Class struct {
+	Name       string
+	Superclass *Class
+	members
+}
+
+// The members of a class or a trait by their name
+type members struct {
+	methods map[string]*Function
+	statics map[string]*Function // Methods called on the class itself
+	getters map[string]*Function
+	setters map[string]*Function
+}
+
+// Creates the members declared in the body of a class or a trait, closing over
+// the given environment
+func newMembers(declarations stmt.Members, closure *Environment) members {
+	m := members{
+		methods: make(map[string]*Function, len(declarations.Methods)),
+		statics: make(map[string]*Function, len(declarations.StaticMethods)),
+		getters: make(map[string]*Function, len(declarations.Getters)),
+		setters: make(map[string]*Function, len(declarations.Setters)),
+	}
+
+	for _, method := range declarations.Methods {
+		m.methods[method.Name.Lexeme] = NewFunction(method, closure, method.Name.Lexeme == "init")
+	}
+
+	for _, kind := range []struct {
+		declarations []*stmt.Function
+		functions    map[string]*Function
+	}{{declarations.StaticMethods, m.statics}, {declarations.Getters, m.getters}, {declarations.Setters, m.setters}} {
+		for _, declaration := range kind.declarations {
+			kind.functions[declaration.Name.Lexeme] = NewFunction(declaration, closure, false)
+		}
+	}
+
+	return m
+}
+
+// Returns the members of every kind, in the same order for every value
+func (m *members) kinds() []map[string]*Function {
+	return []map[string]*Function{m.methods, m.statics, m.getters, m.setters}
+}
+
+// NewClass creates a new class with the given methods
+func NewClass(name string, superclass *Class, methods map[string]*Function) *Class {
+	return &Class{
+		Name:       name,
+		Superclass: superclass,
+		members: members{
+			methods: methods,
+			statics: make(map[string]*Function),
+			getters: make(map[string]*Function),
+			setters: make(map[string]*Function),
+		},
+	}
+}
+
+// Mixes the members of the traits into the class. Members declared by the class
+// itself take precedence over the ones of the traits, but a member provided by
+// two different traits is a conflict the class has to resolve by declaring it.
+// Returns the error message of the first conflict, if any
+func (c *Class) mixin(traits []*Trait) string {
+	declared := make(map[string]bool)
+	for _, kind := range c.kinds() {
+		for name := range kind {
+			declared[name] = true
+		}
+	}
+
+	origins := make(map[string]*Trait)
+	for _, trait := range traits {
+		classKinds := c.kinds()
+
+		for idx, kind := range trait.kinds() {
+			for name, function := range kind {
+				if declared[name] {
+					continue
+				}
+
+				if origin, ok := origins[name]; ok && origin != trait {
+					return "Traits " + origin.Name + " and " + trait.Name + " both define '" + name +
+						"', declare it in " + c.Name + " to resolve the conflict."
+				}
+
+				origins[name] = trait
+				classKinds[idx][name] = function
+			}
+		}
+	}
+
+	return ""
+}
+
+// FindMethod looks up a method from the class or its superclasses
+func (c *Class) FindMethod(name string) (*Function, bool) {
+	return c.find(name, func(class *Class) map[string]*Function { return class.methods })
+}
+
+// Reports whether the class is the other class or one of its subclasses
+func (c *Class) isSubclassOf(other *Class) bool {
+	for class := c; class != nil; class = class.Superclass {
+		if class == other {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindStaticMethod looks up a static method from the class or its superclasses
+func (c *Class) FindStaticMethod(name string) (*Function, bool) {
+	return c.find(name, func(class *Class) map[string]*Function { return class.statics })
+}
+
+// Looks up a member of the kind selected by members from the class or its superclasses
+func (c *Class) find(name string, members func(class *Class) map[string]*Function) (*Function, bool) {
+	for class := c; class != nil; class = class.Superclass {
+		if member, ok := members(class)[name]; ok {
+			return member, true
+		}
+	}
+
+	return nil, false
+}
+
+// Looks up the getter and the setter of a property. A subclass declaring either
+// one of them overrides both of the superclass
+func (c *Class) findAccessors(name string) (getter, setter *Function) {
+	for class := c; class != nil; class = class.Superclass {
+		getter, setter = class.getters[name], class.setters[name]
+		if getter != nil || setter != nil {
+			return getter, setter
+		}
+	}
+
+	return nil, nil
+}
+
+// Get returns a static method bound to the class
+func (c *Class) Get(name *token.Token) Value {
+	if method, ok := c.FindStaticMethod(name.Lexeme); ok {
+		return method.bind(c)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// Arity implements the Callable interface. The arity of a class is the arity of
+// its initializer
+func (c *Class) Arity() int {
+	if initializer, ok := c.FindMethod("init"); ok {
+		return initializer.Arity()
+	}
+
+	return 0
+}
+
+// Call implements the Callable interface. Calling a class creates a new instance
+// of it and runs the initializer, if there is one
+func (c *Class) Call(i *Interpreter, arguments []Value) (Value, error) {
+	return c.callNamed(i, arguments, nil)
+}
+
+func (c *Class) callNamed(i *Interpreter, arguments []Value, named []namedArgument) (Value, error) {
+	initializer, ok := c.FindMethod("init")
+	if !ok {
+		if len(named) > 0 {
+			return nil, kindError(loxerr.TypeError, "Unexpected named argument '"+named[0].name.Lexeme+"' for "+c.Name+".")
+		}
+		if err := checkArity(c, len(arguments)); err != nil {
+			return nil, err
+		}
+	}
+
+	i.allocate(instanceSize)
+	instance := NewInstance(c)
+
+	if ok {
+		if _, err := initializer.Bind(instance).callNamed(i, arguments, named); err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// String returns the string representation of the class
+func (c *Class) String() string {
+	return c.Name
+}
+
+// Trait is the runtime representation of a trait declared in Lox, a set of
+// members that classes can mix in
+type Trait struct {
+	Name string
+	members
+}
+
+// String returns the string representation of the trait
+func (t *Trait) String() string {
+	return "<trait " + t.Name + ">"
+}
+
+// Instance is an instance of a Lox class
+type Instance struct {
+	class  *Class
+	fields map[string]Value
+	frozen bool
+}
+
+// NewInstance creates a new instance of the given class without running its initializer
+func NewInstance(class *Class) *Instance {
+	return &Instance{class: class, fields: make(map[string]Value)}
+}
+
+// Class returns the class of the instance
+func (in *Instance) Class() *Class {
+	return in.class
+}
+
+// Get returns the value of a field, or a method bound to the instance. Fields
+// shadow methods. Getters are not run, see Interpreter.getProperty
+func (in *Instance) Get(name *token.Token) Value {
+	if value, ok := in.fields[name.Lexeme]; ok {
+		return value
+	}
+
+	if method, ok := in.class.FindMethod(name.Lexeme); ok {
+		return method.Bind(in)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// Set sets the value of a field
+func (in *Instance) Set(name *token.Token, value Value) {
+	in.fields[name.Lexeme] = value
+}
+
+// String returns the string representation of the instance
+func (in *Instance) String() string {
+	return in.class.Name + " instance"
+}
@@ -0,0 +1,89 @@
+package interpreter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time to the event loop, which waits on it for the timers to
+// be due. The system clock is used by default, a FakeClock makes the timers
+// fire without waiting
+type Clock interface {
+	Now() time.Time
+
+	// Sleep waits for the given duration, or returns the error of the context
+	// if it is done first
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// The clock of the operating system
+type systemClock struct{}
+
+// Now implements the Clock interface
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements the Clock interface
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FakeClock is a Clock whose time only moves when it sleeps or is advanced.
+// Sleeping returns at once, so the timers of a program fire in order without
+// any actual waiting, which makes them deterministic in tests
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates a fake clock starting at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now implements the Clock interface
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Sleep implements the Clock interface by advancing the clock
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.Advance(d)
+
+	return nil
+}
+
+// Advance moves the time of the clock forward
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// SetClock sets the clock that the timers of the event loop wait on
+func (i *Interpreter) SetClock(clock Clock) {
+	i.loop.clock = clock
+}
+
+// Clock returns the clock that the timers of the event loop wait on
+func (i *Interpreter) Clock() Clock {
+	return i.loop.clock
+}
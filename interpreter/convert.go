@@ -0,0 +1,249 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var valueType = reflect.TypeOf((*Value)(nil)).Elem()
+
+// ToValue converts a Go value into a Lox value. Supported are nil, booleans,
+// strings, all numeric types, slices and arrays, which are converted into lists,
+// maps with boolean, string or numeric keys, callables and functions, which are
+// wrapped into native functions
+func ToValue(v interface{}) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch value := v.(type) {
+	case bool, string, float64, Callable, *List, *Map, *Instance:
+		return value, nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Func:
+		return wrapFunc("anonymous", rv)
+	case reflect.Slice, reflect.Array:
+		return listFromSlice(rv)
+	case reflect.Map:
+		return mapFromGoMap(rv)
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert Go value of type %T into a Lox value", v)
+}
+
+// FromValue converts a Lox value into a Go value of the given type. Numbers can be
+// converted into any numeric type as long as the value fits into it without
+// losing precision
+func FromValue(v Value, t reflect.Type) (reflect.Value, error) {
+	if t == valueType {
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(v), nil
+	}
+
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Map, reflect.Slice:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("expected %s but got null", t)
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return numberFromValue(v, t)
+	case reflect.Bool:
+		if b, ok := v.(bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+	case reflect.String:
+		if s, ok := v.(string); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case reflect.Slice:
+		if list, ok := v.(*List); ok {
+			return sliceFromList(list, t)
+		}
+	case reflect.Map:
+		if m, ok := v.(*Map); ok {
+			return goMapFromMap(m, t)
+		}
+	}
+
+	if rv := reflect.ValueOf(v); rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("expected %s but got %s", t, TypeName(v))
+}
+
+func listFromSlice(rv reflect.Value) (Value, error) {
+	elements := make([]Value, rv.Len())
+
+	for idx := range elements {
+		element, err := ToValue(rv.Index(idx).Interface())
+		if err != nil {
+			return nil, err
+		}
+		elements[idx] = element
+	}
+
+	return NewList(elements), nil
+}
+
+func sliceFromList(list *List, t reflect.Type) (reflect.Value, error) {
+	slice := reflect.MakeSlice(t, len(list.Elements), len(list.Elements))
+
+	for idx, element := range list.Elements {
+		converted, err := FromValue(element, t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", idx, err)
+		}
+		slice.Index(idx).Set(converted)
+	}
+
+	return slice, nil
+}
+
+func mapFromGoMap(rv reflect.Value) (Value, error) {
+	m := NewMap()
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := ToValue(iter.Key().Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		hash, ok := primitiveHash(key)
+		if !ok {
+			return nil, fmt.Errorf("cannot use Go value of type %s as a map key", iter.Key().Type())
+		}
+
+		value, err := ToValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		m.store(hash, key, value, nil)
+	}
+
+	return m, nil
+}
+
+func goMapFromMap(m *Map, t reflect.Type) (reflect.Value, error) {
+	goMap := reflect.MakeMapWithSize(t, m.Len())
+
+	for _, entry := range m.entries {
+		if entry.removed {
+			continue
+		}
+
+		key, err := FromValue(entry.key, t.Key())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %s: %w", Stringify(entry.key), err)
+		}
+
+		value, err := FromValue(entry.value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("value of key %s: %w", Stringify(entry.key), err)
+		}
+		goMap.SetMapIndex(key, value)
+	}
+
+	return goMap, nil
+}
+
+func numberFromValue(v Value, t reflect.Type) (reflect.Value, error) {
+	n, ok := v.(float64)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("expected a number but got %s", TypeName(v))
+	}
+
+	converted := reflect.ValueOf(n).Convert(t)
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return converted, nil
+	}
+
+	if n != math.Trunc(n) {
+		return reflect.Value{}, fmt.Errorf("expected an integer but got %v", n)
+	}
+
+	var back float64
+	if converted.CanInt() {
+		back = float64(converted.Int())
+	} else {
+		back = float64(converted.Uint())
+	}
+
+	if back != n {
+		return reflect.Value{}, fmt.Errorf("number %v does not fit into %s", n, t)
+	}
+
+	return converted, nil
+}
+
+// TypeName returns the name of the Lox type of the given value
+func TypeName(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *Class:
+		return "class"
+	case *Trait:
+		return "trait"
+	case *Instance:
+		return "instance"
+	case *List:
+		return "list"
+	case *Map:
+		return "map"
+	case *Module:
+		return "module"
+	case *Generator:
+		return "generator"
+	case *Iterator:
+		return "iterator"
+	case Range:
+		return "range"
+	case *Task:
+		return "task"
+	case *Chan:
+		return "channel"
+	case *Promise:
+		return "promise"
+	case Callable:
+		return "function"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
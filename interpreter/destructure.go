@@ -0,0 +1,101 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"strconv"
+)
+
+// VisitDestructureExpr implements the expr.Visitor interface
+//
+// The assignment evaluates to the whole value, like a simple assignment
+func (i *Interpreter) VisitDestructureExpr(e *expr.Destructure) interface{} {
+	value := i.evaluate(e.Value)
+	i.destructure(e.Pattern, value, i.environment.Define)
+
+	return value
+}
+
+// Binds the parts of a value to the variables of a destructuring pattern with
+// define, or assigns them to the targets of a destructuring assignment. Unlike
+// a match, a value of the wrong shape is an error naming the missing element or
+// property
+func (i *Interpreter) destructure(pattern expr.Pattern, value Value, define func(name string, value Value)) {
+	switch p := pattern.(type) {
+	case *expr.BindingPattern:
+		define(p.Name.Lexeme, value)
+	case *expr.TargetPattern:
+		i.assignTarget(p.Target, value)
+	case *expr.ListPattern:
+		i.destructureList(p, value, define)
+	case *expr.InstancePattern:
+		i.destructureObject(p, value, define)
+	}
+}
+
+func (i *Interpreter) destructureList(pattern *expr.ListPattern, value Value, define func(name string, value Value)) {
+	list, ok := value.(*List)
+	if !ok {
+		panic(loxerr.NewKind(pattern.Bracket, loxerr.TypeError, "Expected a list to destructure but got "+TypeName(value)+"."))
+	}
+
+	count := len(pattern.Elements)
+	for idx, element := range pattern.Elements {
+		if idx >= len(list.Elements) {
+			panic(loxerr.NewKind(expr.PatternToken(element), loxerr.IndexError, "Missing list element at index "+strconv.Itoa(idx)+
+				", the list has "+strconv.Itoa(len(list.Elements))+" elements."))
+		}
+		i.destructure(element, list.Elements[idx], define)
+	}
+
+	if pattern.Rest == nil {
+		if len(list.Elements) > count {
+			panic(loxerr.NewKind(pattern.Bracket, loxerr.IndexError, "Too many elements to destructure, expected "+strconv.Itoa(count)+
+				" but got "+strconv.Itoa(len(list.Elements))+"."))
+		}
+		return
+	}
+
+	if pattern.Rest.Lexeme != "_" {
+		rest := make([]Value, len(list.Elements)-count)
+		copy(rest, list.Elements[count:])
+
+		i.allocate(listSize + len(rest)*elementSize)
+		define(pattern.Rest.Lexeme, NewList(rest))
+	}
+}
+
+func (i *Interpreter) destructureObject(pattern *expr.InstancePattern, value Value, define func(name string, value Value)) {
+	if !i.isPatternObject(pattern, value) {
+		expected := "an instance or a map"
+		if pattern.Class != nil {
+			expected = "a " + pattern.Class.Name.Lexeme + " instance"
+		}
+		panic(loxerr.NewKind(expr.PatternToken(pattern), loxerr.TypeError, "Expected "+expected+" to destructure but got "+TypeName(value)+"."))
+	}
+
+	for _, field := range pattern.Fields {
+		property, ok := i.patternProperty(value, field.Name)
+		if !ok {
+			if instance, isInstance := value.(*Instance); isInstance {
+				panic(loxerr.NewKind(field.Name, loxerr.NameError, "Missing property '"+field.Name.Lexeme+"' in "+Stringify(instance)+"."))
+			}
+			panic(loxerr.NewKind(field.Name, loxerr.IndexError, "Missing key '"+field.Name.Lexeme+"' in map."))
+		}
+
+		i.destructure(field.Pattern, property, define)
+	}
+}
+
+// Assigns a value to a variable, a property or an index
+func (i *Interpreter) assignTarget(target expr.Expr, value Value) {
+	switch t := target.(type) {
+	case *expr.Variable:
+		i.environment.Assign(t.Name, value)
+	case *expr.Get:
+		i.setProperty(fieldsOf(i.evaluate(t.Object), t.Name), t.Name, value)
+	case *expr.Index:
+		object := i.evaluate(t.Object)
+		i.setIndex(object, i.evaluate(t.Index), value, t.Bracket)
+	}
+}
@@ -0,0 +1,85 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+)
+
+// Environment holds the variable bindings of a single scope. Scopes are chained
+// through the enclosing environment all the way up to the globals
+type Environment struct {
+	enclosing *Environment
+	values    map[string]Value
+	constants map[string]bool // The variables of this scope that can't be assigned, nil when there are none
+}
+
+// NewEnvironment creates a new environment nested inside the enclosing one. The
+// global environment has no enclosing environment
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		enclosing: enclosing,
+		values:    make(map[string]Value),
+	}
+}
+
+// Define binds a new variable in this scope, shadowing any variable with the same
+// name in the enclosing scopes
+func (e *Environment) Define(name string, value Value) {
+	e.values[name] = value
+	delete(e.constants, name)
+}
+
+// DefineConstant binds a new constant in this scope, a variable that can't be
+// assigned
+func (e *Environment) DefineConstant(name string, value Value) {
+	e.values[name] = value
+
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+	e.constants[name] = true
+}
+
+// Get looks up the variable with the given name, walking up the scope chain
+func (e *Environment) Get(name *token.Token) Value {
+	if value, ok := e.values[name.Lexeme]; ok {
+		return value
+	}
+
+	if e.enclosing != nil {
+		return e.enclosing.Get(name)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined variable '"+name.Lexeme+"'."))
+}
+
+// Assign sets a new value for an existing variable, walking up the scope chain.
+// Assigning a constant is a runtime error
+func (e *Environment) Assign(name *token.Token, value Value) {
+	if _, ok := e.values[name.Lexeme]; ok {
+		if e.constants[name.Lexeme] {
+			panic(loxerr.NewKind(name, loxerr.TypeError, "Can't assign to constant '"+name.Lexeme+"'."))
+		}
+		e.values[name.Lexeme] = value
+		return
+	}
+
+	if e.enclosing != nil {
+		e.enclosing.Assign(name, value)
+		return
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined variable '"+name.Lexeme+"'."))
+}
+
+// Lookup returns the value of the variable with the given name without raising
+// a runtime error. The second return value reports whether the variable exists
+func (e *Environment) Lookup(name string) (Value, bool) {
+	for env := e; env != nil; env = env.enclosing {
+		if value, ok := env.values[name]; ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
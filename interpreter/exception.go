@@ -0,0 +1,201 @@
+package interpreter
+
+import (
+	"errors"
+	loxerr "golox/error"
+	"golox/lexer"
+	"golox/parser"
+	"golox/stmt"
+	"golox/token"
+	"strconv"
+	"strings"
+)
+
+// The error classes every interpreter starts with. Runtime errors raised by the
+// interpreter itself are caught as instances of the subclasses of Error
+const preludeSource = `
+class Error {
+	init(message = "") {
+		this.message = message;
+		this.stack = null;
+	}
+}
+
+class TypeError < Error {}
+class NameError < Error {}
+class IndexError < Error {}
+class RuntimeError < Error {}
+class StopIteration < Error {}
+`
+
+var prelude = parsePrelude()
+
+// Parses the prelude once, it is executed by every new interpreter
+func parsePrelude() []stmt.Stmt {
+	l := lexer.New(preludeSource)
+	l.ScanTokens()
+
+	statements, errors := parser.New(l.Tokens).ParseProgram()
+	if len(errors) > 0 {
+		panic("invalid prelude: " + errors[0].Error())
+	}
+
+	return statements
+}
+
+// Exception is the error returned when a value thrown by a throw statement is
+// not caught by the program
+type Exception struct {
+	Value       Value        // The thrown value
+	Token       *token.Token // The throw keyword
+	description string
+}
+
+func (e *Exception) Error() string {
+	return loxerr.New(e.Token, "Uncaught "+e.description).Error()
+}
+
+// Creates the exception raised by throwing a value at the given location
+func (i *Interpreter) exception(value Value, location *token.Token) *Exception {
+	i.fillStackTrace(value, location)
+
+	return &Exception{Value: value, Token: location, description: i.describeException(value)}
+}
+
+// Describes a thrown value for the error message, instances of Error by their
+// class and message
+func (i *Interpreter) describeException(value Value) string {
+	instance, ok := i.errorInstance(value)
+	if !ok {
+		return Stringify(value)
+	}
+
+	message, ok := instance.fields["message"]
+	if !ok || message == nil || message == "" {
+		return instance.class.Name
+	}
+
+	return instance.class.Name + ": " + Stringify(message)
+}
+
+// Reports whether the value is an instance of Error or one of its subclasses
+func (i *Interpreter) errorInstance(value Value) (*Instance, bool) {
+	instance, ok := value.(*Instance)
+	if !ok || !instance.class.isSubclassOf(i.errorClasses["Error"]) {
+		return nil, false
+	}
+
+	return instance, true
+}
+
+// A call in progress, used for the stack traces of the errors
+type callFrame struct {
+	callee Callable
+	site   *token.Token // The closing parenthesis of the call
+}
+
+// Returns the stack trace of the current execution as a list of lines, from
+// the innermost call to the script. The location is the token the error was
+// raised at
+func (i *Interpreter) stackTrace(location *token.Token) *List {
+	lines := make([]Value, 0, len(i.frames)+1)
+
+	for idx := len(i.frames) - 1; idx >= 0; idx-- {
+		frame := i.frames[idx]
+		lines = append(lines, "[line "+strconv.Itoa(location.Line)+"] in "+calleeName(frame.callee)+"()")
+		location = frame.site
+	}
+	lines = append(lines, "[line "+strconv.Itoa(location.Line)+"] in script")
+
+	i.allocate(listSize + len(lines)*elementSize)
+
+	return NewList(lines)
+}
+
+// Returns the name of a callable for the stack traces
+func calleeName(callee Callable) string {
+	switch c := callee.(type) {
+	case *Function:
+		return c.declaration.Name.Lexeme
+	case *Class:
+		return c.Name
+	case *Native:
+		return c.Name
+	case *builtin:
+		return c.name
+	case *boundMethod:
+		return c.method.name
+	}
+
+	return strings.Trim(Stringify(callee), "<>")
+}
+
+// Converts a recovered panic into the value bound by a catch block. Values
+// thrown by the program are caught as is and runtime errors as instances of
+// the error classes. Anything else, such as a LimitError, can't be caught
+func (i *Interpreter) caughtValue(r interface{}) (Value, bool) {
+	switch e := r.(type) {
+	case *Exception:
+		return e.Value, true
+	case *loxerr.Error:
+		return i.newError(e), true
+	}
+
+	return nil, false
+}
+
+// Creates an instance of the error class named after the kind of a runtime
+// error, without running the initializer
+func (i *Interpreter) newError(err *loxerr.Error) *Instance {
+	i.allocate(instanceSize + 2*fieldSize)
+	instance := NewInstance(i.errorClasses[err.Kind.String()])
+	instance.fields["message"] = err.Message
+	instance.fields["stack"] = i.stackTrace(err.Token)
+
+	return instance
+}
+
+// Returns the error of a native function or a call raising a runtime error of
+// the given kind
+func kindError(kind loxerr.Kind, message string) error {
+	return &loxerr.KindError{Kind: kind, Err: errors.New(message)}
+}
+
+// Converts the error returned by a call into a runtime error at the given
+// location, of the kind of a KindError and a RuntimeError otherwise
+func runtimeError(location *token.Token, err error) *loxerr.Error {
+	var kindErr *loxerr.KindError
+	if errors.As(err, &kindErr) {
+		return loxerr.NewKind(location, kindErr.Kind, err.Error())
+	}
+
+	return loxerr.New(location, err.Error())
+}
+
+// Fills in the stack trace of a thrown instance of Error, unless it has been
+// thrown before
+func (i *Interpreter) fillStackTrace(value Value, location *token.Token) {
+	instance, ok := i.errorInstance(value)
+	if !ok {
+		return
+	}
+
+	if stack, ok := instance.fields["stack"]; !ok || stack == nil {
+		i.allocate(fieldSize)
+		instance.fields["stack"] = i.stackTrace(location)
+	}
+}
+
+// Executes the prelude and keeps the error classes it defines, so that runtime
+// errors can be converted into instances of them even if the program shadows
+// the globals
+func (i *Interpreter) loadPrelude() {
+	i.executeBlock(prelude, i.builtins)
+
+	i.errorClasses = make(map[string]*Class)
+	for _, statement := range prelude {
+		name := statement.(*stmt.Class).Name.Lexeme
+		class, _ := i.builtins.Lookup(name)
+		i.errorClasses[name] = class.(*Class)
+	}
+}
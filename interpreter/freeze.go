@@ -0,0 +1,62 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+)
+
+// Errors of the built-in methods modifying a frozen list or map
+var (
+	errFrozenList = kindError(loxerr.TypeError, "Can't modify a frozen list.")
+	errFrozenMap  = kindError(loxerr.TypeError, "Can't modify a frozen map.")
+)
+
+// Freeze makes an instance, a list or a map read-only: assigning its fields,
+// elements or entries is then a runtime error. Freezing is shallow, the values
+// held by a frozen value can still be modified unless they are frozen too. The
+// other values can't be modified anyway, and are left as they are
+func Freeze(value Value) Value {
+	switch v := value.(type) {
+	case *Instance:
+		v.frozen = true
+	case *List:
+		v.frozen = true
+	case *Map:
+		v.frozen = true
+	}
+
+	return value
+}
+
+// IsFrozen reports whether the value can't be modified: instances, lists and
+// maps once they are frozen, and all the other values
+func IsFrozen(value Value) bool {
+	switch v := value.(type) {
+	case *Instance:
+		return v.frozen
+	case *List:
+		return v.frozen
+	case *Map:
+		return v.frozen
+	}
+
+	return true
+}
+
+// Raises a runtime error at the given token if the value is frozen
+func checkNotFrozen(value Value, location *token.Token) {
+	switch v := value.(type) {
+	case *Instance:
+		if v.frozen {
+			panic(loxerr.NewKind(location, loxerr.TypeError, "Can't modify a frozen instance."))
+		}
+	case *List:
+		if v.frozen {
+			panic(runtimeError(location, errFrozenList))
+		}
+	case *Map:
+		if v.frozen {
+			panic(runtimeError(location, errFrozenMap))
+		}
+	}
+}
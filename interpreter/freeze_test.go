@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpreter_Freeze(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expected    string
+		expectedErr string
+	}{
+		{name: "Reading a frozen list", source: "var xs = freeze([1, 2]); [xs[0], xs.length(), xs[0:1]];", expected: "[1, 2, [1]]"},
+		{name: "Freezing is shallow", source: "var xs = freeze([[1]]); xs[0].push(2); xs;", expected: "[[1, 2]]"},
+		{name: "Copies are not frozen", source: "var xs = freeze([1])[0:1]; xs.push(2); xs;", expected: "[1, 2]"},
+		{name: "Methods of a frozen instance", source: "class P { init(x) { this.x = x; } get() { return this.x; } } freeze(P(1)).get();", expected: "1"},
+		{name: "Freezing in an initializer", source: "class P { init(x) { this.x = x; freeze(this); } } var p = P(1); var e; try { p.x = 2; } catch (error) { e = error; } [p.x, e];", expected: "[1, TypeError instance]"},
+		{name: "List index assignment", source: "var xs = freeze([1]);\nxs[0] = 2;", expectedErr: "[Pos 2:5] Error at ']': Can't modify a frozen list."},
+		{name: "List push", source: "freeze([]).push(1);", expectedErr: "Can't modify a frozen list."},
+		{name: "List pop", source: "freeze([1]).pop();", expectedErr: "Can't modify a frozen list."},
+		{name: "List insert", source: "freeze([]).insert(0, 1);", expectedErr: "Can't modify a frozen list."},
+		{name: "List remove", source: "freeze([1]).remove(0);", expectedErr: "Can't modify a frozen list."},
+		{name: "List compound assignment", source: "var xs = freeze([1]); xs[0] += 1;", expectedErr: "Can't modify a frozen list."},
+		{name: "Map assignment", source: "var m = freeze({}); m[\"a\"] = 1;", expectedErr: "Can't modify a frozen map."},
+		{name: "Map delete", source: "freeze({\"a\": 1}).delete(\"a\");", expectedErr: "Can't modify a frozen map."},
+		{name: "Instance field", source: "class P {} var p = freeze(P()); p.x = 1;", expectedErr: "Can't modify a frozen instance."},
+		{name: "Instance setter", source: "class P { set x(v) { this._x = v; } } var p = freeze(P()); p.x = 1;", expectedErr: "Can't modify a frozen instance."},
+		{name: "Destructuring into a frozen list", source: "var xs = freeze([1, 2]); [xs[0], xs[1]] = [3, 4];", expectedErr: "Can't modify a frozen list."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.RegisterNative("freeze", 1, func(arguments []Value) (Value, error) {
+				return Freeze(arguments[0]), nil
+			})
+
+			actual, err := run(t, i, tt.source)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsFrozen(t *testing.T) {
+	list := NewList(nil)
+	if IsFrozen(list) {
+		t.Error("Expected a new list not to be frozen")
+	}
+
+	if Freeze(list) != list || !IsFrozen(list) {
+		t.Error("Expected Freeze to freeze the list and return it")
+	}
+
+	for _, value := range []Value{nil, 1.0, "s", true, Range{Start: 0, End: 1}} {
+		if !IsFrozen(value) {
+			t.Errorf("Expected %v to be frozen", Stringify(value))
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+	"strconv"
+)
+
+// Function is a function declared in Lox. It captures the environment it was
+// declared in, which makes it a closure
+type Function struct {
+	declaration   *stmt.Function
+	closure       *Environment
+	isInitializer bool // Initializers always return the instance they were called on
+}
+
+// NewFunction creates a new function from its declaration and the environment
+// that it closes over
+func NewFunction(declaration *stmt.Function, closure *Environment, isInitializer bool) *Function {
+	return &Function{declaration: declaration, closure: closure, isInitializer: isInitializer}
+}
+
+// Bind returns a copy of the method with "this" bound to the given instance
+func (f *Function) Bind(instance *Instance) *Function {
+	return f.bind(instance)
+}
+
+// Binds "this" to the receiver of the method, an instance or, for static
+// methods, a class
+func (f *Function) bind(this Value) *Function {
+	environment := NewEnvironment(f.closure)
+	environment.Define("this", this)
+
+	return NewFunction(f.declaration, environment, f.isInitializer)
+}
+
+// Arity implements the Callable interface. Functions with default values or a
+// rest parameter accept a varying number of arguments and are Variadic
+func (f *Function) Arity() int {
+	for _, param := range f.declaration.Params {
+		if param.Default != nil || param.Rest {
+			return Variadic
+		}
+	}
+
+	return len(f.declaration.Params)
+}
+
+// Call implements the Callable interface
+func (f *Function) Call(i *Interpreter, arguments []Value) (Value, error) {
+	return f.callNamed(i, arguments, nil)
+}
+
+func (f *Function) callNamed(i *Interpreter, arguments []Value, named []namedArgument) (Value, error) {
+	i.allocate(environmentSize)
+
+	environment := NewEnvironment(f.closure)
+	if message := i.bindArguments(f, environment, arguments, named); message != "" {
+		return nil, kindError(loxerr.TypeError, message)
+	}
+
+	if f.declaration.Async {
+		return i.callAsync(f, environment), nil
+	}
+
+	if f.declaration.Generator {
+		return i.newGenerator(f, environment), nil
+	}
+
+	if returned, ok := i.executeFunctionBody(f.declaration.Body, environment); ok && !f.isInitializer {
+		return returned, nil
+	}
+
+	if f.isInitializer {
+		return f.closure.values["this"], nil
+	}
+
+	return nil, nil
+}
+
+// String returns the string representation of the function
+func (f *Function) String() string {
+	return "<fn " + f.declaration.Name.Lexeme + ">"
+}
+
+// Binds the arguments of a call to the parameters of the function by defining
+// them in its environment. Positional arguments fill the parameters in order,
+// with the extra ones collected by the rest parameter, then named arguments fill
+// the parameters with the same name. Parameters left without an argument get
+// their default value, evaluated in the environment of the function so that it
+// can refer to the parameters before it. Returns an error message if the
+// arguments do not match the parameters
+func (i *Interpreter) bindArguments(f *Function, environment *Environment, arguments []Value, named []namedArgument) string {
+	name := f.declaration.Name.Lexeme
+	params := f.declaration.Params
+
+	fixed := len(params)
+	hasRest := fixed > 0 && params[fixed-1].Rest
+	if hasRest {
+		fixed--
+	}
+
+	if len(arguments) > fixed && !hasRest {
+		if f.Arity() != Variadic {
+			return "Expected " + strconv.Itoa(fixed) + " arguments but got " + strconv.Itoa(len(arguments)) + "."
+		}
+		return "Expected at most " + strconv.Itoa(fixed) + " arguments but got " + strconv.Itoa(len(arguments)) + "."
+	}
+
+	values, given, message := placeArguments(name, params, fixed, arguments, named)
+	if message != "" {
+		return message
+	}
+
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+	i.environment = environment
+
+	for idx, param := range params[:fixed] {
+		if !given[idx] {
+			if param.Default == nil {
+				return "Missing argument for parameter '" + param.Name.Lexeme + "' of " + name + "."
+			}
+			values[idx] = i.evaluate(param.Default)
+		}
+		environment.Define(param.Name.Lexeme, values[idx])
+	}
+
+	if hasRest {
+		extra := []Value{}
+		if len(arguments) > fixed {
+			extra = append(extra, arguments[fixed:]...)
+		}
+
+		i.allocate(listSize + len(extra)*elementSize)
+		environment.Define(params[fixed].Name.Lexeme, NewList(extra))
+	}
+
+	return ""
+}
+
+// Places the positional and named arguments of a call at the position of their
+// parameter among the fixed ones, the parameters before the rest parameter. The
+// second return value reports which parameters got an argument. Returns an
+// error message if a named argument does not match a fixed parameter without
+// an argument
+func placeArguments(name string, params []*expr.Param, fixed int, arguments []Value, named []namedArgument) ([]Value, []bool, string) {
+	values := make([]Value, fixed)
+	given := make([]bool, fixed)
+	for idx := 0; idx < min(fixed, len(arguments)); idx++ {
+		values[idx], given[idx] = arguments[idx], true
+	}
+
+	for _, argument := range named {
+		idx := paramIndex(params, argument.name.Lexeme)
+		switch {
+		case idx < 0:
+			return nil, nil, "Unexpected named argument '" + argument.name.Lexeme + "' for " + name + "."
+		case idx == fixed:
+			return nil, nil, "Can't pass the rest parameter '" + argument.name.Lexeme + "' of " + name + " by name."
+		case given[idx]:
+			return nil, nil, "Got multiple values for parameter '" + argument.name.Lexeme + "' of " + name + "."
+		}
+		values[idx], given[idx] = argument.value, true
+	}
+
+	return values, given, ""
+}
+
+func paramIndex(params []*expr.Param, name string) int {
+	for idx, param := range params {
+		if param.Name.Lexeme == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// Used to unwind the stack of the interpreter when a return statement is executed
+type returnValue struct {
+	value   Value
+	keyword *token.Token // The return keyword, to report a return outside of a function
+}
+
+// Executes the body of a function. The second return value reports whether a
+// return statement was executed
+func (i *Interpreter) executeFunctionBody(body []stmt.Stmt, environment *Environment) (result Value, returned bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret, ok := r.(*returnValue)
+			if !ok {
+				panic(r)
+			}
+			result, returned = ret.value, true
+		}
+	}()
+
+	i.executeBlock(body, environment)
+
+	return nil, false
+}
@@ -0,0 +1,337 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/stmt"
+	"golox/token"
+	"runtime"
+	"sync"
+)
+
+// Generator is the iterator returned by calling a generator function, a function
+// whose body has a yield statement. Every call to next runs the body up to its
+// next yield and returns the yielded value.
+//
+// The body runs on a goroutine of its own, with a copy of the interpreter, and
+// takes turns with its caller: the caller waits while the body runs, and the body
+// waits while it is suspended at a yield. The goroutine is started by the first
+// call to next, and exits when the body ends, when the generator is closed, or
+// when the generator is garbage collected while suspended. Closing the generator
+// runs the finally blocks of the body, collecting it doesn't.
+//
+// A suspended goroutine keeps alive everything its body refers to, so a generator
+// reachable from its own body, such as one stored in a field of the instance
+// whose method it runs, is never collected. Interpreter.Close drops those
+type Generator struct {
+	*generator
+}
+
+// The generators of an interpreter whose body is suspended, dropped when the
+// interpreter is closed. It only refers to the state shared with the goroutines,
+// so that the Generators can still be collected
+type generators struct {
+	mu        sync.Mutex
+	suspended map[*generator]bool
+}
+
+// Records whether the body of the generator is suspended
+func (s *generators) track(g *generator, suspended bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if suspended {
+		s.suspended[g] = true
+	} else {
+		delete(s.suspended, g)
+	}
+}
+
+// Close drops the generators whose body is suspended, ending their goroutines
+// without running any more of their body, finally blocks included. It must not
+// be called while the interpreter is running. The interpreter can still be used
+// afterwards, but the dropped generators are ended
+func (i *Interpreter) Close() {
+	i.generators.mu.Lock()
+	suspended := make([]*generator, 0, len(i.generators.suspended))
+	for g := range i.generators.suspended {
+		suspended = append(suspended, g)
+	}
+	i.generators.mu.Unlock()
+
+	for _, g := range suspended {
+		g.drop()
+	}
+}
+
+// The state of a generator, shared with the goroutine running its body. The
+// goroutine never refers to the Generator itself, so that the Generator can be
+// collected while the goroutine is still waiting
+type generator struct {
+	mu          sync.Mutex // Guards the status, which is also read by the finalizer
+	status      generatorStatus
+	function    *Function
+	environment *Environment // The environment of the body, holding the arguments
+	interpreter *Interpreter // The copy of the interpreter running the body
+	tracker     *generators  // Where the generator is recorded while suspended
+	resume      chan resumeMode
+	results     chan generatorResult
+	closing     bool // Whether the body is unwinding from a close, only used by the body
+}
+
+type generatorStatus int
+
+const (
+	generatorCreated generatorStatus = iota
+	generatorSuspended
+	generatorRunning
+	generatorDone
+)
+
+// What the body of a generator yielded, or how it ended
+type generatorResult struct {
+	value Value
+	done  bool        // Whether the body ended instead of yielding
+	panic interface{} // The error that ended the body, raised again in the caller
+}
+
+// How a suspended body is resumed
+type resumeMode int
+
+const (
+	resumeNext  resumeMode = iota // Runs the body up to its next yield
+	resumeClose                   // Unwinds the body, running its finally blocks
+	resumeDrop                    // Unwinds the body without running any more of it
+)
+
+// Raised at the yield statement a generator is suspended at when it is closed,
+// unwinding its body. The finally blocks run unless the generator is dropped
+type generatorExit struct {
+	dropped bool
+}
+
+// Creates the generator of a call to a generator function, whose arguments are
+// already bound in the environment
+func (i *Interpreter) newGenerator(function *Function, environment *Environment) *Generator {
+	i.allocate(functionSize)
+
+	g := &Generator{&generator{function: function, environment: environment}}
+	runtime.SetFinalizer(g, func(g *Generator) {
+		g.drop()
+	})
+
+	return g
+}
+
+// Get returns the built-in method of the generator with the given name
+func (g *Generator) Get(name *token.Token) Value {
+	if method, ok := generatorMethods[name.Lexeme]; ok {
+		return method.bind(g)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the generator
+func (g *Generator) String() string {
+	return "<generator " + g.function.declaration.Name.Lexeme + ">"
+}
+
+// Built-in methods of generators, receiving the generator they were called on
+var generatorMethods = map[string]*method{
+	"next":     {name: "next", arity: 0, fn: generatorNext},
+	"iterator": {name: "iterator", arity: 0, fn: iteratorSelf},
+	"close":    {name: "close", arity: 0, fn: generatorClose},
+}
+
+func generatorNext(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	value, ok := receiver.(*Generator).next(i, i.callSite())
+	if !ok {
+		return nil, errExhausted
+	}
+
+	return value, nil
+}
+
+func generatorClose(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	if !receiver.(*Generator).close(i, i.callSite()) {
+		panic(runningError(i.callSite()))
+	}
+
+	return nil, nil
+}
+
+// Returns the error raised at the given location when a generator is resumed or
+// closed by its own body
+func runningError(location *token.Token) *loxerr.Error {
+	return loxerr.New(location, "Generator is already running.")
+}
+
+// Runs the body of the generator up to its next yield, as a call made at the
+// given location. The second return value is false if the generator has ended
+func (g *Generator) next(i *Interpreter, location *token.Token) (Value, bool) {
+	g.mu.Lock()
+	switch g.status {
+	case generatorRunning:
+		g.mu.Unlock()
+		panic(runningError(location))
+	case generatorDone:
+		g.mu.Unlock()
+		return nil, false
+	case generatorCreated:
+		g.interpreter = i.fork()
+		g.interpreter.generator = g.generator
+		g.tracker = i.generators
+		g.resume, g.results = make(chan resumeMode), make(chan generatorResult)
+		go g.run()
+	}
+	g.status = generatorRunning
+	g.mu.Unlock()
+
+	result := g.resumeBody(i, location, resumeNext)
+	if result.panic != nil {
+		panic(result.panic)
+	}
+
+	return result.value, !result.done
+}
+
+// Ends the generator as a call made at the given location. A suspended body is
+// unwound from its yield, running its finally blocks, and an error they raise is
+// raised again in the caller. Returns false if the generator can't be closed
+// because its body is running
+func (g *Generator) close(i *Interpreter, location *token.Token) bool {
+	g.mu.Lock()
+	status := g.status
+	switch status {
+	case generatorRunning:
+		g.mu.Unlock()
+		return false
+	case generatorSuspended:
+		g.status = generatorRunning
+	default:
+		g.status = generatorDone
+	}
+	g.mu.Unlock()
+
+	if status == generatorSuspended {
+		if result := g.resumeBody(i, location, resumeClose); result.panic != nil {
+			panic(result.panic)
+		}
+	}
+
+	return true
+}
+
+// Resumes the body as a call made at the given location, until it yields or
+// ends. The body continues the stack and the budget of its caller
+func (g *Generator) resumeBody(i *Interpreter, location *token.Token, mode resumeMode) generatorResult {
+	defer i.enterCall()()
+
+	body := g.interpreter
+	body.budget = i.budget
+	body.frames = append(i.frames[:len(i.frames):len(i.frames)], callFrame{callee: g.function, site: location})
+
+	g.resume <- mode
+	result := <-g.results
+	i.budget = body.budget
+
+	// A suspended body must not keep the values of its caller alive
+	body.frames = nil
+
+	g.mu.Lock()
+	if result.done {
+		g.status = generatorDone
+	} else {
+		g.status = generatorSuspended
+	}
+	g.mu.Unlock()
+	g.tracker.track(g.generator, !result.done)
+
+	// Keeps the finalizer from dropping the generator while its body runs
+	runtime.KeepAlive(g)
+
+	return result
+}
+
+// Ends the generator without running any more of its body, finally blocks
+// included. Used when the generator is garbage collected, when its caller is
+// stopped by a LimitError or when the interpreter is closed, when no caller is
+// left to run them
+func (g *generator) drop() {
+	g.mu.Lock()
+	status := g.status
+	if status != generatorRunning {
+		g.status = generatorDone
+	}
+	g.mu.Unlock()
+
+	if status == generatorSuspended {
+		g.resume <- resumeDrop
+		<-g.results
+		g.tracker.track(g, false)
+	}
+}
+
+// Runs the body of the generator on its goroutine, once it is resumed for the
+// first time
+func (g *generator) run() {
+	result := generatorResult{done: true}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(generatorExit); !ok {
+				result.panic = r
+			}
+		}
+		g.results <- result
+	}()
+
+	<-g.resume
+	g.interpreter.executeFunctionBody(g.function.declaration.Body, g.environment)
+}
+
+// Suspends the body with the yielded value until the generator is resumed
+func (g *generator) yield(value Value) {
+	g.results <- generatorResult{value: value}
+
+	switch <-g.resume {
+	case resumeClose:
+		g.closing = true
+		panic(generatorExit{})
+	case resumeDrop:
+		panic(generatorExit{dropped: true})
+	}
+}
+
+// VisitYieldStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitYieldStmt(s *stmt.Yield) interface{} {
+	var value Value
+	if s.Value != nil {
+		value = i.evaluate(s.Value)
+	}
+
+	if i.generator == nil {
+		panic(loxerr.New(s.Keyword, "Can't use 'yield' outside of a generator."))
+	}
+
+	if i.generator.closing {
+		panic(loxerr.New(s.Keyword, "Can't yield from a generator being closed."))
+	}
+
+	i.generator.yield(value)
+
+	return nil
+}
+
+// Returns a copy of the interpreter to run code on another goroutine. The copy
+// shares the globals, the modules and the output with the interpreter, but has
+// its own current environment, call frames and budget
+func (i *Interpreter) fork() *Interpreter {
+	forked := *i
+	forked.environment = nil
+	forked.frames = nil
+	forked.generator = nil
+	forked.coroutine = nil
+
+	return &forked
+}
@@ -0,0 +1,224 @@
+package interpreter
+
+import (
+	"bytes"
+	"golox/token"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Collects the elements of an iterable through the iterator protocol
+const collect = "fun collect(iterable) { var out = []; var it = iterable.iterator(); " +
+	"while (true) { try { out.push(it.next()); } catch (e) { return out; } } } "
+
+func TestInterpreter_Generators(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Next", source: "fun g() { yield 1; yield 2; } var it = g(); [it.next(), it.next()];", expected: "[1, 2]"},
+		{name: "Representation", source: "fun g() { yield; } g();", expected: "<generator g>"},
+		{name: "Lazy body", source: "var ran = false; fun g() { ran = true; yield 1; } var it = g(); ran;", expected: "false"},
+		{name: "Arguments", source: collect + "fun count(n) { var i = 0; while (i < n) { yield i; i++; } } collect(count(3));", expected: "[0, 1, 2]"},
+		{name: "Bare yield", source: collect + "fun g() { yield; } collect(g());", expected: "[null]"},
+		{name: "Infinite generator", source: "fun nat() { var n = 0; while (true) { yield n; n++; } } var it = nat(); it.next(); it.next(); it.next();", expected: "2"},
+		{name: "Return ends the generator", source: collect + "fun g() { yield 1; return; yield 2; } collect(g());", expected: "[1]"},
+		{name: "Independent generators", source: "fun g() { yield 1; yield 2; } var a = g(); var b = g(); a.next(); [a.next(), b.next()];", expected: "[2, 1]"},
+		{name: "Method", source: collect + "class Box { init(xs) { this.xs = xs; } each() { var i = 0; while (i < this.xs.length()) { yield this.xs[i]; i++; } } } collect(Box([1, 2]).each());", expected: "[1, 2]"},
+		{name: "Lambda", source: collect + "var g = fun () { yield \"a\"; }; collect(g());", expected: "[a]"},
+		{name: "Closure over the generator", source: collect + "fun g() { var n = 0; var inc = () => n++; yield inc(); yield inc(); } collect(g());", expected: "[0, 1]"},
+		{name: "Nested generators", source: collect + "fun inner() { yield 1; yield 2; } fun outer() { var it = inner(); yield it.next() * 10; yield it.next() * 10; } collect(outer());", expected: "[10, 20]"},
+		{name: "Yield in a try block", source: collect + "fun g() { try { yield 1; yield 2; } finally { print \"done\"; } } collect(g());", expected: "[1, 2]"},
+		{name: "Yield in a match arm", source: collect + "fun g(x) { match (x) { 1 => { yield \"one\"; } _ => { yield \"other\"; } } } collect(g(1));", expected: "[one]"},
+		{name: "Generator is its own iterator", source: "fun g() { yield 1; } var it = g(); it.iterator() == it;", expected: "true"},
+		{name: "Exhausted generator", source: "fun g() { yield 1; } var it = g(); it.next(); var m; try { it.next(); } catch (e) { m = e.message; } m;", expected: "Iterator is exhausted."},
+		{name: "StopIteration class", source: "fun g() { return; yield; } var e; try { g().next(); } catch (error) { e = error; } e;", expected: "StopIteration instance"},
+		{name: "Close", source: "fun g() { yield 1; yield 2; } var it = g(); it.next(); it.close(); var closed = false; try { it.next(); } catch (e) { closed = true; } closed;", expected: "true"},
+		{name: "Close runs finally", source: "var log = []; fun g() { try { yield 1; yield 2; } finally { log.push(\"finally\"); } } var it = g(); it.next(); it.close(); it.close(); log;", expected: "[finally]"},
+		{name: "Close before the first next", source: "var log = []; fun g() { try { yield 1; } finally { log.push(\"finally\"); } } var it = g(); it.close(); log;", expected: "[]"},
+		{name: "Break runs finally", source: "var log = []; fun g() { try { yield 1; yield 2; } finally { log.push(\"finally\"); } } for (var x in g()) { log.push(x); break; } log;", expected: "[1, finally]"},
+		{name: "Exception in a loop runs finally", source: "var log = []; fun g() { try { yield 1; } finally { log.push(\"finally\"); } } try { for (var x in g()) throw x; } catch (e) { log.push(e); } log;", expected: "[finally, 1]"},
+		{name: "Exception in the body", source: "fun g() { yield 1; throw \"boom\"; } var it = g(); it.next(); var e; try { it.next(); } catch (error) { e = error; } e;", expected: "boom"},
+		{name: "Generator ends after an exception", source: "fun g() { throw \"boom\"; yield; } var it = g(); var m; try { it.next(); } catch (e) {} try { it.next(); } catch (e) { m = e.message; } m;", expected: "Iterator is exhausted."},
+		{name: "List iterator", source: collect + "collect([1, 2, 3]);", expected: "[1, 2, 3]"},
+		{name: "Map iterator", source: collect + "collect({\"a\": 1, \"b\": 2});", expected: "[a, b]"},
+		{name: "String iterator", source: collect + "collect(\"héllo\");", expected: "[h, é, l, l, o]"},
+		{name: "Iterator representation", source: "[[].iterator(), {}.iterator(), \"\".iterator()];", expected: "[<list iterator>, <map iterator>, <string iterator>]"},
+		{
+			name: "Class iterator",
+			source: collect + "class Range { init(n) { this.n = n; } iterator() { return RangeIterator(this.n); } } " +
+				"class RangeIterator { init(n) { this.i = 0; this.n = n; } next() { if (this.i >= this.n) throw StopIteration(); this.i++; return this.i; } } " +
+				"collect(Range(3));",
+			expected: "[1, 2, 3]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_GeneratorErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Runtime error in the body", source: "fun g() {\nyield 1 + \"a\"; }\ng().next();", expectedErr: "[Pos 2:9] Error at '+': Operands must be two numbers or two strings."},
+		{name: "Arguments are checked by the call", source: "fun g(a) { yield a; } g();", expectedErr: "Missing argument for parameter 'a' of g."},
+		{name: "Uncaught exception", source: "fun g() { throw \"boom\"; yield 1; } g().next();", expectedErr: "Uncaught boom"},
+		{name: "Resuming a running generator", source: "var it; fun g() { yield it.next(); } it = g(); it.next();", expectedErr: "Generator is already running."},
+		{name: "Exception in finally while closing", source: "fun g() { try { yield 1; } finally { throw \"cleanup\"; } } var it = g(); it.next(); it.close();", expectedErr: "Uncaught cleanup"},
+		{name: "Yield while closing", source: "fun g() { try { yield 1; } finally { yield 2; } }\nvar it = g(); it.next(); it.close();", expectedErr: "Error at 'yield': Can't yield from a generator being closed."},
+		{name: "Closing a running generator", source: "var it; fun g() { it.close(); yield 1; } it = g(); it.next();", expectedErr: "Generator is already running."},
+		{name: "Exhausted iterator", source: "[].iterator().next();", expectedErr: "Iterator is exhausted."},
+		{name: "Yield outside of a generator", source: "yield 1;", expectedErr: "Can't use 'yield' outside of a generator."},
+		{name: "Unknown method", source: "fun g() { yield 1; } g().send(1);", expectedErr: "Undefined property 'send'."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, New(), tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_GeneratorStackTrace(t *testing.T) {
+	source := "fun g() {\nyield 1;\nthrow Error(\"boom\");\n}\nvar it = g();\nit.next();\nvar stack;\ntry {\nit.next();\n} catch (e) {\nstack = e.stack;\n}\nstack;"
+
+	actual, err := run(t, New(), source)
+	if err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	expected := "[[line 3] in g(), [line 9] in next(), [line 9] in script]"
+	if Stringify(actual) != expected {
+		t.Errorf("Stack trace = %v, want %v", Stringify(actual), expected)
+	}
+}
+
+func TestInterpreter_Iterate(t *testing.T) {
+	i := New()
+	if _, err := run(t, i, "fun g() { yield 1; yield 2; yield 3; }"); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	g, _ := i.Globals().Lookup("g")
+	generator, err := i.Call(g, nil)
+	if err != nil {
+		t.Fatalf("Call() returned an error: %v", err)
+	}
+
+	var elements []Value
+	err = i.Iterate(generator, func(element Value) bool {
+		elements = append(elements, element)
+		return len(elements) < 2
+	})
+	if err != nil {
+		t.Fatalf("Iterate() returned an error: %v", err)
+	}
+
+	if Stringify(NewList(elements)) != "[1, 2]" {
+		t.Errorf("Iterate() visited %v, want [1, 2]", Stringify(NewList(elements)))
+	}
+
+	// Leaving the iteration early closes the generator
+	next := generator.(*Generator).Get(&token.Token{Type: token.IDENTIFIER, Lexeme: "next"})
+	if _, err := i.Call(next, nil); err == nil || !strings.HasSuffix(err.Error(), "Iterator is exhausted.") {
+		t.Errorf("Expected the generator to be closed but got %v", err)
+	}
+
+	if err := i.Iterate(1.0, func(Value) bool { return true }); err == nil {
+		t.Error("Expected an error when iterating a number")
+	}
+}
+
+func TestInterpreter_GeneratorGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	var out bytes.Buffer
+	i := New()
+	i.SetStdout(&out)
+	source := "fun nat() { var n = 0; try { while (true) { yield n; n++; } } finally { print \"finally\"; } } " +
+		"fun start() { var it = nat(); it.next(); it.next(); } " +
+		"var n = 0; while (n < 100) { start(); n++; } " +
+		"var closed = nat(); closed.next(); closed.close();"
+	if _, err := run(t, i, source); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	// The suspended generators are dropped once they are garbage collected
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected %d goroutines after the generators were collected but got %d", before, after)
+	}
+
+	// Only the closed generator runs its finally block
+	if out.String() != "finally\n" {
+		t.Errorf("Output = %q, want %q", out.String(), "finally\n")
+	}
+}
+
+func TestInterpreter_CloseDropsGenerators(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// The generators are reachable from their own body, through the instance
+	// running them, so they are never garbage collected
+	var out bytes.Buffer
+	i := New()
+	i.SetStdout(&out)
+	source := "class Tree { init() { this.it = this.walk(); this.it.next(); } " +
+		"walk() { try { yield 1; yield 2; } finally { print \"finally\"; } } } " +
+		"var n = 0; while (n < 50) { Tree(); n++; } var kept = Tree();"
+	if _, err := run(t, i, source); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	if after := runtime.NumGoroutine(); after < before+51 {
+		t.Fatalf("Expected the 51 suspended generators to have goroutines but got %d more", after-before)
+	}
+
+	i.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected %d goroutines after the interpreter was closed but got %d", before, after)
+	}
+
+	if out.String() != "" {
+		t.Errorf("Expected the dropped generators not to run their finally blocks but got %q", out.String())
+	}
+
+	// A dropped generator is ended
+	if _, err := run(t, i, "kept.it.next();"); err == nil || !strings.HasSuffix(err.Error(), "Iterator is exhausted.") {
+		t.Errorf("Expected the dropped generator to be exhausted but got %v", err)
+	}
+}
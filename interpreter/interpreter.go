@@ -1,26 +1,117 @@
+/*
+Package interpreter implements a tree-walking interpreter for the GoLox language.
+
+The interpreter is a visitor that walks the AST produced by the parser and
+evaluates it. Runtime errors are raised as panics carrying an *error.Error that
+points at the token where the error occurred, and are recovered at the entry
+points of the interpreter.
+*/
 package interpreter
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	loxerr "golox/error"
 	"golox/expr"
+	"golox/stmt"
 	"golox/token"
+	"io"
+	"math"
+	"os"
+	"strconv"
 )
 
 // Interpreter is the visitor that interprets the AST
-type Interpreter struct{}
+type Interpreter struct {
+	builtins    *Environment // Native functions and the prelude, shared by every module
+	globals     *Environment // The globals of the main script
+	environment *Environment // The innermost scope being executed
+	stdout      io.Writer    // Destination of the print statements
+	stderr      io.Writer    // Destination of the warnings of the imported modules
+	limits      Limits
+	budget      budget      // Resources used by the current execution
+	frames      []callFrame // The calls in progress, for the stack traces
+	generator   *generator  // The generator whose body the interpreter runs, if any
+	generators  *generators // The generators whose body is suspended, shared with the copies
+	scheduler   *scheduler  // The tasks of the execution, shared with the copies running them
+	loop        *eventLoop  // The asynchronous work of the execution, shared with the copies
+	coroutine   *coroutine  // The async call whose body the interpreter runs, if any
+
+	errorClasses map[string]*Class // The classes defined by the prelude
 
-// New creates a new Interpreter
+	modules    map[string]*Module // Loaded modules by their absolute path
+	importing  []moduleFile       // Modules being loaded, for the import cycle errors
+	dir        string             // Directory that relative imports are resolved against
+	searchPath []string
+	importRoot string // Directory the imported files must be inside, if not empty
+}
+
+// New creates a new Interpreter that prints to the standard output
 func New() *Interpreter {
-	return &Interpreter{}
+	builtins := NewEnvironment(nil)
+	globals := NewEnvironment(builtins)
+
+	i := &Interpreter{
+		builtins:    builtins,
+		globals:     globals,
+		environment: globals,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+		modules:     make(map[string]*Module),
+		scheduler:   newScheduler(),
+		generators:  &generators{suspended: make(map[*generator]bool)},
+		loop:        newEventLoop(),
+	}
+	i.loadPrelude()
+	i.loadChannels()
+	i.loadEventLoop()
+
+	return i
+}
+
+// SetStdout sets the writer that the print statements write to
+func (i *Interpreter) SetStdout(w io.Writer) {
+	i.stdout = w
+}
+
+// SetStderr sets the writer that the warnings of the imported modules, such as
+// unreachable code, are written to
+func (i *Interpreter) SetStderr(w io.Writer) {
+	i.stderr = w
+}
+
+// Globals returns the global environment of the interpreter
+func (i *Interpreter) Globals() *Environment {
+	return i.globals
+}
+
+// Interpret evaluates the given expression. Runtime errors are returned as an
+// *error.Error instead of being raised
+func (i *Interpreter) Interpret(e expr.Expr) (Value, error) {
+	return i.InterpretContext(context.Background(), e)
+}
+
+// InterpretContext evaluates the given expression, stopping with a LimitError if
+// the context is done before the evaluation finishes
+func (i *Interpreter) InterpretContext(ctx context.Context, e expr.Expr) (result Value, err error) {
+	defer recoverError(&err)
+	defer i.begin(ctx)()
+
+	return i.evaluate(e), nil
 }
 
+// VisitLiteralExpr implements the expr.Visitor interface
 func (i *Interpreter) VisitLiteralExpr(e *expr.Literal) interface{} {
 	return e.Value
 }
 
+// VisitGroupingExpr implements the expr.Visitor interface
 func (i *Interpreter) VisitGroupingExpr(e *expr.Grouping) interface{} {
 	return i.evaluate(e.Expression)
 }
 
+// VisitUnaryExpr implements the expr.Visitor interface
 func (i *Interpreter) VisitUnaryExpr(e *expr.Unary) interface{} {
 	right := i.evaluate(e.Right)
 
@@ -28,34 +119,41 @@ func (i *Interpreter) VisitUnaryExpr(e *expr.Unary) interface{} {
 	case token.BANG:
 		return !isTruthy(right)
 	case token.MINUS:
+		if method, instance := specialMethod(right, "__neg"); method != nil {
+			return i.callMember(method, instance, e.Operator, nil)
+		}
+
 		checkNumberOperand(e.Operator, right)
-		
+
 		return -right.(float64)
+	case token.TILDE:
+		return float64(^checkIntegerOperand(e.Operator, right))
 	}
 
 	// Unreachable
 	return nil
 }
 
+// VisitBinaryExpr implements the expr.Visitor interface
 func (i *Interpreter) VisitBinaryExpr(e *expr.Binary) interface{} {
 	left := i.evaluate(e.Left)
 	right := i.evaluate(e.Right)
 
-	switch e.Operator.Type {
-	case token.GREATER:
-		return left.(float64) > right.(float64)
-	case token.GREATER_EQUAL:
-		return left.(float64) >= right.(float64)
-	case token.LESS:
-		return left.(float64) < right.(float64)
-	case token.LESS_EQUAL:
-		return left.(float64) <= right.(float64)
+	return i.binary(e.Operator, left, right)
+}
+
+// Applies a binary operator to already evaluated operands. Instances can
+// overload the operators with special methods
+func (i *Interpreter) binary(operator *token.Token, left, right Value) Value {
+	if result, ok := i.overload(operator, left, right); ok {
+		return result
+	}
+
+	switch operator.Type {
 	case token.BANG_EQUAL:
 		return !isEqual(left, right)
 	case token.EQUAL_EQUAL:
 		return isEqual(left, right)
-	case token.MINUS:
-		return left.(float64) - right.(float64)
 	case token.PLUS:
 		if l, ok := left.(float64); ok {
 			if r, ok := right.(float64); ok {
@@ -65,23 +163,635 @@ func (i *Interpreter) VisitBinaryExpr(e *expr.Binary) interface{} {
 
 		if l, ok := left.(string); ok {
 			if r, ok := right.(string); ok {
+				i.allocate(len(l) + len(r))
 				return l + r
 			}
 		}
+
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Operands must be two numbers or two strings."))
+	}
+
+	switch operator.Type {
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LESS_LESS, token.GREATER_GREATER:
+		return bitwise(operator, left, right)
+	case token.DOT_DOT, token.DOT_DOT_LESS:
+		return newRange(operator, left, right)
+	}
+
+	checkNumberOperands(operator, left, right)
+
+	return arithmetic(operator, left.(float64), right.(float64))
+}
+
+// Applies an arithmetic or comparison operator to two numbers
+func arithmetic(operator *token.Token, l, r float64) Value {
+	switch operator.Type {
+	case token.GREATER:
+		return l > r
+	case token.GREATER_EQUAL:
+		return l >= r
+	case token.LESS:
+		return l < r
+	case token.LESS_EQUAL:
+		return l <= r
+	case token.MINUS:
+		return l - r
 	case token.SLASH:
-		return left.(float64) / right.(float64)
+		return l / r
 	case token.STAR:
-		return left.(float64) * right.(float64)
+		return l * r
+	case token.PERCENT:
+		return FloorMod(l, r)
+	case token.SLASH_SLASH:
+		return math.Floor(l / r)
+	case token.STAR_STAR:
+		return math.Pow(l, r)
 	}
 
 	// Unreachable
 	return nil
 }
 
+// VisitTernaryExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitTernaryExpr(e *expr.Ternary) interface{} {
+	if isTruthy(i.evaluate(e.Condition)) {
+		return i.evaluate(e.TrueBranch)
+	}
+
+	return i.evaluate(e.FalseBranch)
+}
+
+// VisitLogicalExpr implements the expr.Visitor interface
+//
+// Logical operators short-circuit and return the operand that decided the result
+func (i *Interpreter) VisitLogicalExpr(e *expr.Logical) interface{} {
+	left := i.evaluate(e.Left)
+
+	if e.Operator.Type == token.OR {
+		if isTruthy(left) {
+			return left
+		}
+	} else if !isTruthy(left) {
+		return left
+	}
+
+	return i.evaluate(e.Right)
+}
+
+// VisitVariableExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitVariableExpr(e *expr.Variable) interface{} {
+	return i.environment.Get(e.Name)
+}
+
+// VisitAssignExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitAssignExpr(e *expr.Assign) interface{} {
+	value := i.evaluate(e.Value)
+	i.environment.Assign(e.Name, value)
+
+	return value
+}
+
+// VisitCallExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitCallExpr(e *expr.Call) interface{} {
+	callee := i.evaluate(e.Callee)
+	arguments, named := i.evaluateArguments(e)
+
+	function, ok := callableOf(callee)
+	if !ok {
+		panic(loxerr.NewKind(e.Paren, loxerr.TypeError, "Can only call functions and classes."))
+	}
+
+	defer i.enterCall()()
+
+	i.frames = append(i.frames, callFrame{callee: function, site: e.Paren})
+	result, err := i.invoke(function, arguments, named)
+	i.frames = i.frames[:len(i.frames)-1]
+
+	if err != nil {
+		raise(e.Paren, err)
+	}
+
+	return result
+}
+
+// Evaluates the positional and named arguments of a call
+func (i *Interpreter) evaluateArguments(e *expr.Call) ([]Value, []namedArgument) {
+	arguments := make([]Value, len(e.Arguments))
+	for idx, argument := range e.Arguments {
+		arguments[idx] = i.evaluate(argument)
+	}
+
+	named := make([]namedArgument, len(e.Named))
+	for idx, argument := range e.Named {
+		named[idx] = namedArgument{name: argument.Name, value: i.evaluate(argument.Value)}
+	}
+
+	return arguments, named
+}
+
+// Raises the error returned by a call. Runtime errors, exceptions and limit
+// errors are raised as they are, any other error as a runtime error at the
+// given location, of the kind of a KindError
+func raise(location *token.Token, err error) {
+	var runtimeErr *loxerr.Error
+	if errors.As(err, &runtimeErr) {
+		panic(runtimeErr)
+	}
+	var exception *Exception
+	if errors.As(err, &exception) {
+		panic(exception)
+	}
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		panic(limitErr)
+	}
+	panic(runtimeError(location, err))
+}
+
+// VisitGetExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitGetExpr(e *expr.Get) interface{} {
+	return i.getProperty(i.evaluate(e.Object), e.Name)
+}
+
+// VisitSetExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitSetExpr(e *expr.Set) interface{} {
+	instance := fieldsOf(i.evaluate(e.Object), e.Name)
+
+	value := i.evaluate(e.Value)
+	i.setProperty(instance, e.Name, value)
+
+	return value
+}
+
+// Reads a property of an object. The getter of an instance property takes
+// precedence over its fields and methods
+func (i *Interpreter) getProperty(object Value, name *token.Token) Value {
+	switch o := object.(type) {
+	case *Instance:
+		if getter, _ := o.class.findAccessors(name.Lexeme); getter != nil {
+			return i.callMember(getter, o, name, nil)
+		}
+		return o.Get(name)
+	case *Class:
+		return o.Get(name)
+	case *List:
+		return o.Get(name)
+	case *Map:
+		return o.Get(name)
+	case *Module:
+		return o.Get(name)
+	case *Generator:
+		return o.Get(name)
+	case *Iterator:
+		return o.Get(name)
+	case Range:
+		return o.Get(name)
+	case *Task:
+		return o.Get(name)
+	case *Chan:
+		return o.Get(name)
+	case *Promise:
+		return o.Get(name)
+	case string:
+		if method, ok := stringMethods[name.Lexeme]; ok {
+			return method.bind(o)
+		}
+		panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+	}
+
+	panic(loxerr.NewKind(name, loxerr.TypeError, "Only instances have properties."))
+}
+
+// Returns the instance whose field is being assigned
+func fieldsOf(object Value, name *token.Token) *Instance {
+	instance, ok := object.(*Instance)
+	if !ok {
+		panic(loxerr.NewKind(name, loxerr.TypeError, "Only instances have fields."))
+	}
+
+	return instance
+}
+
+// Assigns a property of an instance, through its setter if it has one. A property
+// with a getter but no setter is read-only
+func (i *Interpreter) setProperty(instance *Instance, name *token.Token, value Value) {
+	getter, setter := instance.class.findAccessors(name.Lexeme)
+
+	switch {
+	case setter != nil:
+		i.callMember(setter, instance, name, []Value{value})
+	case getter != nil:
+		panic(loxerr.New(name, "Can't assign to property '"+name.Lexeme+"' which has a getter but no setter."))
+	default:
+		i.setField(instance, name, value)
+	}
+}
+
+// Runs a getter, a setter or a special method on the instance like a call at
+// the given token
+func (i *Interpreter) callMember(member *Function, instance *Instance, name *token.Token, arguments []Value) Value {
+	defer i.enterCall()()
+
+	i.frames = append(i.frames, callFrame{callee: member, site: name})
+	result, err := member.Bind(instance).Call(i, arguments)
+	i.frames = i.frames[:len(i.frames)-1]
+
+	if err != nil {
+		raise(name, err)
+	}
+
+	return result
+}
+
+func (i *Interpreter) setField(instance *Instance, name *token.Token, value Value) {
+	checkNotFrozen(instance, name)
+
+	i.allocate(fieldSize)
+	instance.Set(name, value)
+}
+
+// VisitThisExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitThisExpr(e *expr.This) interface{} {
+	return i.environment.Get(e.Keyword)
+}
+
+// VisitSuperExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitSuperExpr(e *expr.Super) interface{} {
+	superclass, ok := i.environment.Get(e.Keyword).(*Class)
+	if !ok {
+		panic(loxerr.New(e.Keyword, "Can't use 'super' in a class with no superclass."))
+	}
+
+	this, _ := i.environment.Lookup("this")
+
+	// In a static method "this" is the class, and super looks up static methods
+	if class, ok := this.(*Class); ok {
+		if method, ok := superclass.FindStaticMethod(e.Method.Lexeme); ok {
+			return method.bind(class)
+		}
+		panic(loxerr.NewKind(e.Method, loxerr.NameError, "Undefined property '"+e.Method.Lexeme+"'."))
+	}
+
+	instance := this.(*Instance)
+	if getter, _ := superclass.findAccessors(e.Method.Lexeme); getter != nil {
+		return i.callMember(getter, instance, e.Method, nil)
+	}
+
+	method, ok := superclass.FindMethod(e.Method.Lexeme)
+	if !ok {
+		panic(loxerr.NewKind(e.Method, loxerr.NameError, "Undefined property '"+e.Method.Lexeme+"'."))
+	}
+
+	return method.Bind(instance)
+}
+
+// VisitListExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitListExpr(e *expr.List) interface{} {
+	elements := make([]Value, len(e.Elements))
+	for idx, element := range e.Elements {
+		elements[idx] = i.evaluate(element)
+	}
+
+	i.allocate(listSize + len(elements)*elementSize)
+
+	return NewList(elements)
+}
+
+// VisitMapExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitMapExpr(e *expr.Map) interface{} {
+	m := NewMap()
+	i.allocate(listSize)
+
+	for idx := range e.Keys {
+		key := i.evaluate(e.Keys[idx])
+		value := i.evaluate(e.Values[idx])
+
+		if m.store(i.mapKey(e.Brace, key), key, value, i.keyEqual(e.Brace)) {
+			i.allocate(2 * elementSize)
+		}
+	}
+
+	return m
+}
+
+// VisitIndexExpr implements the expr.Visitor interface
+//
+// Lists and strings can be indexed, negative indices count from the end. Maps
+// are indexed by their keys, and instances through their __index method
+func (i *Interpreter) VisitIndexExpr(e *expr.Index) interface{} {
+	object := i.evaluate(e.Object)
+	index := i.evaluate(e.Index)
+
+	return i.getIndex(object, index, e.Bracket)
+}
+
+// VisitSetIndexExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitSetIndexExpr(e *expr.SetIndex) interface{} {
+	object := i.evaluate(e.Object)
+	index := i.evaluate(e.Index)
+	value := i.evaluate(e.Value)
+
+	i.setIndex(object, index, value, e.Bracket)
+
+	return value
+}
+
+func (i *Interpreter) getIndex(object, index Value, bracket *token.Token) Value {
+	switch o := object.(type) {
+	case *List:
+		idx, err := normalizeIndex(index, len(o.Elements))
+		if err != nil {
+			panic(runtimeError(bracket, err))
+		}
+		return o.Elements[idx]
+	case string:
+		runes := []rune(o)
+		idx, err := normalizeIndex(index, len(runes))
+		if err != nil {
+			panic(runtimeError(bracket, err))
+		}
+		return string(runes[idx])
+	case *Map:
+		value, ok := o.lookup(i.mapKey(bracket, index), index, i.keyEqual(bracket))
+		if !ok {
+			panic(loxerr.NewKind(bracket, loxerr.IndexError, "Undefined key '"+Stringify(index)+"'."))
+		}
+		return value
+	case *Instance:
+		if method, _ := specialMethod(o, "__index"); method != nil {
+			return i.callMember(method, o, bracket, []Value{index})
+		}
+	}
+
+	panic(loxerr.NewKind(bracket, loxerr.TypeError, "Only lists, strings and maps can be indexed."))
+}
+
+func (i *Interpreter) setIndex(object, index, value Value, bracket *token.Token) {
+	checkNotFrozen(object, bracket)
+
+	switch o := object.(type) {
+	case *List:
+		idx, err := normalizeIndex(index, len(o.Elements))
+		if err != nil {
+			panic(runtimeError(bracket, err))
+		}
+		o.Elements[idx] = value
+	case *Map:
+		if o.store(i.mapKey(bracket, index), index, value, i.keyEqual(bracket)) {
+			i.allocate(2 * elementSize)
+		}
+	case *Instance:
+		method, _ := specialMethod(o, "__setindex")
+		if method == nil {
+			panic(loxerr.NewKind(bracket, loxerr.TypeError, "Only lists and maps support index assignment."))
+		}
+		i.callMember(method, o, bracket, []Value{index, value})
+	default:
+		panic(loxerr.NewKind(bracket, loxerr.TypeError, "Only lists and maps support index assignment."))
+	}
+}
+
+// VisitSliceExpr implements the expr.Visitor interface
+//
+// Slicing creates a new list or string, the bounds are clamped to the length
+func (i *Interpreter) VisitSliceExpr(e *expr.Slice) interface{} {
+	object := i.evaluate(e.Object)
+
+	var start, end Value
+	if e.Start != nil {
+		start = i.evaluate(e.Start)
+	}
+	if e.End != nil {
+		end = i.evaluate(e.End)
+	}
+
+	switch o := object.(type) {
+	case *List:
+		from, to := i.sliceBounds(e.Bracket, start, end, len(o.Elements))
+		i.allocate(listSize + (to-from)*elementSize)
+
+		elements := make([]Value, to-from)
+		copy(elements, o.Elements[from:to])
+
+		return NewList(elements)
+	case string:
+		runes := []rune(o)
+		from, to := i.sliceBounds(e.Bracket, start, end, len(runes))
+		i.allocate(to - from)
+
+		return string(runes[from:to])
+	}
+
+	panic(loxerr.NewKind(e.Bracket, loxerr.TypeError, "Only lists and strings can be sliced."))
+}
+
+func (i *Interpreter) sliceBounds(bracket *token.Token, start, end Value, length int) (from, to int) {
+	from, to, err := sliceBounds(start, end, length)
+	if err != nil {
+		panic(runtimeError(bracket, err))
+	}
+
+	return from, to
+}
+
+func (i *Interpreter) mapKey(location *token.Token, key Value) hashKey {
+	hash, err := i.hash(key)
+	if err != nil {
+		raise(location, err)
+	}
+
+	return hash
+}
+
+// VisitCompoundAssignExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitCompoundAssignExpr(e *expr.CompoundAssign) interface{} {
+	operator := &token.Token{Type: compoundOperators[e.Operator.Type], Lexeme: e.Operator.Lexeme, Line: e.Operator.Line, Column: e.Operator.Column}
+
+	_, updated := i.update(e.Target, func(current Value) Value {
+		return i.binary(operator, current, i.evaluate(e.Value))
+	})
+
+	return updated
+}
+
+// VisitUpdateExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitUpdateExpr(e *expr.Update) interface{} {
+	delta := 1.0
+	if e.Operator.Type == token.MINUS_MINUS {
+		delta = -1
+	}
+
+	old, updated := i.update(e.Target, func(current Value) Value {
+		checkNumberOperand(e.Operator, current)
+		return current.(float64) + delta
+	})
+
+	if e.Prefix {
+		return updated
+	}
+
+	return old
+}
+
+// The binary operators applied by the compound assignment operators
+var compoundOperators = map[token.Type]token.Type{
+	token.PLUS_EQUAL:    token.PLUS,
+	token.MINUS_EQUAL:   token.MINUS,
+	token.STAR_EQUAL:    token.STAR,
+	token.SLASH_EQUAL:   token.SLASH,
+	token.PERCENT_EQUAL: token.PERCENT,
+}
+
+// Reads the current value of an assignment target, computes the new value from
+// it and stores the result. The object and the index of the target are evaluated
+// only once. Returns both the old and the new value
+func (i *Interpreter) update(target expr.Expr, compute func(current Value) Value) (old, updated Value) {
+	switch t := target.(type) {
+	case *expr.Variable:
+		old = i.environment.Get(t.Name)
+		updated = compute(old)
+		i.environment.Assign(t.Name, updated)
+	case *expr.Get:
+		instance := fieldsOf(i.evaluate(t.Object), t.Name)
+		old = i.getProperty(instance, t.Name)
+		updated = compute(old)
+		i.setProperty(instance, t.Name, updated)
+	case *expr.Index:
+		object := i.evaluate(t.Object)
+		index := i.evaluate(t.Index)
+		old = i.getIndex(object, index, t.Bracket)
+		updated = compute(old)
+		i.setIndex(object, index, updated, t.Bracket)
+	}
+
+	return old, updated
+}
+
+// VisitLambdaExpr implements the expr.Visitor interface
+//
+// Lambdas create closures just like function declarations, under the name
+// "anonymous"
+func (i *Interpreter) VisitLambdaExpr(e *expr.Lambda) interface{} {
+	declaration := &stmt.Function{
+		Name:      &token.Token{Type: token.IDENTIFIER, Lexeme: "anonymous", Line: e.Keyword.Line, Column: e.Keyword.Column},
+		Params:    e.Params,
+		Body:      stmt.Statements(e.Body),
+		Generator: e.Generator,
+		Async:     e.Async,
+	}
+
+	i.allocate(functionSize)
+
+	return NewFunction(declaration, i.environment, false)
+}
+
+// Call calls a Lox callable from Go. Runtime errors are returned as an
+// *error.Error instead of being raised
+func (i *Interpreter) Call(callee Value, arguments []Value) (Value, error) {
+	return i.CallContext(context.Background(), callee, arguments)
+}
+
+// CallContext calls a Lox callable from Go, stopping with a LimitError if the
+// context is done before the call returns
+func (i *Interpreter) CallContext(ctx context.Context, callee Value, arguments []Value) (result Value, err error) {
+	function, ok := callableOf(callee)
+	if !ok {
+		return nil, fmt.Errorf("can only call functions and classes, got %s", TypeName(callee))
+	}
+
+	defer recoverError(&err)
+	defer i.begin(ctx)()
+	defer i.enterCall()()
+
+	return i.invoke(function, arguments, nil)
+}
+
+// Calls a Lox callable from a native function, for example the callback given
+// to a list method
+func (i *Interpreter) callValue(callee Value, arguments []Value) (Value, error) {
+	function, ok := callableOf(callee)
+	if !ok {
+		return nil, loxerr.Errorf(loxerr.TypeError, "expected a function but got %s", TypeName(callee))
+	}
+
+	defer i.enterCall()()
+
+	return i.invoke(function, arguments, nil)
+}
+
+// Invokes a callable with the given arguments. Callables declared in Lox bind
+// the arguments themselves, any other callable has its arity checked first and
+// can't take named arguments
+func (i *Interpreter) invoke(function Callable, arguments []Value, named []namedArgument) (Value, error) {
+	if callable, ok := function.(parameterCallable); ok {
+		return callable.callNamed(i, arguments, named)
+	}
+
+	if len(named) > 0 {
+		return nil, kindError(loxerr.TypeError, "Only functions and classes declared in Lox accept named arguments.")
+	}
+
+	if err := checkArity(function, len(arguments)); err != nil {
+		return nil, err
+	}
+
+	return function.Call(i, arguments)
+}
+
 func (i *Interpreter) evaluate(e expr.Expr) interface{} {
+	i.tick()
 	return e.Accept(i)
 }
 
+// Recovers the errors raised during an execution and stores them in err. A
+// return statement executed outside of a function, which the resolver rejects,
+// is reported as an error too. Any other panic is a bug in the interpreter and
+// is raised again
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		switch e := r.(type) {
+		case *loxerr.Error:
+			*err = e
+		case *returnValue:
+			*err = loxerr.New(e.keyword, "Can't return from top-level code.")
+		case *Exception:
+			*err = e
+		case *LimitError:
+			*err = e
+		default:
+			panic(r)
+		}
+	}
+}
+
+// Stringify returns the string representation of a Lox value
+func Stringify(value Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case interface{ String() string }:
+		return v.String()
+	}
+
+	return "<" + TypeName(value) + ">"
+}
+
+// Returns the error for calling the function with the wrong number of
+// arguments, or nil if the count is fine
+func checkArity(function Callable, count int) error {
+	if arity := function.Arity(); arity != Variadic && arity != count {
+		return kindError(loxerr.TypeError, "Expected "+strconv.Itoa(arity)+" arguments but got "+strconv.Itoa(count)+".")
+	}
+
+	return nil
+}
+
 // We follow simple rule to determine truthiness:
 // - nil and false are false
 // - everything else is true
@@ -108,8 +818,83 @@ func isEqual(a, b interface{}) bool {
 	return a == b
 }
 
+// Applies a bitwise operator. Both operands must be integers
+func bitwise(operator *token.Token, left, right Value) Value {
+	l, r := checkIntegerOperands(operator, left, right)
+
+	switch operator.Type {
+	case token.AMPERSAND:
+		return float64(l & r)
+	case token.PIPE:
+		return float64(l | r)
+	case token.CARET:
+		return float64(l ^ r)
+	}
+
+	if r < 0 {
+		panic(loxerr.New(operator, "Shift count must not be negative."))
+	}
+
+	if operator.Type == token.LESS_LESS {
+		return float64(l << r)
+	}
+
+	return float64(l >> r)
+}
+
+// FloorMod returns the remainder of the floored division, which has the sign of
+// the divisor. It is the result of the % operator
+func FloorMod(l, r float64) float64 {
+	remainder := math.Mod(l, r)
+	if remainder != 0 && (remainder < 0) != (r < 0) {
+		remainder += r
+	}
+
+	return remainder
+}
+
 func checkNumberOperand(operator *token.Token, operand interface{}) {
 	if _, ok := operand.(float64); !ok {
-		panic("Invalid operation: operator '" + operator.Lexeme + "' not defined on '" + operand.(string) + "'")
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Operand must be a number."))
+	}
+}
+
+// Numbers are floats at runtime, the bitwise operators work on the ones that
+// hold an integer in the range of int64
+func toInteger(value Value) (int64, bool) {
+	n, ok := value.(float64)
+	if !ok || n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, false
+	}
+
+	return int64(n), true
+}
+
+func checkIntegerOperand(operator *token.Token, operand interface{}) int64 {
+	n, ok := toInteger(operand)
+	if !ok {
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Operand must be an integer."))
+	}
+
+	return n
+}
+
+func checkIntegerOperands(operator *token.Token, left, right interface{}) (int64, int64) {
+	l, lok := toInteger(left)
+	r, rok := toInteger(right)
+
+	if !lok || !rok {
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Operands must be integers."))
+	}
+
+	return l, r
+}
+
+func checkNumberOperands(operator *token.Token, left, right interface{}) {
+	_, lok := left.(float64)
+	_, rok := right.(float64)
+
+	if !lok || !rok {
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Operands must be numbers."))
 	}
 }
@@ -0,0 +1,604 @@
+package interpreter
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInterpreter_Operators(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Modulo", source: "[7 % 3, -7 % 3, 7 % -3, 5.5 % 2];", expected: "[1, 2, -2, 1.5]"},
+		{name: "Floor division", source: "[7 // 2, -7 // 2, 7.5 // 2];", expected: "[3, -4, 3]"},
+		{name: "Comments after statements", source: "var a = 5; // half\n// own line\nvar c = a // 2 + 1; if (c > 1) /* big */ c = c // 2; c;", expected: "1"},
+		{name: "Power", source: "[2 ** 10, 2 ** -1, -2 ** 2, 2 ** 3 ** 2];", expected: "[1024, 0.5, -4, 512]"},
+		{name: "Bitwise and, or, xor", source: "[12 & 10, 12 | 10, 12 ^ 10];", expected: "[8, 14, 6]"},
+		{name: "Bitwise not", source: "[~0, ~5, ~-1];", expected: "[-1, -6, 0]"},
+		{name: "Shifts", source: "[1 << 10, 1024 >> 3, -16 >> 2];", expected: "[1024, 128, -4]"},
+		{name: "Precedence", source: "[1 + 2 * 3 % 4, 1 | 2 == 3, 2 * 3 ** 2, 1 << 2 + 1];", expected: "[3, true, 18, 8]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_OperatorErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Non-integral bitwise operand", source: "1.5 & 1;", expectedErr: "Error at '&': Operands must be integers."},
+		{name: "Non-number bitwise operand", source: "\"a\" | 1;", expectedErr: "Error at '|': Operands must be integers."},
+		{name: "Non-integral bitwise not", source: "~0.5;", expectedErr: "Error at '~': Operand must be an integer."},
+		{name: "Negative shift count", source: "1 << -1;", expectedErr: "Error at '<<': Shift count must not be negative."},
+		{name: "Non-number power", source: "\"a\" ** 2;", expectedErr: "Error at '**': Operands must be numbers."},
+		{name: "Non-number modulo", source: "null % 2;", expectedErr: "Error at '%': Operands must be numbers."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, New(), tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Lambdas(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Lambda expression", source: "var add = fun (a, b) { return a + b; }; add(1, 2);", expected: "3"},
+		{name: "Arrow function", source: "[1, 2, 3].map((x) => x * 2);", expected: "[2, 4, 6]"},
+		{name: "Arrow function without parameters", source: "var f = () => 42; f();", expected: "42"},
+		{name: "Arrow function with a block body", source: "[1, 2, 3, 4].filter((x) => { return x % 2 == 0; });", expected: "[2, 4]"},
+		{name: "Immediately invoked", source: "fun (x) { print x; }(1); ((x) => x + 1)(1);", expected: "2"},
+		{
+			name:     "Closures",
+			source:   "fun counter() { var n = 0; return () => ++n; } var c = counter(); c(); c(); c();",
+			expected: "3",
+		},
+		{name: "Arrow returning a lambda", source: "var adder = (a) => (b) => a + b; adder(1)(2);", expected: "3"},
+		{name: "String representation", source: "fun (a) {};", expected: "<fn anonymous>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_CompoundAssignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Variables", source: "var x = 10; x += 5; x -= 3; x *= 2; x /= 4; x;", expected: "6"},
+		{name: "Modulo", source: "var a = 7; var b = -7; a %= 3; b %= 3; [a, b];", expected: "[1, 2]"},
+		{name: "String concatenation", source: "var s = \"a\"; s += \"b\"; s;", expected: "ab"},
+		{name: "Value of the assignment", source: "var x = 1; var y = x += 2; [x, y];", expected: "[3, 3]"},
+		{name: "Fields", source: "class P {} var p = P(); p.x = 1; p.x += 2; p.x;", expected: "3"},
+		{name: "List elements", source: "var xs = [1, 2]; xs[-1] *= 10; xs;", expected: "[1, 20]"},
+		{name: "Map entries", source: "var m = {\"a\": 1}; m[\"a\"] -= 1; m;", expected: "{a: 0}"},
+		{name: "Prefix increment", source: "var x = 1; [++x, x];", expected: "[2, 2]"},
+		{name: "Postfix increment", source: "var x = 1; [x++, x];", expected: "[1, 2]"},
+		{name: "Prefix and postfix decrement", source: "var x = 5; [--x, x--, x];", expected: "[4, 4, 3]"},
+		{name: "Increment in a for loop", source: "var s = 0; for (var i = 0; i < 4; i++) s += i; s;", expected: "6"},
+		{name: "Field and index updates", source: "class P {} var p = P(); p.n = 0; p.n++; var xs = [0]; --xs[0]; [p.n, xs];", expected: "[1, [-1]]"},
+		{
+			name:     "Target object is evaluated once",
+			source:   "var calls = 0; var xs = [0, 0]; fun f() { calls++; return xs; } f()[calls] += 5; f()[0]++; [calls, xs];",
+			expected: "[2, [1, 5]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_CompoundAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Incompatible operands", source: "var x = 1;\nx += \"a\";", expectedErr: "[Pos 2:3] Error at '+=': Operands must be two numbers or two strings."},
+		{name: "Non-number operand", source: "var x = \"a\"; x -= 1;", expectedErr: "Error at '-=': Operands must be numbers."},
+		{name: "Increment of a non-number", source: "var x = null; x++;", expectedErr: "Error at '++': Operand must be a number."},
+		{name: "Undefined variable", source: "y += 1;", expectedErr: "Undefined variable 'y'."},
+		{name: "Field of a non-instance", source: "var xs = []; xs.length += 1;", expectedErr: "Only instances have fields."},
+		{name: "Undefined field", source: "class P {} P().x++;", expectedErr: "Undefined property 'x'."},
+		{name: "Missing map key", source: "var m = {}; m[\"a\"] += 1;", expectedErr: "Undefined key 'a'."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Parameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Default values", source: "fun f(a, b = 2) { return [a, b]; } [f(1), f(1, 3)];", expected: "[[1, 2], [1, 3]]"},
+		{name: "Default referring to an earlier parameter", source: "fun f(a, b = a * 2) { return b; } f(5);", expected: "10"},
+		{name: "Default evaluated on each call", source: "fun f(xs = []) { xs.push(1); return xs; } f(); f();", expected: "[1]"},
+		{name: "Rest parameter", source: "fun f(a, ...rest) { return [a, rest]; } [f(1), f(1, 2, 3)];", expected: "[[1, []], [1, [2, 3]]]"},
+		{name: "Rest after defaults", source: "fun f(a = 0, ...rest) { return [a, rest]; } [f(), f(1, 2)];", expected: "[[0, []], [1, [2]]]"},
+		{name: "Named arguments", source: "fun f(a, b, c) { return [a, b, c]; } f(c: 3, a: 1, b: 2);", expected: "[1, 2, 3]"},
+		{name: "Named argument skipping a default", source: "fun f(a, b = 2, c = 3) { return [a, b, c]; } f(1, c: 4);", expected: "[1, 2, 4]"},
+		{name: "Lambda parameters", source: "var f = (a, b = 10) => a + b; f(b: 1, a: 2);", expected: "3"},
+		{name: "Initializer parameters", source: "class P { init(x, y = 0) { this.x = x; this.y = y; } } var p = P(y: 2, x: 1); [p.x, p.y];", expected: "[1, 2]"},
+		{name: "Method parameters", source: "class P { f(...xs) { return xs.length(); } } P().f(1, 2, 3);", expected: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ParameterErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Too many arguments", source: "fun f(a) {} f(1, 2);", expectedErr: "Expected 1 arguments but got 2."},
+		{name: "Too many arguments with defaults", source: "fun f(a, b = 1) {} f(1, 2, 3);", expectedErr: "Expected at most 2 arguments but got 3."},
+		{name: "Missing argument", source: "fun f(a, b) {} f(b: 1);", expectedErr: "Missing argument for parameter 'a' of f."},
+		{name: "Missing argument with defaults", source: "fun f(a, b = 1) {} f();", expectedErr: "Missing argument for parameter 'a' of f."},
+		{name: "Unexpected named argument", source: "fun f(a) {} f(1, c: 2);", expectedErr: "Unexpected named argument 'c' for f."},
+		{name: "Duplicate argument", source: "fun f(a) {} f(1, a: 2);", expectedErr: "Got multiple values for parameter 'a' of f."},
+		{name: "Rest parameter by name", source: "fun f(...xs) {} f(xs: 1);", expectedErr: "Can't pass the rest parameter 'xs' of f by name."},
+		{name: "Named argument to a class without init", source: "class P {} P(x: 1);", expectedErr: "Unexpected named argument 'x' for P."},
+		{name: "Named argument to a native", source: "[].push(x: 1);", expectedErr: "Only functions and classes declared in Lox accept named arguments."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ClassMembers(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Static method", source: "class M { class square(x) { return x * x; } } M.square(3);", expected: "9"},
+		{
+			name:     "Static method inherited with this bound to the subclass",
+			source:   "class A { class create() { return this(); } } class B < A {} B.create();",
+			expected: "B instance",
+		},
+		{
+			name:     "Static method calling super",
+			source:   "class A { class name() { return \"A\"; } } class B < A { class name() { return \"B\" + super.name(); } } B.name();",
+			expected: "BA",
+		},
+		{name: "Getter", source: "class C { init(r) { this.r = r; } area { return this.r * 2; } } C(2).area;", expected: "4"},
+		{
+			name:     "Setter",
+			source:   "class T { init() { this._c = 0; } celsius { return this._c; } set celsius(v) { this._c = v; } } var t = T(); t.celsius = 5; t.celsius;",
+			expected: "5",
+		},
+		{
+			name:     "Setter returns the assigned value",
+			source:   "class T { set x(v) { this._x = v * 2; return 0; } } var t = T(); [t.x = 3, t._x];",
+			expected: "[3, 6]",
+		},
+		{
+			name:     "Compound assignment through accessors",
+			source:   "class T { init() { this._c = 1; } c { return this._c; } set c(v) { this._c = v; } } var t = T(); t.c += 2; t.c++; t.c;",
+			expected: "4",
+		},
+		{
+			name:     "Inherited getter and setter",
+			source:   "class A { x { return this._x; } set x(v) { this._x = v; } } class B < A {} var b = B(); b.x = 1; b.x;",
+			expected: "1",
+		},
+		{
+			name:     "Getter calling super",
+			source:   "class A { name { return \"a\"; } } class B < A { name { return \"b\" + super.name; } } B().name;",
+			expected: "ba",
+		},
+		{name: "Getter takes precedence over fields", source: "class A { x { return 1; } } var a = A(); a.x;", expected: "1"},
+		{name: "Method called set", source: "class S { set(x) { return x; } } S().set(1);", expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ClassMemberErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Undefined static method", source: "class M {} M.f();", expectedErr: "Undefined property 'f'."},
+		{name: "Static method on an instance", source: "class M { class f() {} } M().f();", expectedErr: "Undefined property 'f'."},
+		{name: "Instance method on the class", source: "class M { f() {} } M.f();", expectedErr: "Undefined property 'f'."},
+		{
+			name:        "Read-only property",
+			source:      "class C { area { return 1; } } C().area = 2;",
+			expectedErr: "Can't assign to property 'area' which has a getter but no setter.",
+		},
+		{name: "Error in a getter", source: "class C { x { return -\"a\"; } } C().x;", expectedErr: "Operand must be a number."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Traits(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Mixed in method", source: "trait Greet { hi() { return \"hi \" + this.name; } } class P with Greet { init(n) { this.name = n; } } P(\"bob\").hi();", expected: "hi bob"},
+		{
+			name:     "Members of several traits",
+			source:   "trait A { a() { return 1; } } trait B { b { return 2; } class c() { return 3; } } class C with A, B {} [C().a(), C().b, C.c()];",
+			expected: "[1, 2, 3]",
+		},
+		{
+			name:     "Class members override trait members",
+			source:   "trait T { m() { return \"trait\"; } } class C with T { m() { return \"class\"; } } C().m();",
+			expected: "class",
+		},
+		{
+			name:     "Trait members override superclass members",
+			source:   "class A { m() { return \"super\"; } } trait T { m() { return \"trait\"; } } class B < A with T {} B().m();",
+			expected: "trait",
+		},
+		{
+			name:     "Super skips trait members",
+			source:   "class A { m() { return \"a\"; } } trait T { m() { return \"t\"; } } class B < A with T { n() { return super.m(); } } B().n();",
+			expected: "a",
+		},
+		{
+			name:     "Conflict resolved by the class",
+			source:   "trait A { m() { return 1; } } trait B { m() { return 2; } } class C with A, B { m() { return 3; } } C().m();",
+			expected: "3",
+		},
+		{name: "Trait initializer", source: "trait T { init() { this.x = 1; } } class C with T {} C().x;", expected: "1"},
+		{name: "Same trait twice", source: "trait T { m() { return 1; } } class C with T, T {} C().m();", expected: "1"},
+		{name: "Trait value", source: "trait T {} T;", expected: "<trait T>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_TraitErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Mixing in a class", source: "class A {} class B with A {}", expectedErr: "Error at 'A': Can only mix in traits."},
+		{
+			name:        "Conflicting members",
+			source:      "trait A { m() {} } trait B { m { return 1; } } class C with A, B {}",
+			expectedErr: "Error at 'C': Traits A and B both define 'm', declare it in C to resolve the conflict.",
+		},
+		{name: "Calling a trait", source: "trait T {} T();", expectedErr: "Can only call functions and classes."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_OperatorOverloading(t *testing.T) {
+	const money = "class M { init(c) { this.c = c; } __add(o) { return M(this.c + o.c); } __lt(o) { return this.c < o.c; } __eq(o) { return this.c == o.c; } } "
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Addition", source: money + "(M(1) + M(2)).c;", expected: "3"},
+		{name: "Compound assignment", source: money + "var m = M(1); m += M(4); m.c;", expected: "5"},
+		{name: "Less", source: money + "[M(1) < M(2), M(2) < M(1)];", expected: "[true, false]"},
+		{name: "Comparisons derived from __lt", source: money + "[M(2) > M(1), M(1) <= M(1), M(1) >= M(2)];", expected: "[true, true, false]"},
+		{name: "Comparisons derived from __lt of the left operand", source: "class V { init(v) { this.v = v; } __lt(o) { return this.v < o; } __eq(o) { return this.v == o; } } [V(1) > 0, V(1) > 1, V(1) <= 1, V(1) <= 0, V(0) <= 1];", expected: "[true, false, true, false, true]"},
+		{name: "Equality", source: money + "[M(1) == M(1), M(1) != M(1), M(1) == M(2)];", expected: "[true, false, false]"},
+		{name: "Equality with the instance on the right", source: "class A { __eq(o) { return o == 1; } } 1 == A();", expected: "true"},
+		{name: "Equality without __eq is identity", source: "class A {} var a = A(); [a == a, a == A()];", expected: "[true, false]"},
+		{name: "Negation", source: "class N { __neg() { return \"neg\"; } } -N();", expected: "neg"},
+		{
+			name:     "Index",
+			source:   "class G { init() { this.cells = {}; } __index(k) { return this.cells[k]; } __setindex(k, v) { this.cells[k] = v * 2; } } var g = G(); g[\"a\"] = 2; g[\"a\"] += 1; g[\"a\"];",
+			expected: "10",
+		},
+		{name: "Call", source: "class Adder { init(n) { this.n = n; } __call(x) { return x + this.n; } } var add = Adder(2); add(3);", expected: "5"},
+		{name: "Callable instance as a callback", source: "class Double { __call(x) { return x * 2; } } [1, 2].map(Double());", expected: "[2, 4]"},
+		{name: "Inherited special method", source: money + "class N < M {} (N(1) + N(1)).c;", expected: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Str(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Print", source: "class P { __str() { return \"a point\"; } } print P();", expected: "a point\n"},
+		{name: "Inside collections", source: "class P { __str() { return \"p\"; } } print [P(), {\"k\": P()}];", expected: "[p, {k: p}]\n"},
+		{name: "Without __str", source: "class P {} print P();", expected: "P instance\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i := New()
+			i.SetStdout(&out)
+
+			if _, err := run(t, i, tt.source); err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("Expected output %q but got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestInterpreter_OperatorOverloadingErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Missing method", source: "class A {} A() + 1;", expectedErr: "Operands must be two numbers or two strings."},
+		{name: "Only the left operand overloads arithmetic", source: "class A { __add(o) { return 1; } } 1 + A();", expectedErr: "Operands must be two numbers or two strings."},
+		{name: "Index without __index", source: "class A {} A()[0];", expectedErr: "Only lists, strings and maps can be indexed."},
+		{name: "Index assignment without __setindex", source: "class A { __index(k) { return k; } } A()[0] = 1;", expectedErr: "Only lists and maps support index assignment."},
+		{name: "Call without __call", source: "class A {} A()();", expectedErr: "Can only call functions and classes."},
+		{name: "__str returning a number", source: "class A { __str() { return 1; } } print A();", expectedErr: "Error at '__str': __str must return a string."},
+		{name: "Error in a special method", source: "class A { __add(o) { return -\"a\"; } } A() + 1;", expectedErr: "Operand must be a number."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Match(t *testing.T) {
+	const point = "class Point { init(x, y) { this.x = x; this.y = y; } } "
+	const describe = "fun describe(v) { return match (v) { 1 | 2 => \"small\", \"s\" => \"string\", [a, b] => a + b, " +
+		"Point{x: 0, y} => -y, Point{x, y} => x * y, _ => \"other\" }; } "
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Literal alternatives", source: point + describe + "describe(2);", expected: "small"},
+		{name: "String literal", source: point + describe + "describe(\"s\");", expected: "string"},
+		{name: "List", source: point + describe + "describe([3, 4]);", expected: "7"},
+		{name: "List of another length", source: point + describe + "describe([3, 4, 5]);", expected: "other"},
+		{name: "Instance with a literal field", source: point + describe + "describe(Point(0, 5));", expected: "-5"},
+		{name: "Instance fields", source: point + describe + "describe(Point(2, 5));", expected: "10"},
+		{name: "Wildcard", source: point + describe + "describe(true);", expected: "other"},
+		{name: "Binding", source: "match (3) { n => n * 2 };", expected: "6"},
+		{name: "Guards", source: "fun sign(n) { return match (n) { 0 => \"zero\", x if x < 0 => \"negative\", _ => \"positive\" }; } [sign(-2), sign(0), sign(5)];", expected: "[negative, zero, positive]"},
+		{name: "Parenthesized guard", source: "match (1) { n if (n > 0) => \"positive\", _ => \"other\" };", expected: "positive"},
+		{name: "Literal compared with __eq", source: "class V { init(v) { this.v = v; } __eq(o) { return this.v == o; } } match (V(2)) { 1 => \"one\", 2 => \"two\", _ => \"other\" };", expected: "two"},
+		{name: "Negative literal", source: "match (-1) { -1 => \"minus one\", _ => \"other\" };", expected: "minus one"},
+		{name: "Null, true and false", source: "[match (null) { null => 1, _ => 2 }, match (false) { true => 1, false => 2 }];", expected: "[1, 2]"},
+		{name: "Rest of a list", source: "match ([1, 2, 3]) { [first, ...others] => [first, others] };", expected: "[1, [2, 3]]"},
+		{name: "Nested patterns", source: "match ([[1, 2], 3]) { [[a, _], b] => a + b };", expected: "4"},
+		{name: "Subclass instance", source: point + "class Point3 < Point {} match (Point3(1, 2)) { Point{x} => x };", expected: "1"},
+		{name: "Getter in an instance pattern", source: "class C { size { return 3; } } match (C()) { C{size} => size };", expected: "3"},
+		{name: "Missing field does not match", source: point + "match (Point(1, 2)) { Point{z} => z, _ => \"none\" };", expected: "none"},
+		{name: "Object pattern on a map", source: "match ({\"x\": 1}) { {y} => y, {x} => x };", expected: "1"},
+		{name: "Block arm", source: "var out; match (1) { 1 => { out = \"one\"; } _ => { out = \"other\"; } } out;", expected: "one"},
+		{name: "Return from a block arm", source: "fun f(v) { match (v) { 1 => { return \"one\"; } } return \"after\"; } f(1);", expected: "one"},
+		{name: "Bindings do not leak", source: "var a = \"outer\"; match ([1]) { [a] => a }; a;", expected: "outer"},
+		{name: "Subject is evaluated once", source: "var n = 0; fun next() { n += 1; return n; } match (next()) { 5 => 0, _ => n };", expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_MatchGetterBeforeField(t *testing.T) {
+	i := New()
+	if _, err := run(t, i, "class P { x { return \"getter\"; } } var p = P();"); err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	// Lox can't define a field hidden by a getter, an embedding application can
+	p, _ := i.Globals().Lookup("p")
+	p.(*Instance).fields["x"] = "field"
+
+	actual, err := run(t, i, "[p.x, match (p) { P{x} => x }, match (p) { {x} => x }];")
+	if err != nil {
+		t.Fatalf("Execute() returned an error: %v", err)
+	}
+
+	if Stringify(actual) != "[getter, getter, getter]" {
+		t.Errorf("Execute() = %v, want [getter, getter, getter]", Stringify(actual))
+	}
+}
+
+func TestInterpreter_MatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "No matching arm", source: "match (3) { 1 => 1, 2 => 2 };", expectedErr: "Error at 'match': No match arm matches the value 3."},
+		{name: "Guard rejecting every arm", source: "match (3) { n if n > 5 => n };", expectedErr: "No match arm matches the value 3."},
+		{name: "Instance pattern of a non-class", source: "var P = 1; match (1) { P{x} => x };", expectedErr: "Error at 'P': Only classes can be matched by instance patterns."},
+		{name: "Error in a guard", source: "match (1) { n if -\"a\" => n };", expectedErr: "Operand must be a number."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package interpreter
+
+import (
+	"context"
+	loxerr "golox/error"
+	"golox/token"
+)
+
+// Error of the built-in iterators and generators once they have no more elements
+var errExhausted = kindError(loxerr.StopIteration, "Iterator is exhausted.")
+
+// Iterator is a built-in iterator, such as the ones returned by the iterator
+// method of lists, maps and strings
+type Iterator struct {
+	kind string               // The type of the value iterated over, for the representation
+	next func() (Value, bool) // Returns the next element, or false once there are no more
+}
+
+// Get returns the built-in method of the iterator with the given name
+func (it *Iterator) Get(name *token.Token) Value {
+	if method, ok := iteratorMethods[name.Lexeme]; ok {
+		return method.bind(it)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the iterator
+func (it *Iterator) String() string {
+	return "<" + it.kind + " iterator>"
+}
+
+// Built-in methods of iterators, receiving the iterator they were called on
+var iteratorMethods = map[string]*method{
+	"next":     {name: "next", arity: 0, fn: iteratorNext},
+	"iterator": {name: "iterator", arity: 0, fn: iteratorSelf},
+}
+
+func iteratorNext(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	value, ok := receiver.(*Iterator).next()
+	if !ok {
+		return nil, errExhausted
+	}
+
+	return value, nil
+}
+
+// An iterator is iterable itself
+func iteratorSelf(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	return receiver, nil
+}
+
+// Returns an iterator over the elements of a list. Elements added to the list
+// while iterating are included
+func listIterator(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	list := receiver.(*List)
+	idx := 0
+
+	i.allocate(functionSize)
+
+	return &Iterator{kind: "list", next: func() (Value, bool) {
+		if idx >= len(list.Elements) {
+			return nil, false
+		}
+		idx++
+		return list.Elements[idx-1], true
+	}}, nil
+}
+
+// Returns an iterator over the keys of a map, as they were when the iterator
+// was created
+func mapIterator(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	keys := receiver.(*Map).Keys()
+
+	i.allocate(functionSize + len(keys)*elementSize)
+
+	return &Iterator{kind: "map", next: valuesOf(keys)}, nil
+}
+
+// Returns an iterator over the characters of a string
+func stringIterator(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	runes := []rune(receiver.(string))
+
+	characters := make([]Value, len(runes))
+	for idx, r := range runes {
+		characters[idx] = string(r)
+	}
+
+	i.allocate(functionSize + len(characters)*elementSize)
+
+	return &Iterator{kind: "string", next: valuesOf(characters)}, nil
+}
+
+// Returns a function that returns the given values one after the other
+func valuesOf(values []Value) func() (Value, bool) {
+	idx := 0
+
+	return func() (Value, bool) {
+		if idx >= len(values) {
+			return nil, false
+		}
+		idx++
+		return values[idx-1], true
+	}
+}
+
+// Built-in methods of strings, receiving the string they were called on
+var stringMethods = map[string]*method{
+	"iterator": {name: "iterator", arity: 0, fn: stringIterator},
+}
+
+// Iterate calls fn with every element of an iterable value until fn returns
+// false. The elements are the ones of a list, the keys of a map, the characters
+// of a string, the integers of a range, the values yielded by a generator, the
+// values received from a channel until it is closed, or the values returned by
+// the next method of the iterator of an object, until next throws a
+// StopIteration. Runtime errors raised while iterating are returned
+func (i *Interpreter) Iterate(value Value, fn func(Value) bool) (err error) {
+	if !IsIterable(value) {
+		return loxerr.Errorf(loxerr.TypeError, "can only iterate lists, maps, strings, ranges and iterables, got %s", TypeName(value))
+	}
+
+	defer recoverError(&err)
+	defer i.begin(context.Background())()
+
+	i.iterate(value, i.callSite(), fn)
+
+	return nil
+}
+
+// Collect returns a list of the elements of an iterable, visited like Iterate
+// does. The elements count against the allocation limit
+func (i *Interpreter) Collect(value Value) (*List, error) {
+	elements := []Value{}
+	err := i.Iterate(value, func(element Value) bool {
+		i.allocate(elementSize)
+		elements = append(elements, element)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewList(elements), nil
+}
+
+// IsIterable reports whether the value can be iterated: lists, maps, strings,
+// ranges, iterators, channels and instances with an iterator method
+func IsIterable(value Value) bool {
+	switch v := value.(type) {
+	case *List, *Map, string, Range, *Iterator, *Generator, *Chan:
+		return true
+	case *Instance:
+		_, ok := v.class.FindMethod("iterator")
+		return ok
+	}
+
+	return false
+}
+
+// Iterates over the value as a loop at the given location would. A generator
+// left before its end, by fn returning false or raising an error, is closed
+func (i *Interpreter) iterate(value Value, location *token.Token, fn func(Value) bool) {
+	switch v := value.(type) {
+	case *List:
+		for idx := 0; idx < len(v.Elements); idx++ {
+			if !fn(v.Elements[idx]) {
+				return
+			}
+		}
+		return
+	case Range:
+		// A range takes no memory, its iteration is only bounded by the steps
+		v.each(func(element Value) bool {
+			i.tick()
+			return fn(element)
+		})
+		return
+	case *Chan:
+		for {
+			element, ok := v.receive(i, location)
+			if !ok || !fn(element) {
+				return
+			}
+		}
+	case *Generator:
+		defer func() {
+			if r := recover(); r != nil {
+				if skipsFinally(r) {
+					v.drop()
+				} else {
+					v.close(i, location)
+				}
+				panic(r)
+			}
+		}()
+
+		for {
+			element, ok := v.next(i, location)
+			if !ok {
+				return
+			}
+			if !fn(element) {
+				v.close(i, location)
+				return
+			}
+		}
+	}
+
+	if !IsIterable(value) {
+		panic(loxerr.NewKind(location, loxerr.TypeError, "Only lists, maps, strings, ranges and iterables can be iterated."))
+	}
+
+	iterator := i.callMethod(value, "iterator", location)
+	for {
+		element, ok := i.nextOf(iterator, location)
+		if !ok || !fn(element) {
+			return
+		}
+	}
+}
+
+// Returns the next element of an iterator. The second return value is false
+// once the next method of the iterator throws a StopIteration
+func (i *Interpreter) nextOf(iterator Value, location *token.Token) (element Value, ok bool) {
+	switch it := iterator.(type) {
+	case *Iterator:
+		return it.next()
+	case *Generator:
+		return it.next(i, location)
+	}
+
+	depth := len(i.frames)
+	defer func() {
+		if r := recover(); r != nil {
+			if !i.isStopIteration(r) {
+				panic(r)
+			}
+			i.frames = i.frames[:depth]
+			element, ok = nil, false
+		}
+	}()
+
+	return i.callMethod(iterator, "next", location), true
+}
+
+// Reports whether a recovered panic is a StopIteration, thrown by the program
+// or raised by a built-in iterator
+func (i *Interpreter) isStopIteration(r interface{}) bool {
+	switch e := r.(type) {
+	case *Exception:
+		instance, ok := i.errorInstance(e.Value)
+		return ok && instance.class.isSubclassOf(i.errorClasses["StopIteration"])
+	case *loxerr.Error:
+		return e.Kind == loxerr.StopIteration
+	}
+
+	return false
+}
+
+// Calls the method of an object with the given name and no arguments, like a
+// call at the given location
+func (i *Interpreter) callMethod(object Value, name string, location *token.Token) Value {
+	callee := i.getProperty(object, &token.Token{Type: token.IDENTIFIER, Lexeme: name, Line: location.Line, Column: location.Column})
+
+	function, ok := callableOf(callee)
+	if !ok {
+		panic(loxerr.NewKind(location, loxerr.TypeError, "Can only call functions and classes."))
+	}
+
+	defer i.enterCall()()
+
+	i.frames = append(i.frames, callFrame{callee: function, site: location})
+	result, err := i.invoke(function, nil, nil)
+	i.frames = i.frames[:len(i.frames)-1]
+
+	if err != nil {
+		raise(location, err)
+	}
+
+	return result
+}
+
+// Returns the site of the innermost call in progress, which is where a native
+// function was called from
+func (i *Interpreter) callSite() *token.Token {
+	if len(i.frames) == 0 {
+		return &token.Token{Type: token.IDENTIFIER, Lexeme: "script"}
+	}
+
+	return i.frames[len(i.frames)-1].site
+}
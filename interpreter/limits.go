@@ -0,0 +1,181 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Limits restrict the resources a program can use. A zero value disables the
+// corresponding limit. The budgets are reset every time the interpreter is entered
+// from Go, so they apply to a single Execute, Interpret or Call
+type Limits struct {
+	MaxSteps           int64         // Maximum number of statements and expressions evaluated
+	MaxCallDepth       int           // Maximum depth of nested calls
+	Timeout            time.Duration // Maximum wall-clock time of the execution
+	MaxAllocationBytes int64         // Maximum number of bytes allocated by the program, estimated
+}
+
+// Errors wrapped by a LimitError, telling which limit was hit
+var (
+	ErrStepLimit       = errors.New("step limit exceeded")
+	ErrCallDepthLimit  = errors.New("call depth limit exceeded")
+	ErrTimeout         = errors.New("time limit exceeded")
+	ErrAllocationLimit = errors.New("allocation limit exceeded")
+	ErrCanceled        = errors.New("execution canceled")
+)
+
+// LimitError is returned when the execution is stopped because it exceeded one of
+// the limits or its context was done. Unlike runtime errors, a LimitError can not
+// be caught by the program itself. Use errors.Is with the Err* variables to find
+// out which limit was hit
+type LimitError struct {
+	Err   error // One of the Err* variables
+	Cause error // The error of the context, if the execution was stopped by it
+}
+
+func (e *LimitError) Error() string {
+	if e.Cause != nil {
+		return e.Err.Error() + ": " + e.Cause.Error()
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the limit that was hit and the context error, if any
+func (e *LimitError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Err, e.Cause}
+	}
+
+	return []error{e.Err}
+}
+
+// The context is polled, and the other tasks given a turn, only every so many
+// steps, as it involves locking
+const contextCheckInterval = 256
+
+// Rough estimates of the memory used by the runtime objects, used for the allocation limit
+const (
+	environmentSize = 64
+	functionSize    = 64
+	instanceSize    = 64
+	fieldSize       = 32
+	listSize        = 64
+	elementSize     = 16
+)
+
+// Tracks the resources used by the current execution
+type budget struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	steps     int64
+	callDepth int
+	allocated int64
+	running   bool // Whether the interpreter has been entered from Go
+}
+
+// SetLimits sets the limits for the following executions
+func (i *Interpreter) SetLimits(limits Limits) {
+	i.limits = limits
+}
+
+// Starts a new execution with a fresh budget. The returned function ends the
+// execution. Nested entries, for example a native function calling back into the
+// interpreter, keep using the budget of the outermost execution. The call frames
+// left behind by an execution stopped with an error are dropped when it ends.
+//
+// The outermost execution holds the lock of the scheduler. Before it ends, it
+// runs the event loop until nothing is left to run, and waits for the tasks it
+// spawned. It fails with the error of a rejected promise that nothing handled,
+// or of a task that was never joined, if it didn't fail itself
+func (i *Interpreter) begin(ctx context.Context) func() {
+	depth := len(i.frames)
+
+	if i.budget.running {
+		return func() {
+			i.frames = i.frames[:depth]
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if i.limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, i.limits.Timeout)
+	}
+
+	i.budget = budget{ctx: ctx, cancel: cancel, running: true}
+	i.scheduler.start()
+
+	return func() {
+		r := recover()
+		if r == nil {
+			r = i.drainLoop()
+		}
+		_, dropped := r.(*LimitError)
+		if failure := i.resetLoop(dropped); r == nil {
+			r = failure
+		}
+		failure := i.scheduler.stop(i, r != nil)
+
+		i.budget.cancel()
+		i.budget = budget{}
+		i.frames = i.frames[:depth]
+
+		if r != nil {
+			panic(r)
+		}
+		if failure != nil {
+			panic(failure)
+		}
+	}
+}
+
+// Counts a single step of the execution and checks that the program is still
+// within its limits
+func (i *Interpreter) tick() {
+	i.budget.steps++
+
+	if i.limits.MaxSteps > 0 && i.budget.steps > i.limits.MaxSteps {
+		panic(&LimitError{Err: ErrStepLimit})
+	}
+
+	if i.budget.steps%contextCheckInterval == 0 && i.budget.ctx != nil {
+		i.scheduler.yield(i)
+
+		if err := i.budget.ctx.Err(); err != nil {
+			panic(contextLimit(err))
+		}
+	}
+}
+
+// Returns the LimitError of an execution stopped by the error of its context
+func contextLimit(err error) *LimitError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &LimitError{Err: ErrTimeout, Cause: err}
+	}
+
+	return &LimitError{Err: ErrCanceled, Cause: err}
+}
+
+// Records the allocation of roughly the given number of bytes
+func (i *Interpreter) allocate(bytes int) {
+	i.budget.allocated += int64(bytes)
+
+	if i.limits.MaxAllocationBytes > 0 && i.budget.allocated > i.limits.MaxAllocationBytes {
+		panic(&LimitError{Err: ErrAllocationLimit})
+	}
+}
+
+// Enters a new call frame. The returned function leaves it
+func (i *Interpreter) enterCall() func() {
+	i.budget.callDepth++
+
+	if i.limits.MaxCallDepth > 0 && i.budget.callDepth > i.limits.MaxCallDepth {
+		i.budget.callDepth--
+		panic(&LimitError{Err: ErrCallDepthLimit})
+	}
+
+	return func() {
+		i.budget.callDepth--
+	}
+}
@@ -0,0 +1,235 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+	"math"
+	"strings"
+)
+
+// List is the runtime representation of a Lox list
+type List struct {
+	Elements []Value
+	frozen   bool
+}
+
+// NewList creates a new list holding the given elements
+func NewList(elements []Value) *List {
+	return &List{Elements: elements}
+}
+
+// String returns the string representation of the list
+func (l *List) String() string {
+	return l.format(Stringify)
+}
+
+// Formats the list with the given representation of the elements
+func (l *List) format(stringify func(Value) string) string {
+	var str strings.Builder
+
+	str.WriteString("[")
+	for idx, element := range l.Elements {
+		if idx > 0 {
+			str.WriteString(", ")
+		}
+		str.WriteString(stringify(element))
+	}
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// Get returns the built-in method of the list with the given name
+func (l *List) Get(name *token.Token) Value {
+	if method, ok := listMethods[name.Lexeme]; ok {
+		return method.bind(l)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// Built-in methods of lists, receiving the list they were called on
+var listMethods = map[string]*method{
+	"length":   {name: "length", arity: 0, fn: listLength},
+	"push":     {name: "push", arity: 1, fn: listPush},
+	"pop":      {name: "pop", arity: 0, fn: listPop},
+	"insert":   {name: "insert", arity: 2, fn: listInsert},
+	"remove":   {name: "remove", arity: 1, fn: listRemove},
+	"map":      {name: "map", arity: 1, fn: listMap},
+	"filter":   {name: "filter", arity: 1, fn: listFilter},
+	"iterator": {name: "iterator", arity: 0, fn: listIterator},
+}
+
+func listLength(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	return float64(len(receiver.(*List).Elements)), nil
+}
+
+func listPush(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	list := receiver.(*List)
+	if list.frozen {
+		return nil, errFrozenList
+	}
+
+	i.allocate(elementSize)
+	list.Elements = append(list.Elements, arguments[0])
+
+	return nil, nil
+}
+
+func listPop(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	list := receiver.(*List)
+	if list.frozen {
+		return nil, errFrozenList
+	}
+
+	if len(list.Elements) == 0 {
+		return nil, kindError(loxerr.IndexError, "pop: the list is empty")
+	}
+
+	last := list.Elements[len(list.Elements)-1]
+	list.Elements = list.Elements[:len(list.Elements)-1]
+
+	return last, nil
+}
+
+func listInsert(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	list := receiver.(*List)
+	if list.frozen {
+		return nil, errFrozenList
+	}
+
+	// Inserting right after the last element is allowed, but negative indices
+	// still count from the last element
+	idx := len(list.Elements)
+	if n, ok := arguments[0].(float64); !ok || n != float64(idx) {
+		var err error
+		if idx, err = normalizeIndex(arguments[0], len(list.Elements)); err != nil {
+			return nil, err
+		}
+	}
+
+	i.allocate(elementSize)
+	list.Elements = append(list.Elements, nil)
+	copy(list.Elements[idx+1:], list.Elements[idx:])
+	list.Elements[idx] = arguments[1]
+
+	return nil, nil
+}
+
+func listRemove(_ *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	list := receiver.(*List)
+	if list.frozen {
+		return nil, errFrozenList
+	}
+
+	idx, err := normalizeIndex(arguments[0], len(list.Elements))
+	if err != nil {
+		return nil, err
+	}
+
+	removed := list.Elements[idx]
+	list.Elements = append(list.Elements[:idx], list.Elements[idx+1:]...)
+
+	return removed, nil
+}
+
+func listMap(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	list := receiver.(*List)
+	mapped := make([]Value, 0, len(list.Elements))
+
+	i.allocate(listSize + len(list.Elements)*elementSize)
+
+	for _, element := range list.Elements {
+		result, err := i.callValue(arguments[0], []Value{element})
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, result)
+	}
+
+	return NewList(mapped), nil
+}
+
+func listFilter(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	list := receiver.(*List)
+	filtered := []Value{}
+
+	i.allocate(listSize + len(list.Elements)*elementSize)
+
+	for _, element := range list.Elements {
+		keep, err := i.callValue(arguments[0], []Value{element})
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(keep) {
+			filtered = append(filtered, element)
+		}
+	}
+
+	return NewList(filtered), nil
+}
+
+// Converts an index into a position in a sequence of the given length. Negative
+// indices count from the end of the sequence. Returns an error if the index is
+// invalid
+func normalizeIndex(index Value, length int) (int, error) {
+	n, ok := index.(float64)
+	if !ok || n != math.Trunc(n) {
+		return 0, kindError(loxerr.TypeError, "Index must be an integer.")
+	}
+
+	// Checked before the conversion, which would wrap the indexes too large for
+	// an int
+	if n < -float64(length) || n >= float64(length) {
+		return 0, loxerr.Errorf(loxerr.IndexError, "Index %s out of bounds for length %d.", Stringify(n), length)
+	}
+
+	idx := int(n)
+	if idx < 0 {
+		idx += length
+	}
+
+	return idx, nil
+}
+
+// Converts the bounds of a slice into positions in a sequence of the given length.
+// Omitted bounds default to the start and the end of the sequence, and bounds
+// outside of the sequence are clamped to it. Returns an error if a bound is
+// invalid
+func sliceBounds(start, end Value, length int) (from, to int, err error) {
+	bound := func(value Value, fallback int) (int, bool) {
+		if value == nil {
+			return fallback, true
+		}
+
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return 0, false
+		}
+
+		// Clamped before the conversion, which would wrap the bounds too large
+		// for an int
+		switch {
+		case n >= float64(length):
+			return length, true
+		case n < -float64(length):
+			return 0, true
+		}
+
+		idx := int(n)
+		if idx < 0 {
+			idx += length
+		}
+
+		return idx, true
+	}
+
+	from, fromOk := bound(start, 0)
+	to, toOk := bound(end, length)
+	if !fromOk || !toOk {
+		return 0, 0, kindError(loxerr.TypeError, "Slice bounds must be integers.")
+	}
+
+	return from, max(from, to), nil
+}
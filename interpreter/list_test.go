@@ -0,0 +1,79 @@
+package interpreter
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInterpreter_Lists(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Literal", source: "[1, \"two\", [3], null,];", expected: "[1, two, [3], null]"},
+		{name: "Empty literal", source: "[];", expected: "[]"},
+		{name: "Index", source: "var xs = [1, 2, 3]; xs[1];", expected: "2"},
+		{name: "Negative index", source: "var xs = [1, 2, 3]; xs[-1];", expected: "3"},
+		{name: "Index assignment", source: "var xs = [1, 2, 3]; xs[0] = xs[-1] = 9; xs;", expected: "[9, 2, 9]"},
+		{name: "Slice", source: "[0, 1, 2, 3, 4][1:3];", expected: "[1, 2]"},
+		{name: "Slice with omitted bounds", source: "var xs = [0, 1, 2, 3]; [xs[:2], xs[2:], xs[:]];", expected: "[[0, 1], [2, 3], [0, 1, 2, 3]]"},
+		{name: "Slice with negative bounds", source: "[0, 1, 2, 3][-3:-1];", expected: "[1, 2]"},
+		{name: "Slice bounds are clamped", source: "[0, 1, 2][1:10];", expected: "[1, 2]"},
+		{name: "Slice bounds beyond the integers", source: "var xs = [0, 1]; [xs[10 ** 300:], xs[-(10 ** 300):]];", expected: "[[], [0, 1]]"},
+		{name: "String index and slice", source: "var s = \"héllo\"; s[1] + s[-3:];", expected: "éllo"},
+		{name: "Push and length", source: "var xs = []; xs.push(1); xs.push(2); xs.length();", expected: "2"},
+		{name: "Pop", source: "var xs = [1, 2]; [xs.pop(), xs];", expected: "[2, [1]]"},
+		{name: "Insert", source: "var xs = [1, 3]; xs.insert(1, 2); xs.insert(3, 4); xs.insert(-4, 0); xs;", expected: "[0, 1, 2, 3, 4]"},
+		{name: "Remove", source: "var xs = [1, 2, 3]; [xs.remove(-2), xs];", expected: "[2, [1, 3]]"},
+		{name: "Map", source: "fun double(x) { return x * 2; } [1, 2, 3].map(double);", expected: "[2, 4, 6]"},
+		{name: "Filter", source: "fun odd(x) { return x != 2; } [1, 2, 3].filter(odd);", expected: "[1, 3]"},
+		{name: "Lists are references", source: "var a = [1]; var b = a; b.push(2); a;", expected: "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ListErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Index out of bounds", source: "var xs = [1, 2];\nxs[2];", expectedErr: "[Pos 2:5] Error at ']': Index 2 out of bounds for length 2."},
+		{name: "Negative index out of bounds", source: "[1][-2];", expectedErr: "Index -2 out of bounds for length 1."},
+		{name: "Index beyond the integers", source: "[1][2 ** 64];", expectedErr: "Index 18446744073709552000 out of bounds for length 1."},
+		{name: "Non-integral index", source: "[1][0.5];", expectedErr: "Index must be an integer."},
+		{name: "Indexing a number", source: "1[0];", expectedErr: "Only lists, strings and maps can be indexed."},
+		{name: "Assigning to a string index", source: "var s = \"a\"; s[0] = \"b\";", expectedErr: "Only lists and maps support index assignment."},
+		{name: "Invalid slice bound", source: "[1][\"a\":];", expectedErr: "Slice bounds must be integers."},
+		{name: "Pop from an empty list", source: "[].pop();", expectedErr: "pop: the list is empty"},
+		{name: "Remove out of bounds", source: "[].remove(0);", expectedErr: "Index 0 out of bounds for length 0."},
+		{name: "Unknown method", source: "[].size();", expectedErr: "Undefined property 'size'."},
+		{name: "Map with a non-function", source: "[1].map(1);", expectedErr: "expected a function but got number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package interpreter
+
+import (
+	"context"
+	loxerr "golox/error"
+	"golox/token"
+	"sort"
+	"time"
+)
+
+// Error of the built-in timer functions given something other than a function
+var errCallback = kindError(loxerr.TypeError, "Expected a function to call.")
+
+// The event loop runs the asynchronous work of an execution on the execution
+// itself: the callbacks of the settled promises, the async functions resumed by
+// them, and the timers. It runs when the top level awaits a promise, and when
+// the execution ends, until nothing is left to run
+type eventLoop struct {
+	clock  Clock
+	jobs   []func(i *Interpreter) // Callbacks of the settled promises, run in order before any timer
+	timers []*timer               // Timers by due time, then by creation
+	nextID int
+
+	rejected   []*Promise          // Rejected promises, failing the execution if nothing handles them
+	coroutines map[*coroutine]bool // Async calls that haven't ended, closed when the execution ends
+	calls      int                 // Number of async calls of the execution
+}
+
+// A callback called once, or repeatedly, by the event loop when it is due
+type timer struct {
+	id       int
+	due      time.Time
+	interval time.Duration // Time between the calls of a repeating timer, zero for a single call
+	callback Value
+	location *token.Token // The call that created the timer
+}
+
+func newEventLoop() *eventLoop {
+	return &eventLoop{clock: systemClock{}, coroutines: make(map[*coroutine]bool)}
+}
+
+// Queues a job, waking a task waiting for the loop to have work
+func (l *eventLoop) enqueue(i *Interpreter, job func(i *Interpreter)) {
+	l.jobs = append(l.jobs, job)
+	i.scheduler.notify()
+}
+
+// Adds a timer in the order it is due, after the timers due at the same time
+func (l *eventLoop) schedule(t *timer) {
+	idx := sort.Search(len(l.timers), func(idx int) bool {
+		return l.timers[idx].due.After(t.due)
+	})
+
+	l.timers = append(l.timers, nil)
+	copy(l.timers[idx+1:], l.timers[idx:])
+	l.timers[idx] = t
+}
+
+// Removes the timer with the given id, if it is still scheduled
+func (l *eventLoop) cancel(id int) {
+	for idx, t := range l.timers {
+		if t.id == id {
+			l.timers = append(l.timers[:idx], l.timers[idx+1:]...)
+			return
+		}
+	}
+}
+
+// Tracks an async call until it ends
+func (l *eventLoop) track(c *coroutine) {
+	l.calls++
+	c.order = l.calls
+	l.coroutines[c] = true
+}
+
+// Reports whether the loop has anything left to run
+func (l *eventLoop) busy() bool {
+	return len(l.jobs) > 0 || len(l.timers) > 0
+}
+
+// Runs the jobs and the timers of the event loop until done reports true. The
+// jobs run first, in the order they were queued, then the timers in the order
+// they are due, waiting on the clock for the next one. When nothing is left to
+// run, the loop waits for the other tasks to give it work, and fails with a
+// deadlock error at the location if none can. Without a location, it returns
+func (i *Interpreter) runLoop(location *token.Token, done func() bool) {
+	l := i.loop
+
+	for !done() {
+		if len(l.jobs) > 0 {
+			job := l.jobs[0]
+			l.jobs[0] = nil
+			l.jobs = l.jobs[1:]
+			job(i)
+			continue
+		}
+
+		if len(l.timers) > 0 {
+			t := l.timers[0]
+			if wait := t.due.Sub(l.clock.Now()); wait > 0 {
+				i.sleep(wait)
+				continue
+			}
+
+			l.timers = l.timers[1:]
+			if t.interval > 0 {
+				t.due = t.due.Add(t.interval)
+				l.schedule(t)
+			}
+
+			if _, err := i.callValue(t.callback, nil); err != nil {
+				raise(t.location, err)
+			}
+			continue
+		}
+
+		if location == nil {
+			return
+		}
+
+		i.scheduler.wait(i, location, func() bool {
+			return done() || l.busy()
+		})
+	}
+}
+
+// Waits on the clock for the next timer, letting the other tasks run meanwhile
+func (i *Interpreter) sleep(d time.Duration) {
+	ctx := i.budget.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	i.scheduler.unlock(i)
+	err := i.loop.clock.Sleep(ctx, d)
+	i.scheduler.lock(i)
+
+	i.scheduler.check()
+	if err != nil {
+		panic(contextLimit(err))
+	}
+}
+
+// Runs the event loop at the end of an execution until nothing is left to run.
+// Returns the error that stopped it, or the error of a rejected promise that
+// nothing handled
+func (i *Interpreter) drainLoop() (failure interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure = r
+		}
+	}()
+
+	i.runLoop(nil, func() bool {
+		return false
+	})
+
+	for _, p := range i.loop.rejected {
+		if !p.handled {
+			return p.failure
+		}
+	}
+
+	return nil
+}
+
+// Drops what an execution left in the event loop, closing the async calls still
+// waiting for a promise in the order they were made. Their finally blocks run,
+// unless dropped is set or one of them runs out of the budget. Returns the first
+// error raised by a finally block
+func (i *Interpreter) resetLoop(dropped bool) (failure interface{}) {
+	l := i.loop
+
+	// A finally block may make async calls of its own, which are closed as well
+	for len(l.coroutines) > 0 {
+		pending := make([]*coroutine, 0, len(l.coroutines))
+		for c := range l.coroutines {
+			pending = append(pending, c)
+		}
+		sort.Slice(pending, func(a, b int) bool {
+			return pending[a].order < pending[b].order
+		})
+
+		for _, c := range pending {
+			delete(l.coroutines, c)
+
+			err := c.close(i, dropped)
+			if _, ok := err.(*LimitError); ok {
+				dropped = true
+			}
+			if failure == nil {
+				failure = err
+			}
+		}
+	}
+
+	l.jobs, l.timers, l.rejected = nil, nil, nil
+	l.calls = 0
+
+	return failure
+}
+
+// Defines the functions creating promises and timers
+func (i *Interpreter) loadEventLoop() {
+	i.builtins.Define("Promise", &builtin{name: "Promise", arity: 1, fn: newPromise})
+	i.builtins.Define("sleep", &builtin{name: "sleep", arity: 1, fn: sleepFor})
+	i.builtins.Define("setTimeout", &builtin{name: "setTimeout", arity: 2, fn: setTimeout})
+	i.builtins.Define("setInterval", &builtin{name: "setInterval", arity: 2, fn: setInterval})
+	i.builtins.Define("clearTimeout", &builtin{name: "clearTimeout", arity: 1, fn: clearTimer})
+	i.builtins.Define("clearInterval", &builtin{name: "clearInterval", arity: 1, fn: clearTimer})
+}
+
+// sleep(ms) returns a promise fulfilled with null after ms milliseconds
+func sleepFor(i *Interpreter, arguments []Value) (Value, error) {
+	delay, ok := milliseconds(arguments[0])
+	if !ok {
+		panic(loxerr.New(i.callSite(), "Delay must be a non-negative number."))
+	}
+
+	p := i.newPendingPromise()
+	i.addTimer(&builtin{name: "sleep", arity: 0, fn: func(i *Interpreter, _ []Value) (Value, error) {
+		p.resolve(i, nil)
+		return nil, nil
+	}}, delay, 0)
+
+	return p, nil
+}
+
+// setTimeout(fn, ms) calls fn after ms milliseconds and returns the id of the timer
+func setTimeout(i *Interpreter, arguments []Value) (Value, error) {
+	if _, ok := callableOf(arguments[0]); !ok {
+		return nil, errCallback
+	}
+
+	delay, ok := milliseconds(arguments[1])
+	if !ok {
+		panic(loxerr.New(i.callSite(), "Delay must be a non-negative number."))
+	}
+
+	return float64(i.addTimer(arguments[0], delay, 0)), nil
+}
+
+// setInterval(fn, ms) calls fn every ms milliseconds and returns the id of the timer
+func setInterval(i *Interpreter, arguments []Value) (Value, error) {
+	if _, ok := callableOf(arguments[0]); !ok {
+		return nil, errCallback
+	}
+
+	interval, ok := milliseconds(arguments[1])
+	if !ok || interval == 0 {
+		panic(loxerr.New(i.callSite(), "Interval must be a positive number."))
+	}
+
+	return float64(i.addTimer(arguments[0], interval, interval)), nil
+}
+
+// clearTimeout(id) and clearInterval(id) cancel a timer. Unknown ids are ignored
+func clearTimer(i *Interpreter, arguments []Value) (Value, error) {
+	if id, ok := toInteger(arguments[0]); ok {
+		i.loop.cancel(int(id))
+	}
+
+	return nil, nil
+}
+
+// Schedules a callback after the delay, repeating it at the interval if it
+// isn't zero. Returns the id of the timer
+func (i *Interpreter) addTimer(callback Value, delay, interval time.Duration) int {
+	i.allocate(functionSize)
+
+	l := i.loop
+	l.nextID++
+	l.schedule(&timer{
+		id:       l.nextID,
+		due:      l.clock.Now().Add(delay),
+		interval: interval,
+		callback: callback,
+		location: i.callSite(),
+	})
+
+	return l.nextID
+}
+
+// Converts a number of milliseconds into a duration. The second return value is
+// false if it is not a non-negative number
+func milliseconds(value Value) (time.Duration, bool) {
+	ms, ok := value.(float64)
+	if !ok || ms < 0 {
+		return 0, false
+	}
+
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
@@ -0,0 +1,316 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+	"math"
+	"strings"
+)
+
+// Map is the runtime representation of a Lox map. The entries keep the order in
+// which their keys were first inserted. A removed entry is only marked as such,
+// keeping the positions of the others, until the removed entries make up half
+// of them and are dropped at once
+type Map struct {
+	entries []mapEntry
+	index   map[hashKey][]int // Positions of the entries by the hash of their keys
+	removed int               // Number of entries marked as removed
+	frozen  bool
+}
+
+type mapEntry struct {
+	hash    hashKey // Kept to rebuild the index without calling the hash methods again
+	key     Value
+	value   Value
+	removed bool
+}
+
+// The hash of a map key. Two keys have the same hash exactly when they are equal
+// according to isEqual, except for instances, which have the same hash when
+// their class and the result of their hash method are equal. Instances with the
+// same hash are compared with a keyEqual function
+type hashKey struct {
+	kind  string
+	class *Class
+	value interface{}
+}
+
+// Reports whether two instances with the same hash are the same map key
+type keyEqual func(a, b Value) bool
+
+// NewMap creates a new empty map
+func NewMap() *Map {
+	return &Map{index: map[hashKey][]int{}}
+}
+
+// Len returns the number of entries in the map
+func (m *Map) Len() int {
+	return len(m.entries) - m.removed
+}
+
+// Keys returns the keys of the map in insertion order
+func (m *Map) Keys() []Value {
+	keys := make([]Value, 0, m.Len())
+	m.each(func(key, _ Value) {
+		keys = append(keys, key)
+	})
+
+	return keys
+}
+
+// Values returns the values of the map in the insertion order of their keys
+func (m *Map) Values() []Value {
+	values := make([]Value, 0, m.Len())
+	m.each(func(_, value Value) {
+		values = append(values, value)
+	})
+
+	return values
+}
+
+// Calls fn with the key and the value of every entry in insertion order
+func (m *Map) each(fn func(key, value Value)) {
+	for _, entry := range m.entries {
+		if !entry.removed {
+			fn(entry.key, entry.value)
+		}
+	}
+}
+
+// String returns the string representation of the map
+func (m *Map) String() string {
+	return m.format(Stringify)
+}
+
+// Formats the map with the given representation of the keys and values
+func (m *Map) format(stringify func(Value) string) string {
+	var str strings.Builder
+
+	str.WriteString("{")
+	m.each(func(key, value Value) {
+		if str.Len() > 1 {
+			str.WriteString(", ")
+		}
+		str.WriteString(stringify(key))
+		str.WriteString(": ")
+		str.WriteString(stringify(value))
+	})
+	str.WriteString("}")
+
+	return str.String()
+}
+
+// Get returns the built-in method of the map with the given name
+func (m *Map) Get(name *token.Token) Value {
+	if method, ok := mapMethods[name.Lexeme]; ok {
+		return method.bind(m)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// Returns the position of the entry with the given key and hash. Keys that are
+// not instances are the same key when their hashes are the same, instances when
+// equal reports them equal too
+func (m *Map) find(hash hashKey, key Value, equal keyEqual) (int, bool) {
+	for _, idx := range m.index[hash] {
+		if hash.kind != "instance" || equal(m.entries[idx].key, key) {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
+
+func (m *Map) lookup(hash hashKey, key Value, equal keyEqual) (Value, bool) {
+	idx, ok := m.find(hash, key, equal)
+	if !ok {
+		return nil, false
+	}
+
+	return m.entries[idx].value, true
+}
+
+// Stores the value under the key, returning whether a new entry was added
+func (m *Map) store(hash hashKey, key, value Value, equal keyEqual) bool {
+	if idx, ok := m.find(hash, key, equal); ok {
+		m.entries[idx].value = value
+		return false
+	}
+
+	m.index[hash] = append(m.index[hash], len(m.entries))
+	m.entries = append(m.entries, mapEntry{hash: hash, key: key, value: value})
+
+	return true
+}
+
+// Removes the entry with the key, returning whether there was one. The entry is
+// marked as removed, and the removed entries are dropped once they make up half
+// of the entries, which keeps removing in constant amortized time
+func (m *Map) remove(hash hashKey, key Value, equal keyEqual) bool {
+	idx, ok := m.find(hash, key, equal)
+	if !ok {
+		return false
+	}
+
+	positions := m.index[hash]
+	for n, position := range positions {
+		if position == idx {
+			positions = append(positions[:n], positions[n+1:]...)
+			break
+		}
+	}
+	if len(positions) == 0 {
+		delete(m.index, hash)
+	} else {
+		m.index[hash] = positions
+	}
+
+	m.entries[idx] = mapEntry{removed: true}
+	m.removed++
+	if m.removed > len(m.entries)/2 {
+		m.compact()
+	}
+
+	return true
+}
+
+// Drops the entries marked as removed and rebuilds the index
+func (m *Map) compact() {
+	kept := make([]mapEntry, 0, m.Len())
+	for _, entry := range m.entries {
+		if !entry.removed {
+			kept = append(kept, entry)
+		}
+	}
+
+	m.entries, m.removed = kept, 0
+	m.index = make(map[hashKey][]int, len(kept))
+	for idx, entry := range kept {
+		m.index[entry.hash] = append(m.index[entry.hash], idx)
+	}
+}
+
+// Built-in methods of maps, receiving the map they were called on
+var mapMethods = map[string]*method{
+	"length":   {name: "length", arity: 0, fn: mapLength},
+	"keys":     {name: "keys", arity: 0, fn: mapKeys},
+	"values":   {name: "values", arity: 0, fn: mapValues},
+	"has":      {name: "has", arity: 1, fn: mapHas},
+	"delete":   {name: "delete", arity: 1, fn: mapDelete},
+	"iterator": {name: "iterator", arity: 0, fn: mapIterator},
+}
+
+func mapLength(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	return float64(receiver.(*Map).Len()), nil
+}
+
+func mapKeys(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	m := receiver.(*Map)
+	i.allocate(listSize + m.Len()*elementSize)
+
+	return NewList(m.Keys()), nil
+}
+
+func mapValues(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	m := receiver.(*Map)
+	i.allocate(listSize + m.Len()*elementSize)
+
+	return NewList(m.Values()), nil
+}
+
+func mapHas(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	key, err := i.hash(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok := receiver.(*Map).lookup(key, arguments[0], i.keyEqual(i.callSite()))
+	return ok, nil
+}
+
+func mapDelete(i *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	if receiver.(*Map).frozen {
+		return nil, errFrozenMap
+	}
+
+	key, err := i.hash(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return receiver.(*Map).remove(key, arguments[0], i.keyEqual(i.callSite())), nil
+}
+
+// Computes the hash of a map key. Instances are hashable when their class has a
+// hash method returning a number or a string. Returns an error if the value can
+// not be used as a key
+func (i *Interpreter) hash(key Value) (hashKey, error) {
+	if hash, ok := primitiveHash(key); ok {
+		return hash, nil
+	}
+
+	switch k := key.(type) {
+	case float64:
+		return hashKey{}, kindError(loxerr.TypeError, "NaN can't be used as a map key.")
+	case *Instance:
+		method, ok := k.class.FindMethod("hash")
+		if !ok {
+			return hashKey{}, kindError(loxerr.TypeError, "Can't use an instance of "+k.class.Name+" as a map key without a 'hash' method.")
+		}
+
+		result, err := i.callValue(method.Bind(k), []Value{})
+		if err != nil {
+			return hashKey{}, err
+		}
+
+		hash, ok := primitiveHash(result)
+		if !ok || hash.kind != "number" && hash.kind != "string" {
+			return hashKey{}, kindError(loxerr.TypeError, "The 'hash' method of "+k.class.Name+" must return a number or a string.")
+		}
+
+		return hashKey{kind: "instance", class: k.class, value: hash.value}, nil
+	}
+
+	return hashKey{}, kindError(loxerr.TypeError, "Can't use a "+TypeName(key)+" as a map key.")
+}
+
+// Returns the function comparing instance keys with the same hash. Instances
+// are compared with the __eq method of their class, or by identity if it
+// doesn't define one. The location is where a failing __eq is reported
+func (i *Interpreter) keyEqual(location *token.Token) keyEqual {
+	return func(a, b Value) bool {
+		if a == b {
+			return true
+		}
+
+		equal, ok := i.overloadEqual(location, a, b)
+		return ok && equal
+	}
+}
+
+// Computes the hash of null, booleans, numbers, strings and ranges, which are
+// compared by value
+func primitiveHash(key Value) (hashKey, bool) {
+	switch k := key.(type) {
+	case nil:
+		return hashKey{kind: "null"}, true
+	case bool:
+		return hashKey{kind: "boolean", value: k}, true
+	case string:
+		return hashKey{kind: "string", value: k}, true
+	case float64:
+		if math.IsNaN(k) {
+			return hashKey{}, false
+		}
+		// Negative zero is equal to zero
+		if k == 0 {
+			k = 0
+		}
+		return hashKey{kind: "number", value: k}, true
+	case Range:
+		return hashKey{kind: "range", value: k}, true
+	}
+
+	return hashKey{}, false
+}
@@ -0,0 +1,106 @@
+package interpreter
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpreter_Maps(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Literal", source: "var m = {\"a\": 1, 2: [3], true: null,}; m;", expected: "{a: 1, 2: [3], true: null}"},
+		{name: "Empty literal", source: "var m = {}; m;", expected: "{}"},
+		{name: "Index", source: "var m = {\"a\": 1, \"b\": 2}; m[\"b\"];", expected: "2"},
+		{name: "Index assignment", source: "var m = {}; m[\"a\"] = 1; m[\"b\"] = 2; m[\"a\"] = 3; m;", expected: "{a: 3, b: 2}"},
+		{name: "Duplicate keys in a literal", source: "var m = {\"a\": 1, \"a\": 2}; m;", expected: "{a: 2}"},
+		{name: "Numbers compare by value", source: "var m = {0: \"zero\"}; [m[-0], m[1 - 1]];", expected: "[zero, zero]"},
+		{name: "Keys and values", source: "var m = {\"x\": 1, \"y\": 2}; [m.keys(), m.values(), m.length()];", expected: "[[x, y], [1, 2], 2]"},
+		{name: "Has", source: "var m = {null: 1}; [m.has(null), m.has(false)];", expected: "[true, false]"},
+		{name: "Delete keeps order", source: "var m = {1: 1, 2: 2, 3: 3}; var d = [m.delete(2), m.delete(2)]; m[4] = 4; [d, m];", expected: "[[true, false], {1: 1, 3: 3, 4: 4}]"},
+		{name: "Delete most entries", source: "var m = {}; for (var n in 0..<10) m[n] = n; for (var n in 0..<8) m.delete(n); m[0] = 0; [m, m[9], m.has(3), m.length()];", expected: "[{8: 8, 9: 9, 0: 0}, 9, false, 3]"},
+		{name: "Ranges compare by value", source: "var m = {0..2: \"a\"}; m[0..2] = \"b\"; [m, m[0..2], m.has(0..<2)];", expected: "[{0..2: b}, b, false]"},
+		{
+			name:     "Instances with a hash method",
+			source:   "class P { init(x) { this.x = x; } hash() { return this.x; } __eq(o) { return this.x == o.x; } } var m = {}; m[P(1)] = \"a\"; m[P(1)];",
+			expected: "a",
+		},
+		{
+			name:     "Instances with the same hash compare with __eq",
+			source:   "class P { init(x) { this.x = x; } hash() { return 0; } __eq(o) { return this.x == o.x; } } var m = {P(1): 1, P(2): 2, P(1): 3}; [m.length(), m[P(2)], m[P(1)], m.delete(P(2)), m.has(P(2)), m[P(1)]];",
+			expected: "[2, 2, 3, true, false, 3]",
+		},
+		{
+			name:     "Instances without __eq compare by identity",
+			source:   "class P { hash() { return 0; } } var p = P(); var m = {p: 1, P(): 2}; [m.length(), m[p], m.has(P())];",
+			expected: "[2, 1, false]",
+		},
+		{name: "Maps are references", source: "var a = {}; var b = a; b[1] = 2; a;", expected: "{1: 2}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_MapErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Missing key", source: "var m = {\"a\": 1};\nm[\"b\"];", expectedErr: "[Pos 2:6] Error at ']': Undefined key 'b'."},
+		{name: "List key", source: "var m = {[1]: 2};", expectedErr: "Can't use a list as a map key."},
+		{name: "Function key", source: "fun f() {} var m = {}; m[f] = 1;", expectedErr: "Can't use a function as a map key."},
+		{name: "Map key", source: "var m = {}; m.has(m);", expectedErr: "Can't use a map as a map key."},
+		{name: "Instance without a hash method", source: "class P {} var m = {}; m[P()] = 1;", expectedErr: "Can't use an instance of P as a map key without a 'hash' method."},
+		{name: "Invalid hash", source: "class P { hash() { return []; } } var m = {}; m[P()];", expectedErr: "The 'hash' method of P must return a number or a string."},
+		{name: "NaN key", source: "var m = {}; m[0 / 0] = 1;", expectedErr: "NaN can't be used as a map key."},
+		{name: "Unknown method", source: "var m = {}; m.size();", expectedErr: "Undefined property 'size'."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestMap_Conversion(t *testing.T) {
+	value, err := ToValue(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("ToValue() returned an error: %v", err)
+	}
+
+	if Stringify(value) != "{a: 1}" {
+		t.Errorf("ToValue() = %v, want {a: 1}", Stringify(value))
+	}
+
+	back, err := FromValue(value, reflect.TypeOf(map[string]int{}))
+	if err != nil {
+		t.Fatalf("FromValue() returned an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(back.Interface(), map[string]int{"a": 1}) {
+		t.Errorf("FromValue() = %v, want map[a:1]", back.Interface())
+	}
+}
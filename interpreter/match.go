@@ -0,0 +1,169 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+)
+
+// VisitMatchExpr implements the expr.Visitor interface
+//
+// The arms are tried in order. Every arm runs in a new environment holding the
+// variables bound by its pattern, where its guard and body are evaluated
+func (i *Interpreter) VisitMatchExpr(e *expr.Match) interface{} {
+	subject := i.evaluate(e.Subject)
+
+	for _, arm := range e.Arms {
+		i.allocate(environmentSize)
+		environment := NewEnvironment(i.environment)
+
+		if !i.matchPattern(arm.Pattern, subject, environment) {
+			continue
+		}
+
+		if result, ok := i.runArm(arm, environment); ok {
+			return result
+		}
+	}
+
+	panic(loxerr.New(e.Keyword, "No match arm matches the value "+Stringify(subject)+"."))
+}
+
+// Runs the guard and the body of an arm whose pattern matched. The second return
+// value is false if the guard rejected the value
+func (i *Interpreter) runArm(arm *expr.MatchArm, environment *Environment) (Value, bool) {
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+	i.environment = environment
+
+	if arm.Guard != nil && !isTruthy(i.evaluate(arm.Guard)) {
+		return nil, false
+	}
+
+	if arm.Block == nil {
+		return i.evaluate(arm.Body), true
+	}
+
+	for _, statement := range stmt.Statements(arm.Block) {
+		i.execute(statement)
+	}
+
+	return nil, true
+}
+
+// Reports whether the value matches the pattern, defining the variables bound
+// by the pattern in the environment
+func (i *Interpreter) matchPattern(pattern expr.Pattern, value Value, environment *Environment) bool {
+	switch p := pattern.(type) {
+	case *expr.LiteralPattern:
+		if equal, ok := i.overloadEqual(p.Token, value, p.Value); ok {
+			return equal
+		}
+		return isEqual(value, p.Value)
+	case *expr.WildcardPattern:
+		return true
+	case *expr.BindingPattern:
+		environment.Define(p.Name.Lexeme, value)
+		return true
+	case *expr.ListPattern:
+		return i.matchList(p, value, environment)
+	case *expr.InstancePattern:
+		return i.matchInstance(p, value, environment)
+	case *expr.AlternativePattern:
+		for _, alternative := range p.Alternatives {
+			if i.matchPattern(alternative, value, environment) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Matches a list with as many elements as the pattern, or at least as many if
+// the pattern has a rest
+func (i *Interpreter) matchList(pattern *expr.ListPattern, value Value, environment *Environment) bool {
+	list, ok := value.(*List)
+	if !ok {
+		return false
+	}
+
+	count := len(pattern.Elements)
+	if len(list.Elements) < count || (pattern.Rest == nil && len(list.Elements) != count) {
+		return false
+	}
+
+	for idx, element := range pattern.Elements {
+		if !i.matchPattern(element, list.Elements[idx], environment) {
+			return false
+		}
+	}
+
+	if pattern.Rest != nil && pattern.Rest.Lexeme != "_" {
+		rest := make([]Value, len(list.Elements)-count)
+		copy(rest, list.Elements[count:])
+
+		i.allocate(listSize + len(rest)*elementSize)
+		environment.Define(pattern.Rest.Lexeme, NewList(rest))
+	}
+
+	return true
+}
+
+// Matches an instance of the class of the pattern or of one of its subclasses.
+// A pattern without a class matches any instance or map. A missing property
+// fails the match instead of raising an error
+func (i *Interpreter) matchInstance(pattern *expr.InstancePattern, value Value, environment *Environment) bool {
+	if !i.isPatternObject(pattern, value) {
+		return false
+	}
+
+	for _, field := range pattern.Fields {
+		property, ok := i.patternProperty(value, field.Name)
+		if !ok || !i.matchPattern(field.Pattern, property, environment) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Reports whether the value is an object the instance pattern can look into
+func (i *Interpreter) isPatternObject(pattern *expr.InstancePattern, value Value) bool {
+	if pattern.Class == nil {
+		switch value.(type) {
+		case *Instance, *Map:
+			return true
+		}
+		return false
+	}
+
+	class, ok := i.evaluate(pattern.Class).(*Class)
+	if !ok {
+		panic(loxerr.NewKind(pattern.Class.Name, loxerr.TypeError, "Only classes can be matched by instance patterns."))
+	}
+
+	instance, ok := value.(*Instance)
+	return ok && instance.class.isSubclassOf(class)
+}
+
+// Reads a property of an instance, through its getter if it has one like
+// getProperty does, or a string key of a map. The second return value reports whether the property exists
+func (i *Interpreter) patternProperty(object Value, name *token.Token) (Value, bool) {
+	switch o := object.(type) {
+	case *Instance:
+		if getter, _ := o.class.findAccessors(name.Lexeme); getter != nil {
+			return i.callMember(getter, o, name, nil), true
+		}
+		if value, ok := o.fields[name.Lexeme]; ok {
+			return value, true
+		}
+	case *Map:
+		return o.lookup(i.mapKey(name, name.Lexeme), name.Lexeme, nil)
+	}
+
+	return nil, false
+}
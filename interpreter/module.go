@@ -0,0 +1,233 @@
+package interpreter
+
+import (
+	"fmt"
+	loxerr "golox/error"
+	"golox/lexer"
+	"golox/parser"
+	"golox/resolver"
+	"golox/stmt"
+	"golox/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Module is a Lox source file loaded by an import statement. Every module runs
+// in its own global scope, whose variables are the exports of the module. Names
+// starting with an underscore are private to the module
+type Module struct {
+	Name        string // The path given to the import statement
+	Path        string // The absolute path of the file
+	environment *Environment
+}
+
+// Get returns the value of an export of the module
+func (m *Module) Get(name *token.Token) Value {
+	if value, ok := m.Export(name.Lexeme); ok {
+		return value
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined export '"+name.Lexeme+"' in module '"+m.Name+"'."))
+}
+
+// Export returns the value of an export of the module. The second return value
+// reports whether the module exports the name
+func (m *Module) Export(name string) (Value, bool) {
+	if strings.HasPrefix(name, "_") {
+		return nil, false
+	}
+
+	value, ok := m.environment.values[name]
+	return value, ok
+}
+
+// String returns the string representation of the module
+func (m *Module) String() string {
+	return "<module " + m.Name + ">"
+}
+
+// A module file being loaded
+type moduleFile struct {
+	name string
+	path string
+}
+
+// SetSearchPath sets the directories that imports are looked up from when the
+// module is not found relative to the importing file
+func (i *Interpreter) SetSearchPath(dirs ...string) {
+	i.searchPath = dirs
+}
+
+// SetImportRoot confines the imports to the files inside the directory, the
+// others are not found, so that a script can't read any other file. An empty
+// directory, the default, allows importing any file
+func (i *Interpreter) SetImportRoot(dir string) {
+	i.importRoot = ""
+	if dir != "" {
+		i.importRoot = realPath(dir)
+	}
+}
+
+// SetScriptPath sets the path of the file the main script was read from.
+// Relative imports of the script are resolved against its directory, and a
+// module importing the script back is reported as an import cycle
+func (i *Interpreter) SetScriptPath(path string) {
+	i.dir = filepath.Dir(path)
+
+	if abs, err := filepath.Abs(path); err == nil {
+		i.importing = []moduleFile{{name: path, path: abs}}
+	}
+}
+
+// VisitImportStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitImportStmt(s *stmt.Import) interface{} {
+	module := i.importModule(s.Path)
+
+	if s.Alias != nil {
+		i.environment.Define(s.Alias.Lexeme, module)
+		return nil
+	}
+
+	for _, name := range s.Names {
+		i.environment.Define(name.Lexeme, module.Get(name))
+	}
+
+	return nil
+}
+
+// Loads the module with the given path. A module is executed only the first
+// time it is imported, later imports get the same module
+func (i *Interpreter) importModule(path *token.Token) *Module {
+	name, _ := path.Literal.(string)
+
+	file, ok := i.findModule(name)
+	if !ok {
+		panic(loxerr.New(path, "Can't find module '"+name+"'."))
+	}
+
+	if module, ok := i.modules[file]; ok {
+		return module
+	}
+
+	for idx, loading := range i.importing {
+		if loading.path == file {
+			chain := make([]string, 0, len(i.importing)-idx+1)
+			for _, m := range i.importing[idx:] {
+				chain = append(chain, m.name)
+			}
+			chain = append(chain, name)
+
+			panic(loxerr.New(path, "Import cycle: "+strings.Join(chain, " -> ")+"."))
+		}
+	}
+
+	statements := i.parseModule(path, name, file)
+
+	module := &Module{Name: name, Path: file, environment: NewEnvironment(i.builtins)}
+	i.allocate(environmentSize)
+
+	previousDir := i.dir
+	i.importing = append(i.importing, moduleFile{name: name, path: file})
+	defer func() {
+		i.dir = previousDir
+		i.importing = i.importing[:len(i.importing)-1]
+	}()
+
+	i.dir = filepath.Dir(file)
+	i.executeBlock(statements, module.environment)
+
+	i.modules[file] = module
+
+	return module
+}
+
+// Reads and parses the source of a module. Syntax errors are reported at the
+// import statement, prefixed with the name of the module, as are the warnings
+// of the resolver written to the error output. The errors give the position but
+// not the text they are at, so that importing a file that isn't a module doesn't
+// reveal its content
+func (i *Interpreter) parseModule(path *token.Token, name, file string) []stmt.Stmt {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		panic(loxerr.New(path, "Can't read module '"+name+"': "+err.Error()))
+	}
+
+	l := lexer.New(string(source))
+	l.ScanTokens()
+
+	r := resolver.New()
+	statements, errors := parser.New(l.Tokens).ParseProgram()
+	if len(errors) == 0 {
+		errors = r.Resolve(statements)
+	}
+
+	if len(errors) > 0 {
+		messages := make([]string, len(errors))
+		for idx, err := range errors {
+			messages[idx] = fmt.Sprintf("[Pos %d:%d] %s", err.Token.Line, err.Token.Column, err.Message)
+		}
+
+		panic(loxerr.New(path, "Error in module '"+name+"': "+strings.Join(messages, "; ")))
+	}
+
+	for _, warning := range r.Warnings() {
+		//nolint:errcheck // Reporting is best effort
+		fmt.Fprintln(i.stderr, "Warning in module '"+name+"': "+warning.String())
+	}
+
+	return statements
+}
+
+// Resolves the path of an import into the absolute path of a file. Relative
+// paths are looked up from the directory of the importing file first and then
+// from the search path. The ".lox" extension can be left out. Files outside of
+// the import root, if there is one, are skipped
+func (i *Interpreter) findModule(name string) (string, bool) {
+	if filepath.Ext(name) == "" {
+		name += ".lox"
+	}
+
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = []string{filepath.Join(i.dir, name)}
+		for _, dir := range i.searchPath {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			if abs, err := filepath.Abs(candidate); err == nil && i.insideImportRoot(abs) {
+				return abs, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Reports whether the file is inside the import root once the symbolic links
+// are followed, so that neither ".." nor a link leads out of it
+func (i *Interpreter) insideImportRoot(file string) bool {
+	if i.importRoot == "" {
+		return true
+	}
+
+	rel, err := filepath.Rel(i.importRoot, realPath(file))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Returns the absolute path of a file with the symbolic links followed, or as
+// far as it can be resolved
+func realPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	return path
+}
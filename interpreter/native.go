@@ -0,0 +1,178 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	loxerr "golox/error"
+	"reflect"
+)
+
+// NativeFunc is the signature of Go functions that can be exposed to Lox
+type NativeFunc func(arguments []Value) (Value, error)
+
+// Native is a function implemented in Go that can be called from Lox
+type Native struct {
+	Name  string
+	arity int
+	fn    NativeFunc
+}
+
+// NewNative creates a new native function. Use Variadic as the arity for
+// functions that accept any number of arguments
+func NewNative(name string, arity int, fn NativeFunc) *Native {
+	return &Native{Name: name, arity: arity, fn: fn}
+}
+
+// Arity implements the Callable interface
+func (n *Native) Arity() int {
+	return n.arity
+}
+
+// Call implements the Callable interface
+func (n *Native) Call(_ *Interpreter, arguments []Value) (Value, error) {
+	return n.fn(arguments)
+}
+
+// String returns the string representation of the native function
+func (n *Native) String() string {
+	return "<native fn " + n.Name + ">"
+}
+
+// A function built into the interpreter. Unlike a Native, it is given the
+// interpreter calling it
+type builtin struct {
+	name  string
+	arity int
+	fn    func(i *Interpreter, arguments []Value) (Value, error)
+}
+
+// Arity implements the Callable interface
+func (b *builtin) Arity() int {
+	return b.arity
+}
+
+// Call implements the Callable interface
+func (b *builtin) Call(i *Interpreter, arguments []Value) (Value, error) {
+	return b.fn(i, arguments)
+}
+
+// String returns the string representation of the function
+func (b *builtin) String() string {
+	return "<native fn " + b.name + ">"
+}
+
+// RegisterNative defines a native function with the given name in the globals of
+// the interpreter, where every module can see it. Use Variadic as the arity for
+// functions that accept any number of arguments
+func (i *Interpreter) RegisterNative(name string, arity int, fn NativeFunc) {
+	i.builtins.Define(name, NewNative(name, arity, fn))
+}
+
+// RegisterFunc defines an arbitrary Go function in the globals of the interpreter.
+// The arguments and return value of the function are converted between Lox values
+// and Go types automatically, see ToValue and FromValue for the supported types.
+//
+// The function may return nothing, a single value, an error or a value and an error
+func (i *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	native, err := wrapFunc(name, reflect.ValueOf(fn))
+	if err != nil {
+		return err
+	}
+
+	i.builtins.Define(name, native)
+
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Wraps a Go function value into a native function using reflection
+func wrapFunc(name string, fn reflect.Value) (*Native, error) {
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("cannot register %s: %s is not a function", name, fn.Kind())
+	}
+
+	fnType := fn.Type()
+	if err := checkResults(fnType); err != nil {
+		return nil, fmt.Errorf("cannot register %s: %w", name, err)
+	}
+
+	arity := fnType.NumIn()
+	if fnType.IsVariadic() {
+		arity = Variadic
+	}
+
+	return NewNative(name, arity, func(arguments []Value) (Value, error) {
+		in, err := convertArguments(fnType, arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		return convertResults(fn.Call(in))
+	}), nil
+}
+
+func checkResults(fnType reflect.Type) error {
+	switch fnType.NumOut() {
+	case 0, 1:
+		return nil
+	case 2:
+		if fnType.Out(1) == errorType {
+			return nil
+		}
+	}
+
+	return errors.New("a function must return at most a value and an error")
+}
+
+// Converts the Lox arguments into the parameter types of the Go function
+func convertArguments(fnType reflect.Type, arguments []Value) ([]reflect.Value, error) {
+	params := fnType.NumIn()
+	if fnType.IsVariadic() && len(arguments) < params-1 {
+		return nil, loxerr.Errorf(loxerr.TypeError, "expected at least %d arguments but got %d", params-1, len(arguments))
+	}
+
+	in := make([]reflect.Value, len(arguments))
+	for idx, argument := range arguments {
+		target := paramType(fnType, idx)
+
+		converted, err := FromValue(argument, target)
+		if err != nil {
+			return nil, loxerr.Errorf(loxerr.TypeError, "argument %d: %w", idx+1, err)
+		}
+		in[idx] = converted
+	}
+
+	return in, nil
+}
+
+// Returns the type of the parameter at the given index, taking the element type
+// of a variadic parameter into account
+func paramType(fnType reflect.Type, idx int) reflect.Type {
+	last := fnType.NumIn() - 1
+	if fnType.IsVariadic() && idx >= last {
+		return fnType.In(last).Elem()
+	}
+
+	return fnType.In(idx)
+}
+
+// Converts the return values of the Go function into a Lox value and an error
+func convertResults(out []reflect.Value) (Value, error) {
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	last := out[len(out)-1]
+	if last.Type() == errorType {
+		if !last.IsNil() {
+			return nil, last.Interface().(error)
+		}
+
+		if len(out) == 1 {
+			return nil, nil
+		}
+	}
+
+	return ToValue(out[0].Interface())
+}
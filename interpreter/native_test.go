@@ -0,0 +1,147 @@
+package interpreter
+
+import (
+	"errors"
+	loxerr "golox/error"
+	"golox/lexer"
+	"golox/parser"
+	"strings"
+	"testing"
+)
+
+func eval(t *testing.T, i *Interpreter, source string) (Value, error) {
+	t.Helper()
+
+	l := lexer.New(source)
+	l.ScanTokens()
+
+	return i.Interpret(parser.New(l.Tokens).Parse())
+}
+
+func run(t *testing.T, i *Interpreter, source string) (Value, error) {
+	t.Helper()
+
+	l := lexer.New(source)
+	l.ScanTokens()
+
+	statements, errs := parser.New(l.Tokens).ParseProgram()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+
+	return i.Execute(statements)
+}
+
+func TestInterpreter_RegisterNative(t *testing.T) {
+	i := New()
+	i.RegisterNative("add", 2, func(arguments []Value) (Value, error) {
+		return arguments[0].(float64) + arguments[1].(float64), nil
+	})
+	i.RegisterNative("count", Variadic, func(arguments []Value) (Value, error) {
+		return float64(len(arguments)), nil
+	})
+	i.RegisterNative("fail", 0, func(_ []Value) (Value, error) {
+		return nil, errors.New("service unavailable")
+	})
+
+	tests := []struct {
+		name        string
+		source      string
+		expected    Value
+		expectedErr string
+	}{
+		{name: "Fixed arity", source: "add(1, 2) * 2", expected: 6.0},
+		{name: "Variadic", source: "count(1, \"a\", true)", expected: 3.0},
+		{name: "Variadic without arguments", source: "count()", expected: 0.0},
+		{name: "Wrong arity", source: "add(1)", expectedErr: "Expected 2 arguments but got 1."},
+		{name: "Error returned from Go", source: "fail()", expectedErr: "service unavailable"},
+		{name: "Calling a non-callable", source: "\"add\"(1, 2)", expectedErr: "Can only call functions and classes."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := eval(t, i, tt.source)
+
+			if tt.expectedErr != "" {
+				var runtimeErr *loxerr.Error
+				if !errors.As(err, &runtimeErr) || runtimeErr.Message != tt.expectedErr {
+					t.Fatalf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil || actual != tt.expected {
+				t.Errorf("Interpret() = %v, %v, want %v", actual, err, tt.expected)
+			}
+		})
+	}
+}
+
+// A named numeric type, converted into a number like its underlying type
+type celsius float64
+
+func TestInterpreter_RegisterFunc(t *testing.T) {
+	i := New()
+
+	funcs := map[string]interface{}{
+		"repeat": strings.Repeat,
+		"sum": func(numbers ...int) int {
+			total := 0
+			for _, n := range numbers {
+				total += n
+			}
+			return total
+		},
+		"half": func(n int) (float64, error) {
+			if n%2 != 0 {
+				return 0, errors.New("odd number")
+			}
+			return float64(n) / 2, nil
+		},
+		"boiling": func() celsius {
+			return 100
+		},
+	}
+
+	for name, fn := range funcs {
+		if err := i.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%s) failed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		expected    Value
+		expectedErr string
+	}{
+		{name: "Strings and integers", source: "repeat(\"ab\", 3)", expected: "ababab"},
+		{name: "Variadic Go function", source: "sum(1, 2, 3)", expected: 6.0},
+		{name: "Value and error", source: "half(10)", expected: 5.0},
+		{name: "Returned error", source: "half(3)", expectedErr: "odd number"},
+		{name: "Named float type", source: "boiling() + 0.5", expected: 100.5},
+		{name: "Wrong argument type", source: "repeat(1, 2)", expectedErr: "argument 1: expected string but got number"},
+		{name: "Non-integral number", source: "half(1.5)", expectedErr: "argument 1: expected an integer but got 1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := eval(t, i, tt.source)
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil || actual != tt.expected {
+				t.Errorf("Interpret() = %v, %v, want %v", actual, err, tt.expected)
+			}
+		})
+	}
+
+	if err := i.RegisterFunc("invalid", 42); err == nil {
+		t.Errorf("Expected an error when registering a non-function")
+	}
+}
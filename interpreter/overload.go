@@ -0,0 +1,166 @@
+package interpreter
+
+import (
+	"context"
+	loxerr "golox/error"
+	"golox/token"
+)
+
+// The special methods that overload the binary operators for instances. The
+// method is looked up on the left operand and called with the right one
+var binaryMethods = map[token.Type]string{
+	token.PLUS:          "__add",
+	token.MINUS:         "__sub",
+	token.STAR:          "__mul",
+	token.SLASH:         "__div",
+	token.PERCENT:       "__mod",
+	token.SLASH_SLASH:   "__floordiv",
+	token.STAR_STAR:     "__pow",
+	token.LESS:          "__lt",
+	token.LESS_EQUAL:    "__le",
+	token.GREATER:       "__gt",
+	token.GREATER_EQUAL: "__ge",
+}
+
+// Returns the special method with the given name if the value is an instance
+// whose class defines it
+func specialMethod(value Value, name string) (*Function, *Instance) {
+	instance, ok := value.(*Instance)
+	if !ok {
+		return nil, nil
+	}
+
+	method, ok := instance.class.FindMethod(name)
+	if !ok {
+		return nil, nil
+	}
+
+	return method, instance
+}
+
+// Applies a binary operator overloaded by one of the operands. The second return
+// value reports whether the operator is overloaded for the operands at all
+func (i *Interpreter) overload(operator *token.Token, left, right Value) (Value, bool) {
+	switch operator.Type {
+	case token.EQUAL_EQUAL, token.BANG_EQUAL:
+		equal, ok := i.overloadEqual(operator, left, right)
+		return equal == (operator.Type == token.EQUAL_EQUAL), ok
+	}
+
+	name, ok := binaryMethods[operator.Type]
+	if !ok {
+		return nil, false
+	}
+
+	if method, instance := specialMethod(left, name); method != nil {
+		return i.callMember(method, instance, operator, []Value{right}), true
+	}
+
+	return i.overloadComparison(operator, left, right)
+}
+
+// Compares two values with the __eq method of the left operand, or of the right
+// one if only it defines the method
+func (i *Interpreter) overloadEqual(operator *token.Token, left, right Value) (bool, bool) {
+	if method, instance := specialMethod(left, "__eq"); method != nil {
+		return isTruthy(i.callMember(method, instance, operator, []Value{right})), true
+	}
+
+	if method, instance := specialMethod(right, "__eq"); method != nil {
+		return isTruthy(i.callMember(method, instance, operator, []Value{left})), true
+	}
+
+	return false, false
+}
+
+// Derives the comparisons a class does not define from its __lt method, which
+// is enough for all of them:
+//   - a > b is b < a
+//   - a <= b is !(b < a)
+//   - a >= b is !(a < b)
+func (i *Interpreter) overloadComparison(operator *token.Token, left, right Value) (Value, bool) {
+	receiver, argument, negate := right, left, false
+
+	switch operator.Type {
+	case token.GREATER:
+	case token.LESS_EQUAL:
+		negate = true
+	case token.GREATER_EQUAL:
+		receiver, argument, negate = left, right, true
+	default:
+		return nil, false
+	}
+
+	if method, instance := specialMethod(receiver, "__lt"); method != nil {
+		return isTruthy(i.callMember(method, instance, operator, []Value{argument})) != negate, true
+	}
+
+	if receiver == left {
+		return nil, false
+	}
+
+	return i.swappedComparison(operator, left, right)
+}
+
+// Derives > and <= from the __lt method of the left operand when the right one
+// doesn't define it, as in V(1) > 0. The operands are swapped and the result
+// negated, telling equal operands apart with __eq:
+//   - a > b is !(a < b) and a != b
+//   - a <= b is a < b or a == b
+func (i *Interpreter) swappedComparison(operator *token.Token, left, right Value) (Value, bool) {
+	method, instance := specialMethod(left, "__lt")
+	if method == nil {
+		return nil, false
+	}
+
+	if isTruthy(i.callMember(method, instance, operator, []Value{right})) {
+		return operator.Type == token.LESS_EQUAL, true
+	}
+
+	equal, _ := i.overloadEqual(operator, left, right)
+	return equal == (operator.Type == token.LESS_EQUAL), true
+}
+
+// Returns the callable of a value. Functions and classes are callable
+// themselves, instances through their __call method
+func callableOf(value Value) (Callable, bool) {
+	if method, instance := specialMethod(value, "__call"); method != nil {
+		return method.Bind(instance), true
+	}
+
+	function, ok := value.(Callable)
+	return function, ok
+}
+
+// ToString returns the string representation of a value like Stringify, using
+// the __str method of the instances that define one, also inside lists and maps.
+// Runtime errors raised by a __str method are returned
+func (i *Interpreter) ToString(value Value) (str string, err error) {
+	defer recoverError(&err)
+	defer i.begin(context.Background())()
+
+	return i.stringify(value), nil
+}
+
+// Returns the string representation of a value, calling the __str methods
+func (i *Interpreter) stringify(value Value) string {
+	switch v := value.(type) {
+	case *List:
+		return v.format(i.stringify)
+	case *Map:
+		return v.format(i.stringify)
+	case *Instance:
+		method, _ := specialMethod(v, "__str")
+		if method == nil {
+			break
+		}
+
+		str, ok := i.callMember(method, v, method.declaration.Name, nil).(string)
+		if !ok {
+			panic(loxerr.NewKind(method.declaration.Name, loxerr.TypeError, "__str must return a string."))
+		}
+		return str
+	}
+
+	return Stringify(value)
+}
@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/token"
+	"math"
+	"strconv"
+)
+
+// Range is the runtime representation of a range of integers, created by the
+// ".." and "..<" operators. The integers are not stored, they are produced one
+// at a time when the range is iterated. A range whose end is before its start
+// is empty. Ranges are values, two ranges with the same bounds are equal
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool // Whether the end is part of the range
+}
+
+// Creates the range between the operands of a range operator. The range must
+// hold at most math.MaxInt64 integers
+func newRange(operator *token.Token, left, right Value) Range {
+	start, end := checkIntegerOperands(operator, left, right)
+
+	r := Range{Start: start, End: end, Inclusive: operator.Type == token.DOT_DOT}
+	if r.span() > math.MaxInt64 {
+		panic(loxerr.NewKind(operator, loxerr.TypeError, "Range must hold at most "+strconv.FormatInt(math.MaxInt64, 10)+" integers."))
+	}
+
+	return r
+}
+
+// Returns the number of integers in the range, which may not fit in an int64
+func (r Range) span() uint64 {
+	if r.End < r.Start || r.End == r.Start && !r.Inclusive {
+		return 0
+	}
+
+	// The difference of the bounds is exact in uint64, whatever their signs
+	span := uint64(r.End) - uint64(r.Start)
+	if r.Inclusive {
+		span++
+	}
+
+	return span
+}
+
+// Len returns the number of integers in the range. The ranges created by the
+// range operators always have a length that fits in an int64
+func (r Range) Len() int64 {
+	return int64(r.span())
+}
+
+// Contains reports whether the value is one of the integers of the range
+func (r Range) Contains(value Value) bool {
+	n, ok := toInteger(value)
+	return ok && n >= r.Start && uint64(n)-uint64(r.Start) < r.span()
+}
+
+// Get returns the built-in method of the range with the given name
+func (r Range) Get(name *token.Token) Value {
+	if method, ok := rangeMethods[name.Lexeme]; ok {
+		return method.bind(r)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the range
+func (r Range) String() string {
+	operator := "..<"
+	if r.Inclusive {
+		operator = ".."
+	}
+
+	return strconv.FormatInt(r.Start, 10) + operator + strconv.FormatInt(r.End, 10)
+}
+
+// Calls fn with every integer of the range until fn returns false
+func (r Range) each(fn func(Value) bool) {
+	for idx := int64(0); idx < r.Len(); idx++ {
+		if !fn(float64(r.Start + idx)) {
+			return
+		}
+	}
+}
+
+// Built-in methods of ranges, receiving the range they were called on
+var rangeMethods = map[string]*method{
+	"length":   {name: "length", arity: 0, fn: rangeLength},
+	"contains": {name: "contains", arity: 1, fn: rangeContains},
+	"iterator": {name: "iterator", arity: 0, fn: rangeIterator},
+}
+
+func rangeLength(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	return float64(receiver.(Range).Len()), nil
+}
+
+func rangeContains(_ *Interpreter, receiver Value, arguments []Value) (Value, error) {
+	return receiver.(Range).Contains(arguments[0]), nil
+}
+
+// Returns an iterator over the integers of a range
+func rangeIterator(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	r := receiver.(Range)
+	idx := int64(0)
+
+	i.allocate(functionSize)
+
+	return &Iterator{kind: "range", next: func() (Value, bool) {
+		if idx >= r.Len() {
+			return nil, false
+		}
+		idx++
+		return float64(r.Start + idx - 1), true
+	}}, nil
+}
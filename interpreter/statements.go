@@ -0,0 +1,355 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	loxerr "golox/error"
+	"golox/stmt"
+)
+
+// Execute runs the given statements. If the last statement is an expression
+// statement, its value is returned. Runtime errors are returned as an
+// *error.Error instead of being raised
+func (i *Interpreter) Execute(statements []stmt.Stmt) (Value, error) {
+	return i.ExecuteContext(context.Background(), statements)
+}
+
+// ExecuteContext runs the given statements, stopping with a LimitError if the
+// context is done before the execution finishes
+func (i *Interpreter) ExecuteContext(ctx context.Context, statements []stmt.Stmt) (result Value, err error) {
+	defer recoverError(&err)
+	defer i.begin(ctx)()
+
+	for idx, statement := range statements {
+		if expression, ok := statement.(*stmt.Expression); ok && idx == len(statements)-1 {
+			return i.evaluate(expression.Expression), nil
+		}
+		i.execute(statement)
+	}
+
+	return nil, nil
+}
+
+// VisitBlockStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitBlockStmt(s *stmt.Block) interface{} {
+	i.executeBlock(s.Statements, i.newEnvironment())
+	return nil
+}
+
+// VisitBreakStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitBreakStmt(_ *stmt.Break) interface{} {
+	panic(loopBreak)
+}
+
+// VisitClassStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitClassStmt(s *stmt.Class) interface{} {
+	var superclass *Class
+	if s.Superclass != nil {
+		class, ok := i.evaluate(s.Superclass).(*Class)
+		if !ok {
+			panic(loxerr.NewKind(s.Superclass.Name, loxerr.TypeError, "Superclass must be a class."))
+		}
+		superclass = class
+	}
+
+	traits := make([]*Trait, len(s.Traits))
+	for idx, variable := range s.Traits {
+		trait, ok := i.evaluate(variable).(*Trait)
+		if !ok {
+			panic(loxerr.NewKind(variable.Name, loxerr.TypeError, "Can only mix in traits."))
+		}
+		traits[idx] = trait
+	}
+
+	i.environment.Define(s.Name.Lexeme, nil)
+
+	// Methods of a subclass close over an environment where "super" is bound
+	closure := i.environment
+	if superclass != nil {
+		closure = NewEnvironment(i.environment)
+		closure.Define("super", superclass)
+	}
+
+	class := &Class{Name: s.Name.Lexeme, Superclass: superclass, members: newMembers(s.Members, closure)}
+
+	if message := class.mixin(traits); message != "" {
+		panic(loxerr.New(s.Name, message))
+	}
+
+	i.environment.Assign(s.Name, class)
+
+	return nil
+}
+
+// VisitTraitStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitTraitStmt(s *stmt.Trait) interface{} {
+	i.allocate(functionSize)
+	i.environment.Define(s.Name.Lexeme, &Trait{Name: s.Name.Lexeme, members: newMembers(s.Members, i.environment)})
+
+	return nil
+}
+
+// VisitContinueStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitContinueStmt(_ *stmt.Continue) interface{} {
+	panic(loopContinue)
+}
+
+// VisitExpressionStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitExpressionStmt(s *stmt.Expression) interface{} {
+	i.evaluate(s.Expression)
+	return nil
+}
+
+// VisitForInStmt implements the stmt.Visitor interface
+//
+// Every iteration binds the loop variables in a new environment, so closures
+// created in the body capture the element of their own iteration. The entries
+// of a map are the ones it had when the loop started
+func (i *Interpreter) VisitForInStmt(s *stmt.ForIn) interface{} {
+	iterable := i.evaluate(s.Iterable)
+
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+
+	iteration := func(key, value Value) bool {
+		i.environment = i.newEnvironment()
+
+		define := i.environment.Define
+		if s.Constant {
+			define = i.environment.DefineConstant
+		}
+		if s.Key != nil {
+			define(s.Key.Lexeme, key)
+		}
+		define(s.Name.Lexeme, value)
+
+		signal := i.executeLoopBody(s.Body)
+		i.environment = previous
+
+		return signal != loopBreak
+	}
+
+	if m, ok := iterable.(*Map); ok && s.Key != nil {
+		keys, values := m.Keys(), m.Values()
+		i.allocate(2 * len(keys) * elementSize)
+
+		for idx, key := range keys {
+			if !iteration(key, values[idx]) {
+				break
+			}
+		}
+		return nil
+	}
+
+	index := 0.0
+	i.iterate(iterable, s.Keyword, func(element Value) bool {
+		index++
+		return iteration(index-1, element)
+	})
+
+	return nil
+}
+
+// VisitFunctionStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitFunctionStmt(s *stmt.Function) interface{} {
+	i.allocate(functionSize)
+	i.environment.Define(s.Name.Lexeme, NewFunction(s, i.environment, false))
+	return nil
+}
+
+// VisitIfStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitIfStmt(s *stmt.If) interface{} {
+	if isTruthy(i.evaluate(s.Condition)) {
+		i.execute(s.ThenBranch)
+	} else if s.ElseBranch != nil {
+		i.execute(s.ElseBranch)
+	}
+
+	return nil
+}
+
+// VisitPrintStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitPrintStmt(s *stmt.Print) interface{} {
+	value := i.evaluate(s.Expression)
+
+	//nolint:errcheck // Printing is best effort, a broken writer should not stop the program
+	fmt.Fprintln(i.stdout, i.stringify(value))
+
+	return nil
+}
+
+// VisitReturnStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitReturnStmt(s *stmt.Return) interface{} {
+	var value Value
+	if s.Value != nil {
+		value = i.evaluate(s.Value)
+	}
+
+	panic(&returnValue{value: value, keyword: s.Keyword})
+}
+
+// VisitThrowStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitThrowStmt(s *stmt.Throw) interface{} {
+	panic(i.exception(i.evaluate(s.Value), s.Keyword))
+}
+
+// VisitTryStmt implements the stmt.Visitor interface
+//
+// The finally block runs however the try and catch blocks are left, including
+// by a return, break or continue statement, or by closing the generator running
+// it. It doesn't run when there is nothing left to run it, see skipsFinally
+func (i *Interpreter) VisitTryStmt(s *stmt.Try) interface{} {
+	if s.Finally != nil {
+		depth := len(i.frames)
+
+		defer func() {
+			r := recover()
+			if !skipsFinally(r) {
+				// The frames of the calls an error unwound are left behind
+				i.frames = i.frames[:depth]
+				i.executeBlock(s.Finally, i.newEnvironment())
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+
+	if s.CatchName == nil {
+		i.executeBlock(s.Body, i.newEnvironment())
+		return nil
+	}
+
+	if value, caught := i.tryBlock(s.Body); caught {
+		environment := i.newEnvironment()
+		environment.Define(s.CatchName.Lexeme, value)
+		i.executeBlock(s.Catch, environment)
+	}
+
+	return nil
+}
+
+// Reports whether a recovered panic unwinds the execution without running the
+// finally blocks: when the execution is stopped by a LimitError, when a task is
+// closed, and when an async function or a generator is dropped
+func skipsFinally(r interface{}) bool {
+	switch e := r.(type) {
+	case *LimitError, taskExit:
+		return true
+	case asyncExit:
+		return e.dropped
+	case generatorExit:
+		return e.dropped
+	}
+
+	return false
+}
+
+// Executes the body of a try statement, returning the value of the exception
+// that ended it, if any
+func (i *Interpreter) tryBlock(body []stmt.Stmt) (value Value, caught bool) {
+	depth := len(i.frames)
+
+	defer func() {
+		if r := recover(); r != nil {
+			if value, caught = i.caughtValue(r); !caught {
+				panic(r)
+			}
+			i.frames = i.frames[:depth]
+		}
+	}()
+
+	i.executeBlock(body, i.newEnvironment())
+
+	return nil, false
+}
+
+// VisitVarStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitVarStmt(s *stmt.Var) interface{} {
+	var value Value
+	if s.Initializer != nil {
+		value = i.evaluate(s.Initializer)
+	}
+
+	define := i.environment.Define
+	if s.Constant {
+		define = i.environment.DefineConstant
+	}
+
+	if s.Pattern != nil {
+		i.destructure(s.Pattern, value, define)
+		return nil
+	}
+
+	define(s.Name.Lexeme, value)
+
+	return nil
+}
+
+// VisitWhileStmt implements the stmt.Visitor interface
+func (i *Interpreter) VisitWhileStmt(s *stmt.While) interface{} {
+	for isTruthy(i.evaluate(s.Condition)) {
+		if i.executeLoopBody(s.Body) == loopBreak {
+			break
+		}
+
+		if s.Increment != nil {
+			i.evaluate(s.Increment)
+		}
+	}
+
+	return nil
+}
+
+// Signals raised by break and continue statements, unwinding the execution up
+// to the innermost loop
+type loopSignal int
+
+const (
+	loopBreak loopSignal = iota + 1
+	loopContinue
+)
+
+// Executes the body of a loop, returning the signal that ended it early, if any
+func (i *Interpreter) executeLoopBody(body stmt.Stmt) (signal loopSignal) {
+	defer func() {
+		if r := recover(); r != nil {
+			s, ok := r.(loopSignal)
+			if !ok {
+				panic(r)
+			}
+			signal = s
+		}
+	}()
+
+	i.execute(body)
+
+	return 0
+}
+
+func (i *Interpreter) execute(s stmt.Stmt) {
+	i.tick()
+	s.Accept(i)
+}
+
+// Creates a new environment nested in the current one
+func (i *Interpreter) newEnvironment() *Environment {
+	i.allocate(environmentSize)
+	return NewEnvironment(i.environment)
+}
+
+// Executes the statements in the given environment, restoring the previous
+// environment afterwards even if the execution is unwound by a panic
+func (i *Interpreter) executeBlock(statements []stmt.Stmt, environment *Environment) {
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+
+	i.environment = environment
+	for _, statement := range statements {
+		i.execute(statement)
+	}
+}
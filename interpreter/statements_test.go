@@ -0,0 +1,417 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInterpreter_Loops(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "Break",
+			source:   "var i = 0; while (true) { if (i == 3) break; print i; i = i + 1; }",
+			expected: "0\n1\n2\n",
+		},
+		{
+			name:     "Continue in while",
+			source:   "var i = 0; while (i < 4) { i = i + 1; if (i == 2) continue; print i; }",
+			expected: "1\n3\n4\n",
+		},
+		{
+			name:     "Continue in for runs the increment",
+			source:   "for (var i = 0; i < 4; i = i + 1) { if (i == 1) continue; print i; }",
+			expected: "0\n2\n3\n",
+		},
+		{
+			name:     "Break leaves the innermost loop",
+			source:   "for (var i = 0; i < 2; i = i + 1) { for (var j = 0; j < 5; j = j + 1) { if (j == 1) break; print i + j; } }",
+			expected: "0\n1\n",
+		},
+		{
+			name:     "Break from a nested block",
+			source:   "for (;;) { { var x = 1; { print x; break; } } }",
+			expected: "1\n",
+		},
+		{
+			name:     "Return from a loop",
+			source:   "fun f() { while (true) { return 1; } } print f();",
+			expected: "1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i := New()
+			i.SetStdout(&out)
+
+			if _, err := run(t, i, tt.source); err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_ForIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "List", source: "for (var x in [1, 2, 3]) print x;", expected: "1\n2\n3\n"},
+		{name: "List with indices", source: "for (var i, x in [\"a\", \"b\"]) print [i, x];", expected: "[0, a]\n[1, b]\n"},
+		{name: "Map keys", source: "for (var k in {\"a\": 1, \"b\": 2}) print k;", expected: "a\nb\n"},
+		{name: "Map entries", source: "for (var k, v in {\"a\": 1, \"b\": 2}) print [k, v];", expected: "[a, 1]\n[b, 2]\n"},
+		{name: "Map changed in the loop", source: "var m = {\"a\": 1}; for (var k, v in m) { m[\"b\"] = 2; m.delete(\"a\"); print [k, v]; }", expected: "[a, 1]\n"},
+		{name: "String", source: "for (var c in \"hé\") print c;", expected: "h\né\n"},
+		{name: "Inclusive range", source: "for (var n in 1..3) print n;", expected: "1\n2\n3\n"},
+		{name: "Exclusive range", source: "for (var n in 1..<3) print n;", expected: "1\n2\n"},
+		{name: "Empty range", source: "for (var n in 3..1) print n;", expected: ""},
+		{name: "Generator", source: "fun g() { yield 1; yield 2; } for (var i, x in g()) print [i, x];", expected: "[0, 1]\n[1, 2]\n"},
+		{
+			name: "Iteration protocol",
+			source: "class Countdown { init(n) { this.n = n; } iterator() { return this; } " +
+				"next() { if (this.n == 0) throw StopIteration(); this.n--; return this.n + 1; } } " +
+				"for (var n in Countdown(3)) print n;",
+			expected: "3\n2\n1\n",
+		},
+		{name: "Break and continue", source: "for (var n in 0..10) { if (n == 1) continue; if (n == 3) break; print n; }", expected: "0\n2\n"},
+		{name: "Return from the body", source: "fun first(xs) { for (var x in xs) return x; } print first([4, 5]);", expected: "4\n"},
+		{name: "Fresh variable per iteration", source: "var fs = []; for (var x in 0..<3) fs.push(() => x); for (var f in fs) print f();", expected: "0\n1\n2\n"},
+		{name: "Variable does not leak", source: "var x = \"outer\"; for (var x in [1]) {} print x;", expected: "outer\n"},
+		{
+			name:     "Break closes a generator",
+			source:   "fun g() { yield 1; yield 2; } var it = g(); for (var x in it) break; try { it.next(); } catch (e) { print e.message; }",
+			expected: "Iterator is exhausted.\n",
+		},
+		{
+			name:     "Exception closes a generator",
+			source:   "fun g() { yield 1; yield 2; } var it = g(); try { for (var x in it) throw x; } catch (e) { print e; } try { it.next(); } catch (e) { print e.message; }",
+			expected: "1\nIterator is exhausted.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i := New()
+			i.SetStdout(&out)
+
+			if _, err := run(t, i, tt.source); err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Ranges(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expected    string
+		expectedErr string
+	}{
+		{name: "Representation", source: "[0..10, 0..<10];", expected: "[0..10, 0..<10]"},
+		{name: "Bounds are expressions", source: "var n = 2; 1..n * 2;", expected: "1..4"},
+		{name: "Equality", source: "[1..3 == 1..3, 1..3 == 1..<3];", expected: "[true, false]"},
+		{name: "Length", source: "[(0..10).length(), (0..<10).length(), (5..1).length()];", expected: "[11, 10, 0]"},
+		{name: "Contains", source: "var r = 1..<4; [r.contains(1), r.contains(3), r.contains(4), r.contains(1.5)];", expected: "[true, true, false, false]"},
+		{name: "Iterator", source: "var it = (1..2).iterator(); [it, it.next(), it.next()];", expected: "[<range iterator>, 1, 2]"},
+		{name: "Bounds must be integers", source: "0..1.5;", expectedErr: "Operands must be integers."},
+		{name: "Widest range", source: "((0 - 2 ** 62)..<(2 ** 62 - 1024)).length() == 2 ** 63 - 1024;", expected: "true"},
+		{name: "Range too large", source: "(0 - 2 ** 63)..<(2 ** 62);", expectedErr: "Error at '..<': Range must hold at most 9223372036854775807 integers."},
+		{name: "Only iterables can be iterated", source: "for (var x in 1) {}", expectedErr: "[Pos 1:12] Error at 'in': Only lists, maps, strings, ranges and iterables can be iterated."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Constants(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expected    string
+		expectedErr string
+	}{
+		{name: "Const", source: "const x = 1; x;", expected: "1"},
+		{name: "Let", source: "let x = 1; x;", expected: "1"},
+		{name: "Shadowing in a block", source: "const x = 1; { var x = 2; x = 3; } x;", expected: "1"},
+		{name: "Redeclared as a variable", source: "const x = 1; var x = 2; x = 3; x;", expected: "3"},
+		{name: "Destructuring", source: "const [a, {b}] = [1, {\"b\": 2}]; [a, b];", expected: "[1, 2]"},
+		{name: "Constant loop variable", source: "var out = []; for (const i, x in [\"a\"]) out.push([i, x]); out;", expected: "[[0, a]]"},
+		{name: "Assignment", source: "const x = 1;\nx = 2;", expectedErr: "[Pos 2:1] Error at 'x': Can't assign to constant 'x'."},
+		{name: "Assignment in a function", source: "fun f() { x = 2; } const x = 1; f();", expectedErr: "Can't assign to constant 'x'."},
+		{name: "Compound assignment", source: "let x = 1; x += 1;", expectedErr: "Can't assign to constant 'x'."},
+		{name: "Increment", source: "const x = 1; x++;", expectedErr: "Can't assign to constant 'x'."},
+		{name: "Destructuring assignment", source: "const [a, b] = [1, 2]; [a, b] = [b, a];", expectedErr: "Can't assign to constant 'a'."},
+		{name: "Loop variable", source: "for (const x in [1]) x = 2;", expectedErr: "Can't assign to constant 'x'."},
+		{name: "TypeError", source: "const x = 1; var e; try { x = 2; } catch (error) { e = error; } e;", expected: "TypeError instance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := run(t, New(), tt.source)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+					t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Exceptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "Catch a thrown value",
+			source:   "try { print 1; throw \"boom\"; print 2; } catch (e) { print e; }",
+			expected: "1\nboom\n",
+		},
+		{
+			name:     "Catch an error instance",
+			source:   "try { throw TypeError(\"bad\"); } catch (e) { print e.message; print e.stack; }",
+			expected: "bad\n[[line 1] in script]\n",
+		},
+		{
+			name:     "Error subclasses",
+			source:   "class MyError < Error {} try { throw MyError(\"x\"); } catch (e) { print e.message; print e.stack.length(); }",
+			expected: "x\n1\n",
+		},
+		{
+			name:     "Throw from a nested call",
+			source:   "fun g() {\n throw Error(\"deep\");\n}\nfun f() { g(); }\ntry { f(); } catch (e) { print e.stack; }",
+			expected: "[[line 2] in g(), [line 4] in f(), [line 5] in script]\n",
+		},
+		{
+			name:     "Runtime errors are catchable",
+			source:   "try { missing; } catch (e) { print e.message; print e.stack; }",
+			expected: "Undefined variable 'missing'.\n[[line 1] in script]\n",
+		},
+		{
+			name:     "Type errors are catchable",
+			source:   "class P {} try { -\"a\"; } catch (e) { print e.message; } try { P().x; } catch (e) { print e.message; }",
+			expected: "Operand must be a number.\nUndefined property 'x'.\n",
+		},
+		{
+			name:     "Errors of native functions are catchable",
+			source:   "try { [].pop(); } catch (e) { print e.message; }",
+			expected: "pop: the list is empty\n",
+		},
+		{
+			name:     "Finally after success and failure",
+			source:   "try { print 1; } finally { print 2; } try { try { throw 3; } finally { print 4; } } catch (e) { print e; }",
+			expected: "1\n2\n4\n3\n",
+		},
+		{
+			name:     "Finally runs on return",
+			source:   "fun f() { try { return 1; } finally { print \"finally\"; } } print f();",
+			expected: "finally\n1\n",
+		},
+		{
+			name:     "Finally runs on break and continue",
+			source:   "for (var i = 0; i < 3; i++) { try { if (i == 0) continue; if (i == 2) break; print i; } finally { print \"f\"; } }",
+			expected: "f\n1\nf\nf\n",
+		},
+		{
+			name:     "Finally runs on the stack of the try statement",
+			source:   "fun g() {\n throw 1;\n}\nfun f() {\n try { g(); } finally {\n throw Error(); }\n}\ntry { f(); } catch (e) { print e.stack; }",
+			expected: "[[line 6] in f(), [line 8] in script]\n",
+		},
+		{
+			name:     "Finally runs after the catch block",
+			source:   "try { throw 1; } catch (e) { print \"catch\"; } finally { print \"finally\"; }",
+			expected: "catch\nfinally\n",
+		},
+		{
+			name:     "Rethrow keeps the stack trace",
+			source:   "fun f() {\n throw Error();\n}\ntry { try { f(); } catch (e) {\n throw e; } } catch (e) { print e.stack; }",
+			expected: "[[line 2] in f(), [line 4] in script]\n",
+		},
+		{
+			name:     "Throw from a callback",
+			source:   "try { [1, 2].map((x) => { throw x * 10; }); } catch (e) { print e; }",
+			expected: "10\n",
+		},
+		{
+			name:     "Catch restores the environment",
+			source:   "var x = \"global\"; fun f() { var x = \"local\"; throw 1; } try { f(); } catch (e) {} print x;",
+			expected: "global\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i := New()
+			i.SetStdout(&out)
+
+			if _, err := run(t, i, tt.source); err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_UncaughtExceptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Thrown value", source: "throw 42;", expectedErr: "[Pos 1:1] Error at 'throw': Uncaught 42"},
+		{name: "Error instance", source: "\nthrow NameError(\"x\");", expectedErr: "[Pos 2:1] Error at 'throw': Uncaught NameError: x"},
+		{name: "Error without a message", source: "throw Error();", expectedErr: "[Pos 1:1] Error at 'throw': Uncaught Error"},
+		{name: "Error thrown from a catch block", source: "try { throw 1; } catch (e) { throw e + 1; }", expectedErr: "[Pos 1:30] Error at 'throw': Uncaught 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+
+			_, err := run(t, i, tt.source)
+
+			var exception *Exception
+			if !errors.As(err, &exception) || err.Error() != tt.expectedErr {
+				t.Errorf("Expected exception '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_Destructuring(t *testing.T) {
+	const point = "class Point { init(x, y) { this.x = x; this.y = y; } } "
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "List", source: "var [a, b] = [1, 2]; print a + b;", expected: "3\n"},
+		{name: "Object", source: point + "var {x, y} = Point(1, 2); print [x, y];", expected: "[1, 2]\n"},
+		{name: "Object with a class", source: point + "var Point{x: px} = Point(3, 4); print px;", expected: "3\n"},
+		{name: "Map", source: "var {name, age} = {\"name\": \"Ann\", \"age\": 30}; print [name, age];", expected: "[Ann, 30]\n"},
+		{name: "Rest and wildcard", source: "var [_, second, ...others] = [1, 2, 3, 4]; print [second, others];", expected: "[2, [3, 4]]\n"},
+		{name: "Nested", source: point + "var [{x}, [a, b]] = [Point(1, 2), [3, 4]]; print x + a + b;", expected: "8\n"},
+		{name: "Getter", source: "class C { size { return 2; } } var {size} = C(); print size;", expected: "2\n"},
+		{name: "Swap", source: "var a = 1; var b = 2; [a, b] = [b, a]; print [a, b];", expected: "[2, 1]\n"},
+		{
+			name:     "Assignment to properties and indexes",
+			source:   point + "var p = Point(0, 0); var xs = [0, 0]; [p.x, xs[1], _] = [5, 6, 7]; print [p.x, xs];",
+			expected: "[5, [0, 6]]\n",
+		},
+		{name: "Nested assignment", source: "var a; var b; var c; [a, [b, c]] = [1, [2, 3]]; print [a, b, c];", expected: "[1, 2, 3]\n"},
+		{name: "Assignment value", source: "var a; var b; print [a, b] = [1, 2];", expected: "[1, 2]\n"},
+		{name: "In a for loop", source: "for (var [i, n] = [0, 2]; i < n; i++) print i;", expected: "0\n1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i := New()
+			i.SetStdout(&out)
+
+			if _, err := run(t, i, tt.source); err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_DestructuringErrors(t *testing.T) {
+	const point = "class Point { init(x, y) { this.x = x; this.y = y; } } "
+
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Missing element", source: "var [a, b] = [1];", expectedErr: "Error at 'b': Missing list element at index 1, the list has 1 elements."},
+		{name: "Too many elements", source: "var [a] = [1, 2];", expectedErr: "Error at '[': Too many elements to destructure, expected 1 but got 2."},
+		{name: "Not a list", source: "var [a] = 1;", expectedErr: "Expected a list to destructure but got number."},
+		{name: "Missing property", source: point + "var {x, z} = Point(1, 2);", expectedErr: "Error at 'z': Missing property 'z' in Point instance."},
+		{name: "Missing key", source: "var {x} = {\"y\": 1};", expectedErr: "Error at 'x': Missing key 'x' in map."},
+		{name: "Not an object", source: "var {x} = [1];", expectedErr: "Expected an instance or a map to destructure but got list."},
+		{name: "Wrong class", source: point + "class Other {} var Point{x} = Other();", expectedErr: "Expected a Point instance to destructure but got instance."},
+		{name: "Missing element in an assignment", source: "var a; var b; [a, b] = [1];", expectedErr: "Error at 'b': Missing list element at index 1, the list has 1 elements."},
+		{name: "Undefined assignment target", source: "[a] = [1];", expectedErr: "Undefined variable 'a'."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			_, err := run(t, i, tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_TopLevelReturn(t *testing.T) {
+	i := New()
+	i.SetStdout(io.Discard)
+
+	// The resolver rejects this program, the interpreter reports an error instead of a stray return
+	_, err := run(t, i, "print 1;\nreturn 2;")
+
+	expected := "[Pos 2:1] Error at 'return': Can't return from top-level code."
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
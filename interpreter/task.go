@@ -0,0 +1,269 @@
+package interpreter
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/token"
+	"runtime"
+	"sync"
+)
+
+// Task is a call running concurrently with the rest of the program, started by
+// a spawn expression. Joining the task waits for the call to return.
+//
+// Every task runs on a goroutine of its own with a copy of the interpreter, but
+// only one of them runs Lox code at any time: the tasks take turns, switching
+// when the running one blocks on a channel or a join, and every few hundred
+// steps otherwise. The environments, lists, maps and instances shared by the
+// tasks are therefore never accessed concurrently, and need no locking
+type Task struct {
+	name    string
+	done    bool        // Whether the call has returned
+	result  Value       // The value returned by the call
+	failure interface{} // The error that ended the call, raised again by join
+	joined  bool        // Whether the failure has been seen by a join
+}
+
+// Get returns the built-in method of the task with the given name
+func (t *Task) Get(name *token.Token) Value {
+	if method, ok := taskMethods[name.Lexeme]; ok {
+		return method.bind(t)
+	}
+
+	panic(loxerr.NewKind(name, loxerr.NameError, "Undefined property '"+name.Lexeme+"'."))
+}
+
+// String returns the string representation of the task
+func (t *Task) String() string {
+	return "<task " + t.name + ">"
+}
+
+// Built-in methods of tasks, receiving the task they were called on
+var taskMethods = map[string]*method{
+	"join": {name: "join", arity: 0, fn: taskJoin},
+	"done": {name: "done", arity: 0, fn: taskDone},
+}
+
+// Waits for the task to end, returning the value returned by its call or
+// raising the error that ended it
+func taskJoin(i *Interpreter, receiver Value, _ []Value) (Value, error) {
+	t := receiver.(*Task)
+
+	i.scheduler.wait(i, i.callSite(), func() bool {
+		return t.done
+	})
+	t.joined = true
+
+	if t.failure != nil {
+		panic(t.failure)
+	}
+
+	return t.result, nil
+}
+
+func taskDone(_ *Interpreter, receiver Value, _ []Value) (Value, error) {
+	return receiver.(*Task).done, nil
+}
+
+// Raised in the tasks still running when the execution that spawned them is
+// stopped by an error, unwinding them without running any more of them
+type taskExit struct{}
+
+// The lock that lets a single task run at a time, and the state of the tasks
+// shared by all of them
+type scheduler struct {
+	mu      sync.Mutex // Held by the task running Lox code
+	changed *sync.Cond // Broadcast whenever a task may be able to make progress
+
+	live     int         // Number of tasks that haven't ended, the execution itself included
+	blocked  int         // Number of tasks waiting since the last broadcast
+	tasks    []*Task     // Tasks spawned by the current execution
+	canceled bool        // Whether the tasks still running must exit
+	limit    *LimitError // The limit a task exceeded, which stops every task
+	// Incremented on every deadlock, waking the tasks blocked at the time
+	deadlocks int
+
+	// The steps and allocations of the execution, used by the task that holds
+	// the lock and saved here when it releases it
+	steps     int64
+	allocated int64
+}
+
+func newScheduler() *scheduler {
+	s := &scheduler{}
+	s.changed = sync.NewCond(&s.mu)
+
+	return s
+}
+
+// Takes the lock at the start of an execution, which runs as the only task
+func (s *scheduler) start() {
+	s.mu.Lock()
+	s.live = 1
+	s.steps, s.allocated = 0, 0
+}
+
+// Ends an execution, waiting for the tasks it spawned to end. The tasks are
+// made to exit if the execution failed. Returns the error that ended a task that
+// was never joined, if any, and releases the lock
+func (s *scheduler) stop(i *Interpreter, failed bool) interface{} {
+	if failed {
+		s.canceled = true
+		s.notify()
+	}
+
+	for s.live > 1 {
+		if s.blocked+1 == s.live {
+			s.deadlocks++
+			s.notify()
+		}
+		s.sleep(i)
+	}
+
+	var failure interface{}
+	for _, t := range s.tasks {
+		if t.failure != nil && !t.joined {
+			failure = t.failure
+			break
+		}
+	}
+
+	s.tasks = nil
+	s.canceled = false
+	s.limit = nil
+	s.mu.Unlock()
+
+	return failure
+}
+
+// Takes the lock to run the interpreter of a task
+func (s *scheduler) lock(i *Interpreter) {
+	s.mu.Lock()
+	i.budget.steps, i.budget.allocated = s.steps, s.allocated
+}
+
+// Releases the lock taken by the interpreter of a task
+func (s *scheduler) unlock(i *Interpreter) {
+	s.steps, s.allocated = i.budget.steps, i.budget.allocated
+	s.mu.Unlock()
+}
+
+// Lets the other tasks run, if there are any, before the interpreter continues
+func (s *scheduler) yield(i *Interpreter) {
+	if s.live > 1 {
+		s.unlock(i)
+		runtime.Gosched()
+		s.lock(i)
+	}
+
+	s.check()
+}
+
+// Stops the task if the execution is canceled or a task exceeded a limit
+func (s *scheduler) check() {
+	if s.canceled {
+		panic(taskExit{})
+	}
+	if s.limit != nil {
+		panic(s.limit)
+	}
+}
+
+// Releases the lock until the next broadcast
+func (s *scheduler) sleep(i *Interpreter) {
+	s.steps, s.allocated = i.budget.steps, i.budget.allocated
+	s.blocked++
+	s.changed.Wait()
+	i.budget.steps, i.budget.allocated = s.steps, s.allocated
+}
+
+// Wakes the waiting tasks so that they check whether they can continue
+func (s *scheduler) notify() {
+	s.blocked = 0
+	s.changed.Broadcast()
+}
+
+// Blocks the task until ready returns true, letting the other tasks run. If
+// every task would then be blocked, none of them can make progress and the
+// blocked tasks fail with a deadlock error at the location they wait at
+func (s *scheduler) wait(i *Interpreter, location *token.Token, ready func() bool) {
+	for !ready() {
+		s.check()
+
+		if s.blocked+1 == s.live {
+			s.deadlocks++
+			s.notify()
+			panic(deadlockError(location))
+		}
+
+		deadlocks := s.deadlocks
+		s.sleep(i)
+
+		if s.deadlocks != deadlocks {
+			panic(deadlockError(location))
+		}
+	}
+}
+
+// Returns the error raised in a blocked task at the given location when none of
+// the tasks can make progress
+func deadlockError(location *token.Token) *loxerr.Error {
+	return loxerr.New(location, "Deadlock, every task is blocked.")
+}
+
+// VisitSpawnExpr implements the expr.Visitor interface
+func (i *Interpreter) VisitSpawnExpr(e *expr.Spawn) interface{} {
+	callee := i.evaluate(e.Call.Callee)
+	arguments, named := i.evaluateArguments(e.Call)
+
+	function, ok := callableOf(callee)
+	if !ok {
+		panic(loxerr.NewKind(e.Call.Paren, loxerr.TypeError, "Can only call functions and classes."))
+	}
+
+	i.allocate(functionSize)
+
+	t := &Task{name: calleeName(function)}
+	i.scheduler.live++
+	i.scheduler.tasks = append(i.scheduler.tasks, t)
+
+	// The task has a stack of its own and shares the budget of the execution
+	forked := i.fork()
+	forked.budget = budget{ctx: i.budget.ctx, running: true}
+	go forked.runTask(t, function, arguments, named, e.Call.Paren)
+
+	return t
+}
+
+// Runs the call of a task on its goroutine, as a call made at the given location
+func (i *Interpreter) runTask(t *Task, function Callable, arguments []Value, named []namedArgument, location *token.Token) {
+	s := i.scheduler
+	s.lock(i)
+
+	defer func() {
+		switch r := recover().(type) {
+		case nil, taskExit:
+		case *LimitError:
+			t.failure = r
+			s.limit = r
+		default:
+			t.failure = r
+		}
+		t.done = true
+		s.live--
+		s.notify()
+		s.unlock(i)
+	}()
+
+	s.check()
+
+	defer i.enterCall()()
+
+	i.frames = []callFrame{{callee: function, site: location}}
+	result, err := i.invoke(function, arguments, named)
+	if err != nil {
+		raise(location, err)
+	}
+
+	t.result = result
+}
@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpreter_Tasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Join", source: "fun add(a, b) { return a + b; } var t = spawn add(1, 2); t.join();", expected: "3"},
+		{name: "Representation", source: "fun f() {} [spawn f(), Chan(), Chan(2)];", expected: "[<task f>, <channel 0/0>, <channel 0/2>]"},
+		{name: "Done", source: "fun f() {} var t = spawn f(); var before = t.done(); t.join(); [before, t.done()];", expected: "[false, true]"},
+		{name: "Arguments are evaluated by the spawner", source: "var n = 1; fun f(x) { return x; } var t = spawn f(n); n = 2; t.join();", expected: "1"},
+		{name: "Unbuffered channel", source: "var c = Chan(); fun send() { c.send(1); c.send(2); } spawn send(); [c.receive(), c.receive()];", expected: "[1, 2]"},
+		{name: "Buffered channel", source: "var c = Chan(2); c.send(1); c.send(2); [c.receive(), c.receive()];", expected: "[1, 2]"},
+		{name: "Receive from a closed channel", source: "var c = Chan(1); c.send(1); c.close(); [c.receive(), c.receive()];", expected: "[1, null]"},
+		{name: "Iterate a channel", source: "var c = Chan(); fun produce() { for (var n in 1..3) c.send(n); c.close(); } spawn produce(); var out = []; for (var n in c) out.push(n); out;", expected: "[1, 2, 3]"},
+		{name: "Channel iterator", source: collect + "var c = Chan(2); c.send(\"a\"); c.close(); collect(c);", expected: "[a]"},
+		{name: "Select", source: "var a = Chan(); var b = Chan(1); b.send(\"b\"); var [ch, v] = select([a, b]); [ch == b, v];", expected: "[true, b]"},
+		{name: "Select waits", source: "var a = Chan(); var b = Chan(); fun f() { b.send(1); } spawn f(); select([a, b])[1];", expected: "1"},
+		{name: "Select a closed channel", source: "var a = Chan(); a.close(); select([a]);", expected: "[<channel 0/0>, null]"},
+		{
+			name: "Workers",
+			source: "fun worker(jobs, results) { for (var job in jobs) results.send(job * job); } " +
+				"var jobs = Chan(10); var results = Chan(); " +
+				"for (var _ in 1..3) spawn worker(jobs, results); " +
+				"for (var n in 1..5) jobs.send(n); jobs.close(); " +
+				"var total = 0; for (var _ in 1..5) total += results.receive(); total;",
+			expected: "55",
+		},
+		{
+			name:     "Tasks take turns",
+			source:   "var log = []; fun count(name) { for (var n in 0..<1000) {} log.push(name); } var a = spawn count(\"a\"); var b = spawn count(\"b\"); a.join(); b.join(); log.length();",
+			expected: "2",
+		},
+		{name: "Shared state", source: "var n = 0; fun inc() { for (var _ in 0..<500) n++; } var ts = [spawn inc(), spawn inc()]; for (var t in ts) t.join(); n;", expected: "1000"},
+		{name: "Join rethrows", source: "fun f() { throw \"boom\"; } var t = spawn f(); var e; try { t.join(); } catch (error) { e = error; } e;", expected: "boom"},
+		{name: "Caught deadlock", source: "var e; try { Chan().receive(); } catch (error) { e = error.message; } e;", expected: "Deadlock, every task is blocked."},
+		{name: "Unfinished tasks are awaited", source: "var c = Chan(1); fun f() { c.send(1); } spawn f(); 1;", expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := New()
+			i.SetStdout(io.Discard)
+
+			actual, err := run(t, i, tt.source)
+			if err != nil {
+				t.Fatalf("Execute() returned an error: %v", err)
+			}
+
+			if Stringify(actual) != tt.expected {
+				t.Errorf("Execute() = %v, want %v", Stringify(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_TaskErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Deadlock", source: "var c = Chan();\nc.receive();", expectedErr: "[Pos 2:11] Error at ')': Deadlock, every task is blocked."},
+		{name: "Deadlock between tasks", source: "var a = Chan(); var b = Chan(); fun f() { a.receive(); b.send(1); } var t = spawn f(); b.receive();", expectedErr: "Deadlock, every task is blocked."},
+		{name: "Deadlock after the end", source: "var c = Chan(); fun f() { c.receive(); } spawn f();", expectedErr: "Deadlock, every task is blocked."},
+		{name: "Failure of an unjoined task", source: "fun f() { return 1 + null; } spawn f(); 1;", expectedErr: "Operands must be two numbers or two strings."},
+		{name: "Joined failure", source: "fun f() { throw \"boom\"; } var t = spawn f(); t.join();", expectedErr: "Uncaught boom"},
+		{name: "Spawning a non-callable", source: "var x = 1; spawn x();", expectedErr: "Can only call functions and classes."},
+		{name: "Arguments are checked by the task", source: "fun f(a) {} (spawn f()).join();", expectedErr: "Missing argument for parameter 'a' of f."},
+		{name: "Send on a closed channel", source: "var c = Chan(1); c.close(); c.send(1);", expectedErr: "Can't send on a closed channel."},
+		{name: "Close a closed channel", source: "var c = Chan(); c.close(); c.close();", expectedErr: "Can't close a closed channel."},
+		{name: "Negative capacity", source: "Chan(-1);", expectedErr: "Channel capacity must be a non-negative integer."},
+		{name: "Too many arguments", source: "Chan(1, 2);", expectedErr: "Expected 0 or 1 arguments but got 2."},
+		{name: "Select without channels", source: "select([1]);", expectedErr: "Expected a list of channels."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, New(), tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_TaskLimits(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// The tasks share the step budget of the execution, and exit once it fails
+	i := New()
+	i.SetLimits(Limits{MaxSteps: 10_000})
+
+	_, err := run(t, i, "fun spin() { while (true) {} } spawn spin(); spawn spin(); var c = Chan(); c.receive();")
+	if !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("Expected a step limit error but got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected %d goroutines after the execution but got %d", before, after)
+	}
+}
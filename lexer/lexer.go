@@ -49,59 +49,64 @@ func (l *Lexer) ScanTokens() {
 	})
 }
 
+// The tokens made of a single character that never starts a longer token
+var singleCharTokens = map[rune]token.Type{
+	'(': token.LEFT_PAREN,
+	')': token.RIGHT_PAREN,
+	'{': token.LEFT_BRACE,
+	'}': token.RIGHT_BRACE,
+	'[': token.LEFT_BRACKET,
+	']': token.RIGHT_BRACKET,
+	',': token.COMMA,
+	';': token.SEMICOLON,
+	'?': token.QUESTION,
+	':': token.COLON,
+	'&': token.AMPERSAND,
+	'|': token.PIPE,
+	'^': token.CARET,
+	'~': token.TILDE,
+}
+
 // scanToken processes a single token
-//
-//nolint:funlen // This function is long but it's mostly a switch statement
 func (l *Lexer) scanToken() {
 	c := l.advance()
 
+	if tokenType, ok := singleCharTokens[c]; ok {
+		l.addToken(tokenType, nil)
+		return
+	}
+
 	switch c {
-	case '(':
-		l.addToken(token.LEFT_PAREN, nil)
-	case ')':
-		l.addToken(token.RIGHT_PAREN, nil)
-	case '{':
-		l.addToken(token.LEFT_BRACE, nil)
-	case '}':
-		l.addToken(token.RIGHT_BRACE, nil)
-	case ',':
-		l.addToken(token.COMMA, nil)
-	case '.':
-		l.addToken(token.DOT, nil)
-	case '-':
-		l.addToken(token.MINUS, nil)
-	case '+':
-		l.addToken(token.PLUS, nil)
-	case ';':
-		l.addToken(token.SEMICOLON, nil)
-	case '*':
-		l.addToken(token.STAR, nil)
-	case '?':
-		l.addToken(token.QUESTION, nil)
-	case ':':
-		l.addToken(token.COLON, nil)
 	case ' ', '\r', '\t':
 		// Ignore whitespace
 	case '\n':
 		l.advanceLine()
 	case '/':
-		if l.match('/') {
+		if l.peek() == '/' && l.followsOperand() {
+			l.advance()
+			l.addToken(token.SLASH_SLASH, nil)
+		} else if l.match('/') {
 			l.lineComment()
 		} else if l.match('*') {
 			l.blockComment()
 		} else {
-			l.addToken(token.SLASH, nil)
+			l.addToken(l.matchToken('=', token.SLASH_EQUAL, token.SLASH), nil)
+		}
+	case '.':
+		switch {
+		case l.peek() == '.' && l.peekNext() == '.':
+			l.advance()
+			l.advance()
+			l.addToken(token.ELLIPSIS, nil)
+		case l.match('.'):
+			l.addToken(l.matchToken('<', token.DOT_DOT_LESS, token.DOT_DOT), nil)
+		default:
+			l.addToken(token.DOT, nil)
 		}
-	case '!':
-		l.addToken(l.matchToken('=', token.BANG_EQUAL, token.BANG), nil)
-	case '=':
-		l.addToken(l.matchToken('=', token.EQUAL_EQUAL, token.EQUAL), nil)
-	case '<':
-		l.addToken(l.matchToken('=', token.LESS_EQUAL, token.LESS), nil)
-	case '>':
-		l.addToken(l.matchToken('=', token.GREATER_EQUAL, token.GREATER), nil)
 	case '"':
 		l.processString()
+	case '-', '+', '*', '%', '!', '=', '<', '>':
+		l.processOperator(c)
 	default:
 		if l.isDigit(c) {
 			l.processNumber()
@@ -113,6 +118,53 @@ func (l *Lexer) scanToken() {
 	}
 }
 
+// Helper for handling the operators that are one of several tokens starting
+// with the same character
+func (l *Lexer) processOperator(c rune) {
+	switch c {
+	case '-':
+		if l.match('-') {
+			l.addToken(token.MINUS_MINUS, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.MINUS_EQUAL, token.MINUS), nil)
+		}
+	case '+':
+		if l.match('+') {
+			l.addToken(token.PLUS_PLUS, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.PLUS_EQUAL, token.PLUS), nil)
+		}
+	case '*':
+		if l.match('*') {
+			l.addToken(token.STAR_STAR, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.STAR_EQUAL, token.STAR), nil)
+		}
+	case '%':
+		l.addToken(l.matchToken('=', token.PERCENT_EQUAL, token.PERCENT), nil)
+	case '!':
+		l.addToken(l.matchToken('=', token.BANG_EQUAL, token.BANG), nil)
+	case '=':
+		if l.match('>') {
+			l.addToken(token.ARROW, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.EQUAL_EQUAL, token.EQUAL), nil)
+		}
+	case '<':
+		if l.match('<') {
+			l.addToken(token.LESS_LESS, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.LESS_EQUAL, token.LESS), nil)
+		}
+	case '>':
+		if l.match('>') {
+			l.addToken(token.GREATER_GREATER, nil)
+		} else {
+			l.addToken(l.matchToken('=', token.GREATER_EQUAL, token.GREATER), nil)
+		}
+	}
+}
+
 // Helper for handling strings
 func (l *Lexer) processString() {
 	for l.peek() != '"' && !l.isAtEnd() {
@@ -195,6 +247,29 @@ func (l *Lexer) lineComment() {
 	}
 }
 
+// Reports whether the previous token ends an operand on the current line. A "//"
+// following an operand is the floor division operator, anywhere else it starts a
+// line comment. This keeps "a // b" a division while "x = 1; // note" and a
+// comment on its own line stay comments
+func (l *Lexer) followsOperand() bool {
+	if len(l.Tokens) == 0 {
+		return false
+	}
+
+	previous := l.Tokens[len(l.Tokens)-1]
+	if previous.Line != l.line {
+		return false
+	}
+
+	switch previous.Type {
+	case token.NUMBER, token.STRING, token.IDENTIFIER, token.RIGHT_PAREN, token.RIGHT_BRACKET,
+		token.TRUE, token.FALSE, token.NULL, token.THIS:
+		return true
+	}
+
+	return false
+}
+
 // Adds a token to the list
 func (l *Lexer) addToken(tokenType token.Type, literal interface{}) {
 	text := l.source[l.start:l.current]
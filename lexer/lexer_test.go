@@ -32,13 +32,115 @@ func TestScanTokens_Characters(t *testing.T) {
 				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 24},
 			},
 		},
+		{
+			name:  "Brackets",
+			input: "[1]",
+			expectedTokens: []token.Token{
+				{Type: token.LEFT_BRACKET, Lexeme: "[", Literal: nil, Line: 1, Column: 1},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0, Line: 1, Column: 2},
+				{Type: token.RIGHT_BRACKET, Lexeme: "]", Literal: nil, Line: 1, Column: 3},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 4},
+			},
+		},
+		{
+			name:  "Compound assignment and update operators",
+			input: "+= -= *= /= %= ++ -- %",
+			expectedTokens: []token.Token{
+				{Type: token.PLUS_EQUAL, Lexeme: "+=", Literal: nil, Line: 1, Column: 1},
+				{Type: token.MINUS_EQUAL, Lexeme: "-=", Literal: nil, Line: 1, Column: 4},
+				{Type: token.STAR_EQUAL, Lexeme: "*=", Literal: nil, Line: 1, Column: 7},
+				{Type: token.SLASH_EQUAL, Lexeme: "/=", Literal: nil, Line: 1, Column: 10},
+				{Type: token.PERCENT_EQUAL, Lexeme: "%=", Literal: nil, Line: 1, Column: 13},
+				{Type: token.PLUS_PLUS, Lexeme: "++", Literal: nil, Line: 1, Column: 16},
+				{Type: token.MINUS_MINUS, Lexeme: "--", Literal: nil, Line: 1, Column: 19},
+				{Type: token.PERCENT, Lexeme: "%", Literal: nil, Line: 1, Column: 22},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 23},
+			},
+		},
+		{
+			name:  "Arithmetic and bitwise operators",
+			input: "a // b ** c & d | e ^ ~f << g >> h",
+			expectedTokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "a", Literal: nil, Line: 1, Column: 1},
+				{Type: token.SLASH_SLASH, Lexeme: "//", Literal: nil, Line: 1, Column: 3},
+				{Type: token.IDENTIFIER, Lexeme: "b", Literal: nil, Line: 1, Column: 6},
+				{Type: token.STAR_STAR, Lexeme: "**", Literal: nil, Line: 1, Column: 8},
+				{Type: token.IDENTIFIER, Lexeme: "c", Literal: nil, Line: 1, Column: 11},
+				{Type: token.AMPERSAND, Lexeme: "&", Literal: nil, Line: 1, Column: 13},
+				{Type: token.IDENTIFIER, Lexeme: "d", Literal: nil, Line: 1, Column: 15},
+				{Type: token.PIPE, Lexeme: "|", Literal: nil, Line: 1, Column: 17},
+				{Type: token.IDENTIFIER, Lexeme: "e", Literal: nil, Line: 1, Column: 19},
+				{Type: token.CARET, Lexeme: "^", Literal: nil, Line: 1, Column: 21},
+				{Type: token.TILDE, Lexeme: "~", Literal: nil, Line: 1, Column: 23},
+				{Type: token.IDENTIFIER, Lexeme: "f", Literal: nil, Line: 1, Column: 24},
+				{Type: token.LESS_LESS, Lexeme: "<<", Literal: nil, Line: 1, Column: 26},
+				{Type: token.IDENTIFIER, Lexeme: "g", Literal: nil, Line: 1, Column: 29},
+				{Type: token.GREATER_GREATER, Lexeme: ">>", Literal: nil, Line: 1, Column: 31},
+				{Type: token.IDENTIFIER, Lexeme: "h", Literal: nil, Line: 1, Column: 34},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 35},
+			},
+		},
+		{
+			name:  "Floor division and comments",
+			input: "7 // 2; // comment\n// own line\n(1) // 2\n~/* note */ 1",
+			expectedTokens: []token.Token{
+				{Type: token.NUMBER, Lexeme: "7", Literal: 7.0, Line: 1, Column: 1},
+				{Type: token.SLASH_SLASH, Lexeme: "//", Literal: nil, Line: 1, Column: 3},
+				{Type: token.NUMBER, Lexeme: "2", Literal: 2.0, Line: 1, Column: 6},
+				{Type: token.SEMICOLON, Lexeme: ";", Literal: nil, Line: 1, Column: 7},
+				{Type: token.LEFT_PAREN, Lexeme: "(", Literal: nil, Line: 3, Column: 1},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0, Line: 3, Column: 2},
+				{Type: token.RIGHT_PAREN, Lexeme: ")", Literal: nil, Line: 3, Column: 3},
+				{Type: token.SLASH_SLASH, Lexeme: "//", Literal: nil, Line: 3, Column: 5},
+				{Type: token.NUMBER, Lexeme: "2", Literal: 2.0, Line: 3, Column: 8},
+				{Type: token.TILDE, Lexeme: "~", Literal: nil, Line: 4, Column: 1},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0, Line: 4, Column: 13},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 4, Column: 14},
+			},
+		},
+		{
+			name:  "Arrow",
+			input: "(a) => a",
+			expectedTokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "(", Literal: nil, Line: 1, Column: 1},
+				{Type: token.IDENTIFIER, Lexeme: "a", Literal: nil, Line: 1, Column: 2},
+				{Type: token.RIGHT_PAREN, Lexeme: ")", Literal: nil, Line: 1, Column: 3},
+				{Type: token.ARROW, Lexeme: "=>", Literal: nil, Line: 1, Column: 5},
+				{Type: token.IDENTIFIER, Lexeme: "a", Literal: nil, Line: 1, Column: 8},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 9},
+			},
+		},
+		{
+			name:  "Rest parameter",
+			input: "(...xs)",
+			expectedTokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "(", Literal: nil, Line: 1, Column: 1},
+				{Type: token.ELLIPSIS, Lexeme: "...", Literal: nil, Line: 1, Column: 2},
+				{Type: token.IDENTIFIER, Lexeme: "xs", Literal: nil, Line: 1, Column: 5},
+				{Type: token.RIGHT_PAREN, Lexeme: ")", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:  "Ranges",
+			input: "0..10 1..<n",
+			expectedTokens: []token.Token{
+				{Type: token.NUMBER, Lexeme: "0", Literal: 0.0, Line: 1, Column: 1},
+				{Type: token.DOT_DOT, Lexeme: "..", Literal: nil, Line: 1, Column: 2},
+				{Type: token.NUMBER, Lexeme: "10", Literal: 10.0, Line: 1, Column: 4},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0, Line: 1, Column: 7},
+				{Type: token.DOT_DOT_LESS, Lexeme: "..<", Literal: nil, Line: 1, Column: 8},
+				{Type: token.IDENTIFIER, Lexeme: "n", Literal: nil, Line: 1, Column: 11},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 12},
+			},
+		},
 		{
 			name:  "Unrecognized characters",
-			input: "@#^",
+			input: "@#$",
 			expectedTokens: []token.Token{
 				{Type: token.ILLEGAL, Lexeme: "@", Literal: nil, Line: 1, Column: 1},
 				{Type: token.ILLEGAL, Lexeme: "#", Literal: nil, Line: 1, Column: 2},
-				{Type: token.ILLEGAL, Lexeme: "^", Literal: nil, Line: 1, Column: 3},
+				{Type: token.ILLEGAL, Lexeme: "$", Literal: nil, Line: 1, Column: 3},
 				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 4},
 			},
 		},
@@ -197,6 +299,96 @@ func TestScanTokens_Literals(t *testing.T) {
 				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 79},
 			},
 		},
+		{
+			name:  "IDENTIFIER: Loop control keywords",
+			input: "break continue",
+			expectedTokens: []token.Token{
+				{Type: token.BREAK, Lexeme: "break", Literal: nil, Line: 1, Column: 1},
+				{Type: token.CONTINUE, Lexeme: "continue", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 15},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Exception keywords",
+			input: "throw try catch finally",
+			expectedTokens: []token.Token{
+				{Type: token.THROW, Lexeme: "throw", Literal: nil, Line: 1, Column: 1},
+				{Type: token.TRY, Lexeme: "try", Literal: nil, Line: 1, Column: 7},
+				{Type: token.CATCH, Lexeme: "catch", Literal: nil, Line: 1, Column: 11},
+				{Type: token.FINALLY, Lexeme: "finally", Literal: nil, Line: 1, Column: 17},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 24},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Module keywords",
+			input: "import from as",
+			expectedTokens: []token.Token{
+				{Type: token.IMPORT, Lexeme: "import", Literal: nil, Line: 1, Column: 1},
+				{Type: token.FROM, Lexeme: "from", Literal: nil, Line: 1, Column: 8},
+				{Type: token.AS, Lexeme: "as", Literal: nil, Line: 1, Column: 13},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 15},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Trait keywords",
+			input: "trait with",
+			expectedTokens: []token.Token{
+				{Type: token.TRAIT, Lexeme: "trait", Literal: nil, Line: 1, Column: 1},
+				{Type: token.WITH, Lexeme: "with", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 11},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Match keyword",
+			input: "match _",
+			expectedTokens: []token.Token{
+				{Type: token.MATCH, Lexeme: "match", Literal: nil, Line: 1, Column: 1},
+				{Type: token.IDENTIFIER, Lexeme: "_", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Spawn keyword",
+			input: "spawn",
+			expectedTokens: []token.Token{
+				{Type: token.SPAWN, Lexeme: "spawn", Literal: nil, Line: 1, Column: 1},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 6},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Async keywords",
+			input: "async await",
+			expectedTokens: []token.Token{
+				{Type: token.ASYNC, Lexeme: "async", Literal: nil, Line: 1, Column: 1},
+				{Type: token.AWAIT, Lexeme: "await", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 12},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Constant keywords",
+			input: "const let",
+			expectedTokens: []token.Token{
+				{Type: token.CONST, Lexeme: "const", Literal: nil, Line: 1, Column: 1},
+				{Type: token.LET, Lexeme: "let", Literal: nil, Line: 1, Column: 7},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 10},
+			},
+		},
+		{
+			name:  "IDENTIFIER: In keyword",
+			input: "in",
+			expectedTokens: []token.Token{
+				{Type: token.IN, Lexeme: "in", Literal: nil, Line: 1, Column: 1},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 3},
+			},
+		},
+		{
+			name:  "IDENTIFIER: Yield keyword",
+			input: "yield",
+			expectedTokens: []token.Token{
+				{Type: token.YIELD, Lexeme: "yield", Literal: nil, Line: 1, Column: 1},
+				{Type: token.EOF, Lexeme: "", Literal: nil, Line: 1, Column: 6},
+			},
+		},
 	}
 
 	for _, tt := range tests {
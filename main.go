@@ -3,17 +3,44 @@ Go Lox programming language is a simple programming language that is
 following the book "Crafting Interpreters" by Bob Nystrom. This is a
 learning project for me to understand how interpreters work and how to
 write one.
+
+Without arguments, golox starts the REPL. "golox check FILE..." type checks
+the files without running them, and exits with status 1 if any has errors.
 */
 package main
 
 import (
 	"fmt"
+	"golox/golox"
 	"golox/repl"
 	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(check(os.Args[2:]))
+	}
+
 	fmt.Println("Welcome to GoLox!\n Feel free to type in commands")
 
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+// Type checks the files, writing their errors to the standard error. Returns
+// the exit status of the command
+func check(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: golox check FILE...")
+		return 2
+	}
+
+	status := 0
+	for _, path := range paths {
+		if err := golox.CheckFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s:\n%v\n", path, err)
+			status = 1
+		}
+	}
+
+	return status
+}
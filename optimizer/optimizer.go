@@ -0,0 +1,675 @@
+/*
+Package optimizer implements an optimization pass over the GoLox AST.
+
+The optimizer walks the expression and statement trees and rewrites them into
+equivalent but cheaper trees before they are handed to the interpreter:
+
+  - constant arithmetic, string concatenation and comparisons are folded into literals
+  - logical operators with a constant left operand are short-circuited
+  - ternaries with a constant condition are replaced with the selected branch
+  - if and while statements with a constant condition have their dead branches removed
+  - statements following a return, throw, break or continue are removed
+
+Only expressions where every operand is a literal are folded. Expressions that
+would fail at runtime (for example adding a number to a string) are left as is,
+so that the interpreter can still report the error at the right position.
+
+The pass runs after the resolver. Embedding applications enable it for the
+sources evaluated by a VM with the Optimize option of package golox.
+*/
+package optimizer
+
+import (
+	"fmt"
+	"golox/expr"
+	"golox/interpreter"
+	"golox/printer"
+	"golox/stmt"
+	"golox/token"
+	"io"
+	"math"
+)
+
+// Optimizer is a visitor that rewrites the AST into an optimized form
+type Optimizer struct {
+	trace   io.Writer // Destination for the before/after output, nil when tracing is disabled
+	printer *printer.AstPrinter
+}
+
+// New creates a new Optimizer
+func New() *Optimizer {
+	return &Optimizer{printer: printer.New()}
+}
+
+// WithTrace makes the optimizer print every rewritten expression before and after
+// the optimization, and every statement it eliminates, to the given writer
+func (o *Optimizer) WithTrace(w io.Writer) *Optimizer {
+	o.trace = w
+	return o
+}
+
+// Optimize the given expression
+func (o *Optimizer) Optimize(e expr.Expr) expr.Expr {
+	if e == nil {
+		return nil
+	}
+
+	optimized := o.optimizeExpr(e)
+	o.traceExpr(e, optimized)
+
+	return optimized
+}
+
+// OptimizeStatements optimizes the given statements. Statements that can never be
+// executed are removed from the result
+func (o *Optimizer) OptimizeStatements(statements []stmt.Stmt) []stmt.Stmt {
+	optimized := make([]stmt.Stmt, 0, len(statements))
+
+	for idx, s := range statements {
+		if s = o.optimizeStmt(s); s != nil {
+			optimized = append(optimized, s)
+		}
+
+		if keyword := jumpKeyword(s); keyword != nil && idx+1 < len(statements) {
+			o.tracef("removed: %d statement(s) after %s at line %d", len(statements)-idx-1, keyword.Lexeme, keyword.Line)
+			break
+		}
+	}
+
+	return optimized
+}
+
+// VisitAssignExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitAssignExpr(e *expr.Assign) interface{} {
+	return &expr.Assign{Name: e.Name, Value: o.optimizeExpr(e.Value)}
+}
+
+// VisitBinaryExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitBinaryExpr(e *expr.Binary) interface{} {
+	left := o.optimizeExpr(e.Left)
+	right := o.optimizeExpr(e.Right)
+
+	l, lok := left.(*expr.Literal)
+	r, rok := right.(*expr.Literal)
+	if lok && rok {
+		if value, ok := foldBinary(e.Operator, l.Value, r.Value); ok {
+			return &expr.Literal{Value: value}
+		}
+	}
+
+	return &expr.Binary{Left: left, Operator: e.Operator, Right: right}
+}
+
+// VisitCallExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitCallExpr(e *expr.Call) interface{} {
+	arguments := make([]expr.Expr, len(e.Arguments))
+	for idx, argument := range e.Arguments {
+		arguments[idx] = o.optimizeExpr(argument)
+	}
+
+	var named []*expr.NamedArgument
+	for _, argument := range e.Named {
+		named = append(named, &expr.NamedArgument{Name: argument.Name, Value: o.optimizeExpr(argument.Value)})
+	}
+
+	return &expr.Call{Callee: o.optimizeExpr(e.Callee), Paren: e.Paren, Arguments: arguments, Named: named}
+}
+
+// VisitGetExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitGetExpr(e *expr.Get) interface{} {
+	return &expr.Get{Object: o.optimizeExpr(e.Object), Name: e.Name}
+}
+
+// VisitGroupingExpr implements the expr.Visitor interface
+//
+// Groupings only matter for the parser, so a grouped literal is unwrapped
+func (o *Optimizer) VisitGroupingExpr(e *expr.Grouping) interface{} {
+	expression := o.optimizeExpr(e.Expression)
+
+	if literal, ok := expression.(*expr.Literal); ok {
+		return literal
+	}
+
+	return &expr.Grouping{Expression: expression}
+}
+
+// VisitLiteralExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitLiteralExpr(e *expr.Literal) interface{} {
+	return e
+}
+
+// VisitLogicalExpr implements the expr.Visitor interface
+//
+// Logical operators return one of their operands, so a constant left operand
+// decides which one without evaluating anything else
+func (o *Optimizer) VisitLogicalExpr(e *expr.Logical) interface{} {
+	left := o.optimizeExpr(e.Left)
+	right := o.optimizeExpr(e.Right)
+
+	if l, ok := left.(*expr.Literal); ok {
+		if e.Operator.Type == token.OR {
+			if isTruthy(l.Value) {
+				return left
+			}
+			return right
+		}
+
+		if !isTruthy(l.Value) {
+			return left
+		}
+		return right
+	}
+
+	return &expr.Logical{Left: left, Operator: e.Operator, Right: right}
+}
+
+// VisitSetExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitSetExpr(e *expr.Set) interface{} {
+	return &expr.Set{Object: o.optimizeExpr(e.Object), Name: e.Name, Value: o.optimizeExpr(e.Value)}
+}
+
+// VisitSuperExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitSuperExpr(e *expr.Super) interface{} {
+	return e
+}
+
+// VisitThisExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitThisExpr(e *expr.This) interface{} {
+	return e
+}
+
+// VisitUnaryExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitUnaryExpr(e *expr.Unary) interface{} {
+	right := o.optimizeExpr(e.Right)
+
+	if r, ok := right.(*expr.Literal); ok {
+		switch e.Operator.Type {
+		case token.BANG:
+			return &expr.Literal{Value: !isTruthy(r.Value)}
+		case token.MINUS:
+			if n, ok := r.Value.(float64); ok {
+				return &expr.Literal{Value: -n}
+			}
+		}
+	}
+
+	return &expr.Unary{Operator: e.Operator, Right: right}
+}
+
+// VisitVariableExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitVariableExpr(e *expr.Variable) interface{} {
+	return e
+}
+
+// VisitTernaryExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitTernaryExpr(e *expr.Ternary) interface{} {
+	condition := o.optimizeExpr(e.Condition)
+	trueBranch := o.optimizeExpr(e.TrueBranch)
+	falseBranch := o.optimizeExpr(e.FalseBranch)
+
+	if c, ok := condition.(*expr.Literal); ok {
+		if isTruthy(c.Value) {
+			return trueBranch
+		}
+		return falseBranch
+	}
+
+	return &expr.Ternary{Condition: condition, TrueBranch: trueBranch, FalseBranch: falseBranch}
+}
+
+// VisitListExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitListExpr(e *expr.List) interface{} {
+	elements := make([]expr.Expr, len(e.Elements))
+	for idx, element := range e.Elements {
+		elements[idx] = o.optimizeExpr(element)
+	}
+
+	return &expr.List{Elements: elements}
+}
+
+// VisitIndexExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitIndexExpr(e *expr.Index) interface{} {
+	return &expr.Index{Object: o.optimizeExpr(e.Object), Bracket: e.Bracket, Index: o.optimizeExpr(e.Index)}
+}
+
+// VisitSetIndexExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitSetIndexExpr(e *expr.SetIndex) interface{} {
+	return &expr.SetIndex{
+		Object:  o.optimizeExpr(e.Object),
+		Bracket: e.Bracket,
+		Index:   o.optimizeExpr(e.Index),
+		Value:   o.optimizeExpr(e.Value),
+	}
+}
+
+// VisitSliceExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitSliceExpr(e *expr.Slice) interface{} {
+	return &expr.Slice{
+		Object:  o.optimizeExpr(e.Object),
+		Bracket: e.Bracket,
+		Start:   o.optimizeExpr(e.Start),
+		End:     o.optimizeExpr(e.End),
+	}
+}
+
+// VisitMapExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitMapExpr(e *expr.Map) interface{} {
+	keys := make([]expr.Expr, len(e.Keys))
+	values := make([]expr.Expr, len(e.Values))
+	for idx := range e.Keys {
+		keys[idx] = o.optimizeExpr(e.Keys[idx])
+		values[idx] = o.optimizeExpr(e.Values[idx])
+	}
+
+	return &expr.Map{Brace: e.Brace, Keys: keys, Values: values}
+}
+
+// VisitCompoundAssignExpr implements the expr.Visitor interface
+//
+// The optimized target stays a variable, property or index expression, as those
+// are never folded into literals
+func (o *Optimizer) VisitCompoundAssignExpr(e *expr.CompoundAssign) interface{} {
+	return &expr.CompoundAssign{Target: o.optimizeExpr(e.Target), Operator: e.Operator, Value: o.optimizeExpr(e.Value)}
+}
+
+// VisitUpdateExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitUpdateExpr(e *expr.Update) interface{} {
+	return &expr.Update{Target: o.optimizeExpr(e.Target), Operator: e.Operator, Prefix: e.Prefix}
+}
+
+// VisitLambdaExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitLambdaExpr(e *expr.Lambda) interface{} {
+	return &expr.Lambda{Keyword: e.Keyword, Params: o.optimizeParams(e.Params), Body: &stmt.Block{Statements: o.OptimizeStatements(stmt.Statements(e.Body))}, Generator: e.Generator, Async: e.Async, ReturnType: e.ReturnType}
+}
+
+// VisitMatchExpr implements the expr.Visitor interface
+//
+// Patterns hold no expressions to fold, only the guards and bodies are optimized
+func (o *Optimizer) VisitMatchExpr(e *expr.Match) interface{} {
+	arms := make([]*expr.MatchArm, len(e.Arms))
+	for idx, arm := range e.Arms {
+		optimized := &expr.MatchArm{Pattern: arm.Pattern, Guard: o.optimizeExpr(arm.Guard)}
+		if arm.Block != nil {
+			optimized.Block = &stmt.Block{Statements: o.OptimizeStatements(stmt.Statements(arm.Block))}
+		} else {
+			optimized.Body = o.optimizeExpr(arm.Body)
+		}
+		arms[idx] = optimized
+	}
+
+	return &expr.Match{Keyword: e.Keyword, Subject: o.optimizeExpr(e.Subject), Arms: arms}
+}
+
+// VisitDestructureExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitDestructureExpr(e *expr.Destructure) interface{} {
+	return &expr.Destructure{Pattern: o.optimizeTargets(e.Pattern), Equals: e.Equals, Value: o.optimizeExpr(e.Value)}
+}
+
+// VisitSpawnExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitSpawnExpr(e *expr.Spawn) interface{} {
+	return &expr.Spawn{Keyword: e.Keyword, Call: o.VisitCallExpr(e.Call).(*expr.Call)}
+}
+
+// VisitAwaitExpr implements the expr.Visitor interface
+func (o *Optimizer) VisitAwaitExpr(e *expr.Await) interface{} {
+	return &expr.Await{Keyword: e.Keyword, Value: o.optimizeExpr(e.Value)}
+}
+
+// Optimizes the objects and indexes of the targets of a destructuring assignment
+func (o *Optimizer) optimizeTargets(pattern *expr.ListPattern) *expr.ListPattern {
+	elements := make([]expr.Pattern, len(pattern.Elements))
+	for idx, element := range pattern.Elements {
+		switch e := element.(type) {
+		case *expr.TargetPattern:
+			elements[idx] = &expr.TargetPattern{Target: o.optimizeExpr(e.Target)}
+		case *expr.ListPattern:
+			elements[idx] = o.optimizeTargets(e)
+		default:
+			elements[idx] = element
+		}
+	}
+
+	return &expr.ListPattern{Bracket: pattern.Bracket, Elements: elements, Rest: pattern.Rest}
+}
+
+// Optimizes the default values of the parameters
+func (o *Optimizer) optimizeParams(params []*expr.Param) []*expr.Param {
+	optimized := make([]*expr.Param, len(params))
+	for idx, param := range params {
+		optimized[idx] = &expr.Param{Name: param.Name, Type: param.Type, Default: o.optimizeExpr(param.Default), Rest: param.Rest}
+	}
+
+	return optimized
+}
+
+// VisitBlockStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitBlockStmt(s *stmt.Block) interface{} {
+	return &stmt.Block{Statements: o.OptimizeStatements(s.Statements)}
+}
+
+// VisitBreakStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitBreakStmt(s *stmt.Break) interface{} {
+	return s
+}
+
+// VisitClassStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitClassStmt(s *stmt.Class) interface{} {
+	return &stmt.Class{Name: s.Name, Superclass: s.Superclass, Traits: s.Traits, Members: o.optimizeMembers(s.Members)}
+}
+
+// VisitTraitStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitTraitStmt(s *stmt.Trait) interface{} {
+	return &stmt.Trait{Name: s.Name, Members: o.optimizeMembers(s.Members)}
+}
+
+func (o *Optimizer) optimizeMembers(members stmt.Members) stmt.Members {
+	return stmt.Members{
+		Methods:       o.optimizeMethods(members.Methods),
+		StaticMethods: o.optimizeMethods(members.StaticMethods),
+		Getters:       o.optimizeMethods(members.Getters),
+		Setters:       o.optimizeMethods(members.Setters),
+		Fields:        members.Fields,
+	}
+}
+
+func (o *Optimizer) optimizeMethods(methods []*stmt.Function) []*stmt.Function {
+	optimized := make([]*stmt.Function, len(methods))
+	for idx, method := range methods {
+		optimized[idx] = o.optimizeStmt(method).(*stmt.Function)
+	}
+
+	return optimized
+}
+
+// VisitContinueStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitContinueStmt(s *stmt.Continue) interface{} {
+	return s
+}
+
+// VisitExpressionStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitExpressionStmt(s *stmt.Expression) interface{} {
+	return &stmt.Expression{Expression: o.Optimize(s.Expression)}
+}
+
+// VisitForInStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitForInStmt(s *stmt.ForIn) interface{} {
+	return &stmt.ForIn{
+		Keyword:  s.Keyword,
+		Key:      s.Key,
+		Name:     s.Name,
+		Iterable: o.Optimize(s.Iterable),
+		Body:     o.optimizeBranch(s.Body),
+		Constant: s.Constant,
+	}
+}
+
+// VisitFunctionStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitFunctionStmt(s *stmt.Function) interface{} {
+	return &stmt.Function{Name: s.Name, Params: o.optimizeParams(s.Params), Body: o.OptimizeStatements(s.Body), Generator: s.Generator, Async: s.Async, ReturnType: s.ReturnType}
+}
+
+// VisitIfStmt implements the stmt.Visitor interface
+//
+// An if statement with a constant condition is replaced with the branch that
+// would be taken, or removed completely if there is no such branch
+func (o *Optimizer) VisitIfStmt(s *stmt.If) interface{} {
+	condition := o.Optimize(s.Condition)
+
+	if c, ok := condition.(*expr.Literal); ok {
+		if isTruthy(c.Value) {
+			if s.ElseBranch != nil {
+				o.traceBranch("else branch of if", s.Condition)
+			}
+			return o.optimizeStmt(s.ThenBranch)
+		}
+		o.traceBranch("then branch of if", s.Condition)
+		return o.optimizeStmt(s.ElseBranch)
+	}
+
+	return &stmt.If{
+		Condition:  condition,
+		ThenBranch: o.optimizeBranch(s.ThenBranch),
+		ElseBranch: o.optimizeStmt(s.ElseBranch),
+	}
+}
+
+// VisitImportStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitImportStmt(s *stmt.Import) interface{} {
+	return s
+}
+
+// VisitPrintStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitPrintStmt(s *stmt.Print) interface{} {
+	return &stmt.Print{Expression: o.Optimize(s.Expression)}
+}
+
+// VisitReturnStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitReturnStmt(s *stmt.Return) interface{} {
+	return &stmt.Return{Keyword: s.Keyword, Value: o.Optimize(s.Value)}
+}
+
+// VisitThrowStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitThrowStmt(s *stmt.Throw) interface{} {
+	return &stmt.Throw{Keyword: s.Keyword, Value: o.Optimize(s.Value)}
+}
+
+// VisitTryStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitTryStmt(s *stmt.Try) interface{} {
+	try := &stmt.Try{Keyword: s.Keyword, Body: o.OptimizeStatements(s.Body), CatchName: s.CatchName}
+
+	if s.CatchName != nil {
+		try.Catch = o.OptimizeStatements(s.Catch)
+	}
+
+	if s.Finally != nil {
+		try.Finally = o.OptimizeStatements(s.Finally)
+	}
+
+	return try
+}
+
+// VisitVarStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitVarStmt(s *stmt.Var) interface{} {
+	return &stmt.Var{Name: s.Name, Type: s.Type, Pattern: s.Pattern, Initializer: o.Optimize(s.Initializer), Constant: s.Constant}
+}
+
+// VisitWhileStmt implements the stmt.Visitor interface
+//
+// A while loop whose condition is constantly falsy never runs, so it is removed
+func (o *Optimizer) VisitWhileStmt(s *stmt.While) interface{} {
+	condition := o.Optimize(s.Condition)
+
+	if c, ok := condition.(*expr.Literal); ok && !isTruthy(c.Value) {
+		o.traceBranch("while", s.Condition)
+		return nil
+	}
+
+	return &stmt.While{Condition: condition, Body: o.optimizeBranch(s.Body), Increment: o.Optimize(s.Increment)}
+}
+
+// VisitYieldStmt implements the stmt.Visitor interface
+func (o *Optimizer) VisitYieldStmt(s *stmt.Yield) interface{} {
+	return &stmt.Yield{Keyword: s.Keyword, Value: o.Optimize(s.Value)}
+}
+
+func (o *Optimizer) optimizeExpr(e expr.Expr) expr.Expr {
+	if e == nil {
+		return nil
+	}
+
+	return e.Accept(o).(expr.Expr)
+}
+
+func (o *Optimizer) optimizeStmt(s stmt.Stmt) stmt.Stmt {
+	if s == nil {
+		return nil
+	}
+
+	optimized, _ := s.Accept(o).(stmt.Stmt)
+	return optimized
+}
+
+// Optimizes a statement that is required by its parent, replacing a removed
+// statement with an empty block
+func (o *Optimizer) optimizeBranch(s stmt.Stmt) stmt.Stmt {
+	if optimized := o.optimizeStmt(s); optimized != nil {
+		return optimized
+	}
+
+	return &stmt.Block{}
+}
+
+func (o *Optimizer) traceExpr(before, after expr.Expr) {
+	if o.trace == nil {
+		return
+	}
+
+	b, a := o.printer.Print(before), o.printer.Print(after)
+	if b == a {
+		return
+	}
+
+	o.tracef("before: %s\nafter:  %s", b, a)
+}
+
+// Records a branch that is removed because of its constant condition
+func (o *Optimizer) traceBranch(branch string, condition expr.Expr) {
+	if o.trace == nil {
+		return
+	}
+
+	o.tracef("removed: %s %s", branch, o.printer.Print(condition))
+}
+
+func (o *Optimizer) tracef(format string, args ...interface{}) {
+	if o.trace == nil {
+		return
+	}
+
+	//nolint:errcheck // Tracing is best effort
+	fmt.Fprintf(o.trace, format+"\n", args...)
+}
+
+// Returns the keyword of a statement that unconditionally leaves the current
+// block, or nil when the statements following it may run
+func jumpKeyword(s stmt.Stmt) *token.Token {
+	switch s := s.(type) {
+	case *stmt.Return:
+		return s.Keyword
+	case *stmt.Throw:
+		return s.Keyword
+	case *stmt.Break:
+		return s.Keyword
+	case *stmt.Continue:
+		return s.Keyword
+	}
+
+	return nil
+}
+
+// Folds a binary expression with two literal operands. The second return value
+// reports whether the expression could be folded
+func foldBinary(operator *token.Token, left, right interface{}) (interface{}, bool) {
+	switch operator.Type {
+	case token.EQUAL_EQUAL:
+		return isEqual(left, right), true
+	case token.BANG_EQUAL:
+		return !isEqual(left, right), true
+	case token.PLUS:
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, true
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, false
+	}
+
+	switch operator.Type {
+	case token.PLUS:
+		return l + r, true
+	case token.MINUS:
+		return l - r, true
+	case token.STAR:
+		return l * r, true
+	case token.SLASH:
+		return l / r, true
+	case token.SLASH_SLASH:
+		return math.Floor(l / r), true
+	case token.PERCENT:
+		return interpreter.FloorMod(l, r), true
+	case token.STAR_STAR:
+		return math.Pow(l, r), true
+	case token.GREATER:
+		return l > r, true
+	case token.GREATER_EQUAL:
+		return l >= r, true
+	case token.LESS:
+		return l < r, true
+	case token.LESS_EQUAL:
+		return l <= r, true
+	}
+
+	return foldBitwise(operator, l, r)
+}
+
+// Folds a bitwise operator. Operands that are not integers, and negative shift
+// counts, are left for the interpreter to report
+func foldBitwise(operator *token.Token, left, right float64) (interface{}, bool) {
+	isInteger := func(n float64) bool {
+		return n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64
+	}
+	if !isInteger(left) || !isInteger(right) {
+		return nil, false
+	}
+
+	l, r := int64(left), int64(right)
+
+	switch operator.Type {
+	case token.AMPERSAND:
+		return float64(l & r), true
+	case token.PIPE:
+		return float64(l | r), true
+	case token.CARET:
+		return float64(l ^ r), true
+	case token.LESS_LESS:
+		if r >= 0 {
+			return float64(l << r), true
+		}
+	case token.GREATER_GREATER:
+		if r >= 0 {
+			return float64(l >> r), true
+		}
+	}
+
+	return nil, false
+}
+
+// Mirrors the truthiness rules of the interpreter: nil and false are false,
+// everything else is true
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+func isEqual(a, b interface{}) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil {
+		return false
+	}
+
+	return a == b
+}
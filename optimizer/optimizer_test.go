@@ -0,0 +1,313 @@
+package optimizer
+
+import (
+	"bytes"
+	"golox/expr"
+	"golox/printer"
+	"golox/stmt"
+	"golox/token"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizer_Expressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     expr.Expr
+		expected string
+	}{
+		{
+			name: "Constant arithmetic (60 * 60 * 24)",
+			expr: &expr.Binary{
+				Left: &expr.Binary{
+					Left:     &expr.Literal{Value: 60.0},
+					Operator: &token.Token{Type: token.STAR, Lexeme: "*"},
+					Right:    &expr.Literal{Value: 60.0},
+				},
+				Operator: &token.Token{Type: token.STAR, Lexeme: "*"},
+				Right:    &expr.Literal{Value: 24.0},
+			},
+			expected: "86400",
+		},
+		{
+			name: "Power, floor division and modulo (2 ** 10 // 3 % 5)",
+			expr: &expr.Binary{
+				Left: &expr.Binary{
+					Left: &expr.Binary{
+						Left:     &expr.Literal{Value: 2.0},
+						Operator: &token.Token{Type: token.STAR_STAR, Lexeme: "**"},
+						Right:    &expr.Literal{Value: 10.0},
+					},
+					Operator: &token.Token{Type: token.SLASH_SLASH, Lexeme: "//"},
+					Right:    &expr.Literal{Value: 3.0},
+				},
+				Operator: &token.Token{Type: token.PERCENT, Lexeme: "%"},
+				Right:    &expr.Literal{Value: 5.0},
+			},
+			expected: "1",
+		},
+		{
+			name: "Bitwise operators (6 & 3 | 1 << 4)",
+			expr: &expr.Binary{
+				Left: &expr.Binary{
+					Left:     &expr.Literal{Value: 6.0},
+					Operator: &token.Token{Type: token.AMPERSAND, Lexeme: "&"},
+					Right:    &expr.Literal{Value: 3.0},
+				},
+				Operator: &token.Token{Type: token.PIPE, Lexeme: "|"},
+				Right: &expr.Binary{
+					Left:     &expr.Literal{Value: 1.0},
+					Operator: &token.Token{Type: token.LESS_LESS, Lexeme: "<<"},
+					Right:    &expr.Literal{Value: 4.0},
+				},
+			},
+			expected: "18",
+		},
+		{
+			name: "Non-integral bitwise operands are left for the interpreter (1.5 & 1)",
+			expr: &expr.Binary{
+				Left:     &expr.Literal{Value: 1.5},
+				Operator: &token.Token{Type: token.AMPERSAND, Lexeme: "&"},
+				Right:    &expr.Literal{Value: 1.0},
+			},
+			expected: "(& 1.5 1)",
+		},
+		{
+			name: "String concatenation (\"a\" + \"b\")",
+			expr: &expr.Binary{
+				Left:     &expr.Literal{Value: "a"},
+				Operator: &token.Token{Type: token.PLUS, Lexeme: "+"},
+				Right:    &expr.Literal{Value: "b"},
+			},
+			expected: "ab",
+		},
+		{
+			name: "Comparison inside a grouping ((1 < 2))",
+			expr: &expr.Grouping{
+				Expression: &expr.Binary{
+					Left:     &expr.Literal{Value: 1.0},
+					Operator: &token.Token{Type: token.LESS, Lexeme: "<"},
+					Right:    &expr.Literal{Value: 2.0},
+				},
+			},
+			expected: "true",
+		},
+		{
+			name: "Equality of different types (1 == \"1\")",
+			expr: &expr.Binary{
+				Left:     &expr.Literal{Value: 1.0},
+				Operator: &token.Token{Type: token.EQUAL_EQUAL, Lexeme: "=="},
+				Right:    &expr.Literal{Value: "1"},
+			},
+			expected: "false",
+		},
+		{
+			name: "Unary operators (!-1)",
+			expr: &expr.Unary{
+				Operator: &token.Token{Type: token.BANG, Lexeme: "!"},
+				Right: &expr.Unary{
+					Operator: &token.Token{Type: token.MINUS, Lexeme: "-"},
+					Right:    &expr.Literal{Value: 1.0},
+				},
+			},
+			expected: "false",
+		},
+		{
+			name: "Mismatched operands are left for the runtime (1 + \"a\")",
+			expr: &expr.Binary{
+				Left:     &expr.Literal{Value: 1.0},
+				Operator: &token.Token{Type: token.PLUS, Lexeme: "+"},
+				Right:    &expr.Literal{Value: "a"},
+			},
+			expected: "(+ 1 a)",
+		},
+		{
+			name: "Partially constant expression (x + 2 * 3)",
+			expr: &expr.Binary{
+				Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+				Operator: &token.Token{Type: token.PLUS, Lexeme: "+"},
+				Right: &expr.Binary{
+					Left:     &expr.Literal{Value: 2.0},
+					Operator: &token.Token{Type: token.STAR, Lexeme: "*"},
+					Right:    &expr.Literal{Value: 3.0},
+				},
+			},
+			expected: "(+ x 6)",
+		},
+		{
+			name: "Logical or with a truthy left operand (true or x)",
+			expr: &expr.Logical{
+				Left:     &expr.Literal{Value: true},
+				Operator: &token.Token{Type: token.OR, Lexeme: "or"},
+				Right:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+			},
+			expected: "true",
+		},
+		{
+			name: "Logical and with a truthy left operand (1 and x)",
+			expr: &expr.Logical{
+				Left:     &expr.Literal{Value: 1.0},
+				Operator: &token.Token{Type: token.AND, Lexeme: "and"},
+				Right:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+			},
+			expected: "x",
+		},
+		{
+			name: "Ternary with a constant condition (1 > 2 ? x : y)",
+			expr: &expr.Ternary{
+				Condition: &expr.Binary{
+					Left:     &expr.Literal{Value: 1.0},
+					Operator: &token.Token{Type: token.GREATER, Lexeme: ">"},
+					Right:    &expr.Literal{Value: 2.0},
+				},
+				TrueBranch:  &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+				FalseBranch: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "y"}},
+			},
+			expected: "y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := printer.New().Print(New().Optimize(tt.expr))
+
+			if actual != tt.expected {
+				t.Errorf("Optimize() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOptimizer_Statements(t *testing.T) {
+	x := &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}
+	printX := &stmt.Print{Expression: &expr.Variable{Name: x}}
+
+	tests := []struct {
+		name       string
+		statements []stmt.Stmt
+		expected   []stmt.Stmt
+	}{
+		{
+			name: "If with a truthy condition keeps the then branch",
+			statements: []stmt.Stmt{
+				&stmt.If{
+					Condition:  &expr.Literal{Value: true},
+					ThenBranch: printX,
+					ElseBranch: &stmt.Print{Expression: &expr.Literal{Value: "else"}},
+				},
+			},
+			expected: []stmt.Stmt{printX},
+		},
+		{
+			name: "If with a falsy condition and no else branch is removed",
+			statements: []stmt.Stmt{
+				&stmt.If{Condition: &expr.Literal{Value: nil}, ThenBranch: printX},
+				printX,
+			},
+			expected: []stmt.Stmt{printX},
+		},
+		{
+			name: "While with a falsy condition is removed",
+			statements: []stmt.Stmt{
+				&stmt.While{Condition: &expr.Literal{Value: false}, Body: printX},
+			},
+			expected: []stmt.Stmt{},
+		},
+		{
+			name: "Dead branches inside a block are removed",
+			statements: []stmt.Stmt{
+				&stmt.Block{Statements: []stmt.Stmt{
+					&stmt.If{Condition: &expr.Literal{Value: false}, ThenBranch: printX},
+					&stmt.Var{Name: x, Initializer: &expr.Grouping{Expression: &expr.Literal{Value: 1.0}}},
+				}},
+			},
+			expected: []stmt.Stmt{
+				&stmt.Block{Statements: []stmt.Stmt{
+					&stmt.Var{Name: x, Initializer: &expr.Literal{Value: 1.0}},
+				}},
+			},
+		},
+		{
+			name: "Loop body removed by optimization is replaced with an empty block",
+			statements: []stmt.Stmt{
+				&stmt.While{
+					Condition: &expr.Variable{Name: x},
+					Body:      &stmt.If{Condition: &expr.Literal{Value: false}, ThenBranch: printX},
+				},
+			},
+			expected: []stmt.Stmt{
+				&stmt.While{Condition: &expr.Variable{Name: x}, Body: &stmt.Block{}},
+			},
+		},
+		{
+			name: "Statements after a return are removed",
+			statements: []stmt.Stmt{
+				&stmt.If{
+					Condition:  &expr.Literal{Value: true},
+					ThenBranch: &stmt.Return{Keyword: &token.Token{Type: token.RETURN, Lexeme: "return"}},
+				},
+				printX,
+			},
+			expected: []stmt.Stmt{&stmt.Return{Keyword: &token.Token{Type: token.RETURN, Lexeme: "return"}}},
+		},
+		{
+			name: "For-in iterable is folded and an empty body is replaced with an empty block",
+			statements: []stmt.Stmt{
+				&stmt.ForIn{
+					Name:     x,
+					Iterable: &expr.Grouping{Expression: &expr.Literal{Value: "xs"}},
+					Body:     &stmt.If{Condition: &expr.Literal{Value: false}, ThenBranch: printX},
+				},
+			},
+			expected: []stmt.Stmt{
+				&stmt.ForIn{Name: x, Iterable: &expr.Literal{Value: "xs"}, Body: &stmt.Block{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := New().OptimizeStatements(tt.statements)
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("OptimizeStatements() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOptimizer_Trace(t *testing.T) {
+	var out bytes.Buffer
+
+	New().WithTrace(&out).Optimize(&expr.Binary{
+		Left:     &expr.Literal{Value: 1.0},
+		Operator: &token.Token{Type: token.PLUS, Lexeme: "+"},
+		Right:    &expr.Literal{Value: 2.0},
+	})
+
+	expected := "before: (+ 1 2)\nafter:  3\n"
+	if out.String() != expected {
+		t.Errorf("trace = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestOptimizer_TraceEliminated(t *testing.T) {
+	var out bytes.Buffer
+	x := &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}
+	printX := &stmt.Print{Expression: &expr.Variable{Name: x}}
+
+	New().WithTrace(&out).OptimizeStatements([]stmt.Stmt{
+		&stmt.If{Condition: &expr.Literal{Value: true}, ThenBranch: printX, ElseBranch: printX},
+		&stmt.While{Condition: &expr.Literal{Value: false}, Body: printX},
+		&stmt.Throw{Keyword: &token.Token{Type: token.THROW, Lexeme: "throw", Line: 3}, Value: &expr.Variable{Name: x}},
+		printX,
+		printX,
+	})
+
+	expected := "removed: else branch of if true\n" +
+		"removed: while false\n" +
+		"removed: 2 statement(s) after throw at line 3\n"
+	if out.String() != expected {
+		t.Errorf("trace = %q, want %q", out.String(), expected)
+	}
+}
@@ -10,34 +10,106 @@ our way up to the highest-precedence operators.
 
 Parser Context-Free Grammar (CFG):
 
-	expression     → ternary ; 																													// Has the lowest precedence
-	ternary        → equality ("?" expression ":" expression)? ;
+	program        → declaration* EOF ;
+	declaration    → classDecl | traitDecl | funDecl | varDecl | importDecl | statement ;
+	classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )? ( "with" IDENTIFIER ( "," IDENTIFIER )* )?
+	                 "{" member* "}" ;
+	traitDecl      → "trait" IDENTIFIER "{" member* "}" ;
+	member         → "class"? "async"? function | getter | setter | field ;
+	getter         → IDENTIFIER block ;
+	setter         → "set" IDENTIFIER "(" IDENTIFIER ")" block ;
+	field          → IDENTIFIER ":" type ";" ;
+	funDecl        → "async"? "fun" function ;
+	function       → IDENTIFIER "(" parameters? ")" ( ":" type )? block ;
+	parameters     → parameter ( "," parameter )* ;
+	parameter      → "..." IDENTIFIER ( ":" type )? | IDENTIFIER ( ":" type )? ( "=" expression )? ;
+	type           → typeTerm ( "|" typeTerm )* ;
+	typeTerm       → ( IDENTIFIER | "null" | "fun" ) ( "[" type ( "," type )* "]" )? ;
+	varDecl        → ( "var" | "const" | "let" ) ( IDENTIFIER ( ":" type )? ( "=" expression )? | pattern "=" expression ) ";" ;
+	importDecl     → "import" STRING "as" IDENTIFIER ";"
+	               | "from" STRING "import" IDENTIFIER ( "," IDENTIFIER )* ";" ;
+	statement      → exprStmt | forStmt | ifStmt | printStmt | returnStmt | whileStmt
+	               | breakStmt | continueStmt | throwStmt | tryStmt | yieldStmt | matchStmt | block ;
+	exprStmt       → expression ";" ;
+	forStmt        → "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement | forInStmt ;
+	forInStmt      → "for" "(" ( "var" | "const" | "let" ) IDENTIFIER ( "," IDENTIFIER )? "in" expression ")" statement ;
+	ifStmt         → "if" "(" expression ")" statement ( "else" statement )? ;
+	printStmt      → "print" expression ";" ;
+	returnStmt     → "return" expression? ";" ;
+	whileStmt      → "while" "(" expression ")" statement ;
+	breakStmt      → "break" ";" ;
+	continueStmt   → "continue" ";" ;
+	throwStmt      → "throw" expression ";" ;
+	tryStmt        → "try" block ( "catch" "(" IDENTIFIER ")" block )? ( "finally" block )? ;
+	yieldStmt      → "yield" expression? ";" ;
+	matchStmt      → match ";"? ;
+	block          → "{" declaration* "}" ;
+
+	expression     → assignment ; 																												// Has the lowest precedence
+	assignment     → target ( "=" | "+=" | "-=" | "*=" | "/=" | "%=" ) assignment | listTarget "=" assignment | ternary ;
+	target         → ( call "." )? IDENTIFIER | call "[" expression "]" ;
+	listTarget     → "[" ( ( target | listTarget ) ( "," ( target | listTarget ) )* ","? )? "]" ;
+	ternary        → logic_or ("?" expression ":" expression)? ;
+	logic_or       → logic_and ( "or" logic_and )* ;
+	logic_and      → equality ( "and" equality )* ;
 	equality       → comparison ( ( "!=" | "==" ) comparison )* ;
-	comparison     → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+	comparison     → range ( ( ">" | ">=" | "<" | "<=" ) range )* ;
+	range          → bit_or ( ( ".." | "..<" ) bit_or )? ;
+	bit_or         → bit_xor ( "|" bit_xor )* ;
+	bit_xor        → bit_and ( "^" bit_and )* ;
+	bit_and        → shift ( "&" shift )* ;
+	shift          → term ( ( "<<" | ">>" ) term )* ;
 	term           → factor ( ( "-" | "+" ) factor )* ;
-	factor         → unary ( ( "/" | "*" ) unary )* ;
-	unary          → ( "!" | "-" ) unary | primary ;
-	primary        → NUMBER | STRING | "true" | "false" | "nil" | "(" expression ")" ; 	// Has the highest precedence
+	factor         → unary ( ( "/" | "//" | "*" | "%" ) unary )* ;
+	unary          → ( "!" | "-" | "~" | "await" ) unary | ( "++" | "--" ) target | "spawn" call | power ;
+	power          → postfix ( "**" unary )? ;
+	postfix        → call ( "++" | "--" )? ;
+	call           → primary ( "(" arguments? ")" | "." IDENTIFIER | "[" subscript "]" )* ;
+	arguments      → argument ( "," argument )* ;
+	argument       → ( IDENTIFIER ":" )? expression ;
+	subscript      → expression | expression? ":" expression? ;
+	primary        → NUMBER | STRING | "true" | "false" | "nil" | "this" | IDENTIFIER | "(" expression ")"
+	               | "super" "." IDENTIFIER | lambda | match | "[" ( expression ( "," expression )* ","? )? "]"
+	               | "{" ( entry ( "," entry )* ","? )? "}" ; 																									// Has the highest precedence
+	entry          → expression ":" expression ;
+	lambda         → "async"? "fun" "(" parameters? ")" ( ":" type )? block | "async"? "(" parameters? ")" ( ":" type )? "=>" ( block | expression ) ;
+	match          → "match" "(" expression ")" "{" arm ( "," arm )* ","? "}" ;
+	arm            → pattern ( "if" expression )? "=>" ( block | expression ) ;
+	pattern        → alternative ( "|" alternative )* ;
+	alternative    → literal | "_" | IDENTIFIER | IDENTIFIER? "{" ( field ( "," field )* ","? )? "}"
+	               | "[" ( elements | rest )? "]" ;
+	literal        → NUMBER | "-" NUMBER | STRING | "true" | "false" | "null" ;
+	elements       → pattern ( "," pattern )* ( "," rest )? ","? ;
+	rest           → "..." IDENTIFIER ;
+	field          → IDENTIFIER ( ":" pattern )? ;
 
 The parser is implemented as a recursive descent parser. Each non-terminal in the grammar
 is implemented as a function that corresponds to the rule in the grammar. The functions
 are called recursively to parse the input tokens.
 
-The parser is also responsible for error handling. If an error is encountered, the parser
-will panic with an error message that contains the token where the error occurred.
+The parser is also responsible for error handling. If an error is encountered while parsing
+a single expression, the parser will panic with an error message that contains the token where
+the error occurred. When parsing a whole program, the parser synchronizes to the next statement
+after an error and keeps going, so that all the errors can be reported at once.
 */
 package parser
 
 import (
 	"golox/error"
 	"golox/expr"
+	"golox/stmt"
 	"golox/token"
 )
 
+// Maximum number of arguments a call expression can have
+const maxArguments = 255
+
 // Parser is the recursive descent parser for the GoLox language
 type Parser struct {
-	tokens  []token.Token
-	current int // Next token to be parsed
+	tokens   []token.Token
+	current  int  // Next token to be parsed
+	armArrow int  // Position of the "=>" ending the match guard being parsed
+	yielded  bool // Whether a yield statement was parsed in the current function body
 }
 
 // New creates a new parser with the given tokens
@@ -58,13 +130,101 @@ func (p *Parser) Parse() expr.Expr {
 	return p.expression()
 }
 
-// Expression maps to the CFG rule: expression → ternary ;
+// ParseProgram parses the tokens into a list of statements. All the parse errors
+// are collected and returned instead of stopping at the first one
+func (p *Parser) ParseProgram() ([]stmt.Stmt, []*error.Error) {
+	statements := []stmt.Stmt{}
+	errors := []*error.Error{}
+
+	for !p.isAtEnd() {
+		if declaration, err := p.safeDeclaration(); err != nil {
+			errors = append(errors, err)
+			p.synchronize()
+		} else {
+			statements = append(statements, declaration)
+		}
+	}
+
+	return statements, errors
+}
+
+// Expression maps to the CFG rule: expression → assignment ;
 func (p *Parser) expression() expr.Expr {
-	return p.ternary()
+	return p.assignment()
+}
+
+// Assignment maps to the CFG rule:
+// assignment → target ( "=" | "+=" | "-=" | "*=" | "/=" | "%=" ) assignment | ternary ;
+//
+// The left-hand side is parsed as a normal expression first, and only when an "="
+// follows it is converted into an assignment target. This way we do not need
+// unlimited lookahead to know whether we are parsing an assignment
+func (p *Parser) assignment() expr.Expr {
+	expression := p.ternary()
+
+	if p.match(token.PLUS_EQUAL, token.MINUS_EQUAL, token.STAR_EQUAL, token.SLASH_EQUAL, token.PERCENT_EQUAL) {
+		operator := p.previous()
+		value := p.assignment()
+
+		return &expr.CompoundAssign{Target: p.target(expression, operator, "assignment"), Operator: operator, Value: value}
+	}
+
+	if p.match(token.EQUAL) {
+		equals := p.previous()
+		value := p.assignment()
+
+		switch target := expression.(type) {
+		case *expr.Variable:
+			return &expr.Assign{Name: target.Name, Value: value}
+		case *expr.Get:
+			return &expr.Set{Object: target.Object, Name: target.Name, Value: value}
+		case *expr.Index:
+			return &expr.SetIndex{Object: target.Object, Bracket: target.Bracket, Index: target.Index, Value: value}
+		case *expr.List:
+			return &expr.Destructure{Pattern: p.listTarget(target, equals), Equals: equals, Value: value}
+		}
+
+		if err := parseError(equals, "Invalid assignment target."); err != nil {
+			panic(err)
+		}
+	}
+
+	return expression
+}
+
+// Converts the list literal on the left of an assignment into the pattern of a
+// destructuring assignment, maps to the CFG rule:
+// listTarget → "[" ( ( target | listTarget ) ( "," ( target | listTarget ) )* ","? )? "]" ;
+//
+// A "_" element skips the value at its position. List literals do not keep
+// their brackets, so the "=" stands in for them in error messages
+func (p *Parser) listTarget(list *expr.List, equals *token.Token) *expr.ListPattern {
+	pattern := &expr.ListPattern{Bracket: equals, Elements: make([]expr.Pattern, len(list.Elements))}
+
+	for idx, element := range list.Elements {
+		switch target := element.(type) {
+		case *expr.Variable:
+			if target.Name.Lexeme == "_" {
+				pattern.Elements[idx] = &expr.WildcardPattern{Token: target.Name}
+			} else {
+				pattern.Elements[idx] = &expr.TargetPattern{Target: target}
+			}
+		case *expr.Get, *expr.Index:
+			pattern.Elements[idx] = &expr.TargetPattern{Target: target}
+		case *expr.List:
+			pattern.Elements[idx] = p.listTarget(target, equals)
+		default:
+			if err := parseError(equals, "Invalid assignment target."); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	return pattern
 }
 
 func (p *Parser) ternary() expr.Expr {
-	expression := p.equality()
+	expression := p.or()
 
 	if p.match(token.QUESTION) {
 		trueBranch := p.expression()
@@ -79,6 +239,32 @@ func (p *Parser) ternary() expr.Expr {
 	return expression
 }
 
+// Or maps to the CFG rule: logic_or → logic_and ( "or" logic_and )* ;
+func (p *Parser) or() expr.Expr {
+	expression := p.and()
+
+	for p.match(token.OR) {
+		operator := p.previous()
+		right := p.and()
+		expression = &expr.Logical{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// And maps to the CFG rule: logic_and → equality ( "and" equality )* ;
+func (p *Parser) and() expr.Expr {
+	expression := p.equality()
+
+	for p.match(token.AND) {
+		operator := p.previous()
+		right := p.equality()
+		expression = &expr.Logical{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
 // Equality maps to the CFG rule: equality → comparison ( ( "!=" | "==" ) comparison )* ;
 // comparison is the first non-terminal in the rule
 // ( ( "!=" | "==" ) comparison )* is the optional part of the rule
@@ -102,9 +288,9 @@ func (p *Parser) equality() expr.Expr {
 	return expression
 }
 
-// Comparison maps to the CFG rule: comparison → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
-// term is the first non-terminal in the rule
-// ( ( ">" | ">=" | "<" | "<=" ) term )* is the optional part of the rule
+// Comparison maps to the CFG rule: comparison → range ( ( ">" | ">=" | "<" | "<=" ) range )* ;
+// range is the first non-terminal in the rule
+// ( ( ">" | ">=" | "<" | "<=" ) range )* is the optional part of the rule
 //
 // The rule is left-associative
 // Grabs the matched operator token and the right operand and creates a new Binary expression
@@ -112,11 +298,79 @@ func (p *Parser) equality() expr.Expr {
 //
 // The loop continues until there are no more matched operators
 func (p *Parser) comparison() expr.Expr {
-	// first term non-terminal in the rule
-	expression := p.term()
+	// first range non-terminal in the rule
+	expression := p.rangeExpression()
 
-	// loop through the optional ( ( ">" | ">=" | "<" | "<=" ) term )* part of the rule
+	// loop through the optional ( ( ">" | ">=" | "<" | "<=" ) range )* part of the rule
 	for p.match(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL) {
+		operator := p.previous()
+		right := p.rangeExpression()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// RangeExpression maps to the CFG rule: range → bit_or ( ( ".." | "..<" ) bit_or )? ;
+//
+// The rule is not associative, "a..b..c" is a syntax error. The ".." operator
+// includes the end of the range and the "..<" operator excludes it
+func (p *Parser) rangeExpression() expr.Expr {
+	expression := p.bitOr()
+
+	if p.match(token.DOT_DOT, token.DOT_DOT_LESS) {
+		operator := p.previous()
+		right := p.bitOr()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// BitOr maps to the CFG rule: bit_or → bit_xor ( "|" bit_xor )* ;
+func (p *Parser) bitOr() expr.Expr {
+	expression := p.bitXor()
+
+	for p.match(token.PIPE) {
+		operator := p.previous()
+		right := p.bitXor()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// BitXor maps to the CFG rule: bit_xor → bit_and ( "^" bit_and )* ;
+func (p *Parser) bitXor() expr.Expr {
+	expression := p.bitAnd()
+
+	for p.match(token.CARET) {
+		operator := p.previous()
+		right := p.bitAnd()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// BitAnd maps to the CFG rule: bit_and → shift ( "&" shift )* ;
+func (p *Parser) bitAnd() expr.Expr {
+	expression := p.shift()
+
+	for p.match(token.AMPERSAND) {
+		operator := p.previous()
+		right := p.shift()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// Shift maps to the CFG rule: shift → term ( ( "<<" | ">>" ) term )* ;
+func (p *Parser) shift() expr.Expr {
+	expression := p.term()
+
+	for p.match(token.LESS_LESS, token.GREATER_GREATER) {
 		operator := p.previous()
 		right := p.term()
 		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
@@ -138,11 +392,11 @@ func (p *Parser) term() expr.Expr {
 	return expression
 }
 
-// Factor maps to the CFG rule: factor → unary ( ( "/" | "*" ) unary )* ;
+// Factor maps to the CFG rule: factor → unary ( ( "/" | "//" | "*" | "%" ) unary )* ;
 func (p *Parser) factor() expr.Expr {
 	expression := p.unary()
 
-	for p.match(token.SLASH, token.STAR) {
+	for p.match(token.SLASH, token.SLASH_SLASH, token.STAR, token.PERCENT) {
 		operator := p.previous()
 		right := p.unary()
 		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
@@ -151,18 +405,167 @@ func (p *Parser) factor() expr.Expr {
 	return expression
 }
 
-// Unary maps to the CFG rule: unary → ( "!" | "-" ) unary | primary ;
+// Unary maps to the CFG rule:
+// unary → ( "!" | "-" | "~" | "await" ) unary | ( "++" | "--" ) target | "spawn" call | power ;
 func (p *Parser) unary() expr.Expr {
-	if p.match(token.BANG, token.MINUS) {
+	if p.match(token.BANG, token.MINUS, token.TILDE) {
 		operator := p.previous()
 		right := p.unary()
 		return &expr.Unary{Operator: operator, Right: right}
 	}
 
-	return p.primary()
+	if p.match(token.PLUS_PLUS, token.MINUS_MINUS) {
+		operator := p.previous()
+		target := p.target(p.unary(), operator, "increment")
+		return &expr.Update{Target: target, Operator: operator, Prefix: true}
+	}
+
+	if p.match(token.AWAIT) {
+		keyword := p.previous()
+		return &expr.Await{Keyword: keyword, Value: p.unary()}
+	}
+
+	if p.match(token.SPAWN) {
+		keyword := p.previous()
+		call, ok := p.call().(*expr.Call)
+		if !ok {
+			panic(parseError(keyword, "Expect a call after 'spawn'."))
+		}
+		return &expr.Spawn{Keyword: keyword, Call: call}
+	}
+
+	return p.power()
+}
+
+// Power maps to the CFG rule: power → postfix ( "**" unary )? ;
+//
+// The rule is right-associative, as the right operand is parsed with unary, which
+// leads back to power. It also binds tighter than a unary operator on its left, so
+// -2 ** 2 is -(2 ** 2), while 2 ** -1 is still allowed
+func (p *Parser) power() expr.Expr {
+	expression := p.postfix()
+
+	if p.match(token.STAR_STAR) {
+		operator := p.previous()
+		right := p.unary()
+		expression = &expr.Binary{Left: expression, Operator: operator, Right: right}
+	}
+
+	return expression
+}
+
+// Postfix maps to the CFG rule: postfix → call ( "++" | "--" )? ;
+func (p *Parser) postfix() expr.Expr {
+	expression := p.call()
+
+	if p.match(token.PLUS_PLUS, token.MINUS_MINUS) {
+		operator := p.previous()
+		return &expr.Update{Target: p.target(expression, operator, "increment"), Operator: operator}
+	}
+
+	return expression
 }
 
-// Primary maps to the CFG rule: primary → NUMBER | STRING | "true" | "false" | "nil" | "(" expression ")" ;
+// Target maps to the CFG rule: target → ( call "." )? IDENTIFIER | call "[" expression "]" ;
+// It checks that the already parsed expression can be assigned to by the
+// operator. The kind of the operation is used in the error message
+func (p *Parser) target(expression expr.Expr, operator *token.Token, kind string) expr.Expr {
+	switch expression.(type) {
+	case *expr.Variable, *expr.Get, *expr.Index:
+		return expression
+	}
+
+	if err := parseError(operator, "Invalid "+kind+" target."); err != nil {
+		panic(err)
+	}
+
+	return expression
+}
+
+// Call maps to the CFG rule: call → primary ( "(" arguments? ")" | "." IDENTIFIER | "[" subscript "]" )* ;
+func (p *Parser) call() expr.Expr {
+	expression := p.primary()
+
+	for {
+		if p.match(token.LEFT_PAREN) {
+			expression = p.finishCall(expression)
+		} else if p.match(token.DOT) {
+			name := p.consume(token.IDENTIFIER, "Expect property name after '.'.")
+			expression = &expr.Get{Object: expression, Name: name}
+		} else if p.match(token.LEFT_BRACKET) {
+			expression = p.subscript(expression)
+		} else {
+			break
+		}
+	}
+
+	return expression
+}
+
+// Subscript maps to the CFG rule: subscript → expression | expression? ":" expression? ;
+// The opening bracket has already been consumed
+func (p *Parser) subscript(object expr.Expr) expr.Expr {
+	var start expr.Expr
+	if !p.check(token.COLON) {
+		start = p.expression()
+	}
+
+	if !p.match(token.COLON) {
+		bracket := p.consume(token.RIGHT_BRACKET, "Expect ']' after index.")
+		return &expr.Index{Object: object, Bracket: bracket, Index: start}
+	}
+
+	var end expr.Expr
+	if !p.check(token.RIGHT_BRACKET) {
+		end = p.expression()
+	}
+
+	bracket := p.consume(token.RIGHT_BRACKET, "Expect ']' after slice.")
+
+	return &expr.Slice{Object: object, Bracket: bracket, Start: start, End: end}
+}
+
+// Parses the argument list of a call, maps to the CFG rules:
+// arguments → argument ( "," argument )* ;
+// argument  → ( IDENTIFIER ":" )? expression ;
+//
+// Named arguments must follow the positional ones
+func (p *Parser) finishCall(callee expr.Expr) expr.Expr {
+	arguments := []expr.Expr{}
+	var named []*expr.NamedArgument
+
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(arguments)+len(named) >= maxArguments {
+				if err := parseError(p.peek(), "Can't have more than 255 arguments."); err != nil {
+					panic(err)
+				}
+			}
+
+			if p.check(token.IDENTIFIER) && p.checkNext(token.COLON) {
+				name := p.advance()
+				p.advance()
+				named = append(named, &expr.NamedArgument{Name: name, Value: p.expression()})
+			} else if len(named) > 0 {
+				if err := parseError(p.peek(), "Positional argument can't follow named arguments."); err != nil {
+					panic(err)
+				}
+			} else {
+				arguments = append(arguments, p.expression())
+			}
+
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+
+	paren := p.consume(token.RIGHT_PAREN, "Expect ')' after arguments.")
+
+	return &expr.Call{Callee: callee, Paren: paren, Arguments: arguments, Named: named}
+}
+
+// Primary maps to the CFG rule: primary → NUMBER | STRING | "true" | "false" | "nil" | IDENTIFIER | "(" expression ")" ;
 func (p *Parser) primary() expr.Expr {
 	switch {
 	case p.match(token.FALSE):
@@ -173,6 +576,27 @@ func (p *Parser) primary() expr.Expr {
 		return &expr.Literal{Value: nil}
 	case p.match(token.NUMBER, token.STRING):
 		return &expr.Literal{Value: p.previous().Literal}
+	case p.match(token.THIS):
+		return &expr.This{Keyword: p.previous()}
+	case p.match(token.SUPER):
+		keyword := p.previous()
+		p.consume(token.DOT, "Expect '.' after 'super'.")
+		method := p.consume(token.IDENTIFIER, "Expect superclass method name.")
+		return &expr.Super{Keyword: keyword, Method: method}
+	case p.match(token.IDENTIFIER):
+		return &expr.Variable{Name: p.previous()}
+	case p.match(token.LEFT_BRACKET):
+		return p.list()
+	case p.match(token.LEFT_BRACE):
+		return p.mapLiteral()
+	case p.match(token.FUN):
+		return p.lambda()
+	case p.match(token.ASYNC):
+		return p.asyncLambda()
+	case p.match(token.MATCH):
+		return p.matchExpression()
+	case p.check(token.LEFT_PAREN) && p.isArrowFunction():
+		return p.arrowFunction()
 	case p.match(token.LEFT_PAREN):
 		expression := p.expression()
 		p.consume(token.RIGHT_PAREN, "Expect ')' after expression.")
@@ -187,6 +611,332 @@ func (p *Parser) primary() expr.Expr {
 	return nil
 }
 
+// Lambda maps to the CFG rule: lambda → "fun" "(" parameters? ")" ( ":" type )? block ;
+// The "fun" keyword has already been consumed
+func (p *Parser) lambda() expr.Expr {
+	keyword := p.previous()
+
+	p.consume(token.LEFT_PAREN, "Expect '(' after 'fun'.")
+	params := p.parameters()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	returnType := p.returnType()
+	p.consume(token.LEFT_BRACE, "Expect '{' before lambda body.")
+
+	body, generator := p.functionBody(p.block)
+
+	return &expr.Lambda{Keyword: keyword, Params: params, Body: &stmt.Block{Statements: body}, Generator: generator, ReturnType: returnType}
+}
+
+// Parses a lambda after the "async" keyword, which makes it an async function
+func (p *Parser) asyncLambda() expr.Expr {
+	var lambda expr.Expr
+
+	switch {
+	case p.match(token.FUN):
+		lambda = p.lambda()
+	case p.check(token.LEFT_PAREN) && p.isArrowFunction():
+		lambda = p.arrowFunction()
+	default:
+		if err := parseError(p.peek(), "Expect function after 'async'."); err != nil {
+			panic(err)
+		}
+	}
+
+	lambda.(*expr.Lambda).Async = true
+
+	return lambda
+}
+
+// ArrowFunction maps to the CFG rule: lambda → "(" parameters? ")" ( ":" type )? "=>" ( block | expression ) ;
+// An expression body is turned into a return statement
+func (p *Parser) arrowFunction() expr.Expr {
+	p.consume(token.LEFT_PAREN, "Expect '(' before parameters.")
+	params := p.parameters()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	returnType := p.annotation()
+	arrow := p.consume(token.ARROW, "Expect '=>' after parameters.")
+
+	var body []stmt.Stmt
+	var generator bool
+	if p.match(token.LEFT_BRACE) {
+		body, generator = p.functionBody(p.block)
+	} else {
+		body, generator = p.functionBody(func() []stmt.Stmt {
+			return []stmt.Stmt{&stmt.Return{Keyword: arrow, Value: p.expression()}}
+		})
+	}
+
+	return &expr.Lambda{Keyword: arrow, Params: params, ReturnType: returnType, Body: &stmt.Block{Statements: body}, Generator: generator}
+}
+
+// Reports whether the parenthesis at the current token starts the parameters of
+// an arrow function, by looking for a "=>" right after the matching closing
+// parenthesis and the return type, if any. No tokens are consumed
+func (p *Parser) isArrowFunction() bool {
+	depth := 0
+
+	for idx := p.current; idx < len(p.tokens); idx++ {
+		switch p.tokens[idx].Type {
+		case token.LEFT_PAREN, token.LEFT_BRACKET, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACKET, token.RIGHT_BRACE:
+			depth--
+			if depth == 0 {
+				next := p.skipReturnType(idx + 1)
+				return next < len(p.tokens) && p.tokens[next].Type == token.ARROW && next != p.armArrow
+			}
+		case token.SEMICOLON, token.EOF:
+			return false
+		}
+	}
+
+	return false
+}
+
+// Returns the position after the return type annotation starting at the given
+// position, or the position itself if there is none. Only the tokens a type is
+// made of are skipped
+func (p *Parser) skipReturnType(idx int) int {
+	if idx >= len(p.tokens) || p.tokens[idx].Type != token.COLON {
+		return idx
+	}
+
+	for idx++; idx < len(p.tokens); idx++ {
+		switch p.tokens[idx].Type {
+		case token.IDENTIFIER, token.NULL, token.FUN, token.LEFT_BRACKET, token.RIGHT_BRACKET, token.COMMA, token.PIPE:
+		default:
+			return idx
+		}
+	}
+
+	return idx
+}
+
+// MatchExpression maps to the CFG rules:
+// match → "match" "(" expression ")" "{" arm ( "," arm )* ","? "}" ;
+// arm   → pattern ( "if" expression )? "=>" ( block | expression ) ;
+//
+// The "match" keyword has already been consumed. The comma after an arm whose
+// body is a block can be left out
+func (p *Parser) matchExpression() expr.Expr {
+	keyword := p.previous()
+
+	p.consume(token.LEFT_PAREN, "Expect '(' after 'match'.")
+	subject := p.expression()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after match subject.")
+	p.consume(token.LEFT_BRACE, "Expect '{' before match arms.")
+
+	arms := []*expr.MatchArm{}
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		arm := &expr.MatchArm{Pattern: p.pattern()}
+		if p.match(token.IF) {
+			arm.Guard = p.guard()
+		}
+		p.consume(token.ARROW, "Expect '=>' after match pattern.")
+		arms = append(arms, arm)
+
+		if p.match(token.LEFT_BRACE) {
+			arm.Block = &stmt.Block{Statements: p.block()}
+			p.match(token.COMMA)
+			continue
+		}
+
+		arm.Body = p.expression()
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	if len(arms) == 0 {
+		if err := parseError(p.peek(), "Expect at least one match arm."); err != nil {
+			panic(err)
+		}
+	}
+
+	p.consume(token.RIGHT_BRACE, "Expect '}' after match arms.")
+
+	return &expr.Match{Keyword: keyword, Subject: subject, Arms: arms}
+}
+
+// Parses the guard of a match arm. A guard ending with a parenthesized
+// expression, such as "n if (n > 0) => ...", looks like the parameters of an
+// arrow function, so the "=>" of the arm is marked as not being one
+func (p *Parser) guard() expr.Expr {
+	enclosingArrow := p.armArrow
+	defer func() { p.armArrow = enclosingArrow }()
+
+	depth := 0
+	for idx := p.current; idx < len(p.tokens); idx++ {
+		switch p.tokens[idx].Type {
+		case token.LEFT_PAREN, token.LEFT_BRACKET, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACKET, token.RIGHT_BRACE:
+			depth--
+		case token.ARROW:
+			if depth == 0 {
+				p.armArrow = idx
+				return p.expression()
+			}
+		}
+
+		if depth < 0 {
+			break
+		}
+	}
+
+	return p.expression()
+}
+
+// Pattern maps to the CFG rule: pattern → alternative ( "|" alternative )* ;
+func (p *Parser) pattern() expr.Pattern {
+	alternatives := []expr.Pattern{p.alternative()}
+	for p.match(token.PIPE) {
+		alternatives = append(alternatives, p.alternative())
+	}
+
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+
+	return &expr.AlternativePattern{Alternatives: alternatives}
+}
+
+// Alternative maps to the CFG rules:
+// alternative → literal | "_" | IDENTIFIER | IDENTIFIER? "{" ( field ( "," field )* ","? )? "}" | "[" ( elements | rest )? "]" ;
+// literal     → NUMBER | "-" NUMBER | STRING | "true" | "false" | "null" ;
+func (p *Parser) alternative() expr.Pattern {
+	switch {
+	case p.match(token.FALSE):
+		return &expr.LiteralPattern{Token: p.previous(), Value: false}
+	case p.match(token.TRUE):
+		return &expr.LiteralPattern{Token: p.previous(), Value: true}
+	case p.match(token.NULL):
+		return &expr.LiteralPattern{Token: p.previous(), Value: nil}
+	case p.match(token.NUMBER, token.STRING):
+		return &expr.LiteralPattern{Token: p.previous(), Value: p.previous().Literal}
+	case p.match(token.MINUS):
+		minus := p.previous()
+		number := p.consume(token.NUMBER, "Expect number after '-' in pattern.")
+		return &expr.LiteralPattern{Token: minus, Value: -number.Literal.(float64)}
+	case p.match(token.LEFT_BRACKET):
+		return p.listPattern()
+	case p.match(token.LEFT_BRACE):
+		return p.instancePattern(nil)
+	case p.match(token.IDENTIFIER):
+		name := p.previous()
+		switch {
+		case name.Lexeme == "_":
+			return &expr.WildcardPattern{Token: name}
+		case p.match(token.LEFT_BRACE):
+			return p.instancePattern(name)
+		}
+		return &expr.BindingPattern{Name: name}
+	}
+
+	if err := parseError(p.peek(), "Expect pattern."); err != nil {
+		panic(err)
+	}
+
+	return nil
+}
+
+// Parses the elements of a list pattern, maps to the CFG rules:
+// elements → pattern ( "," pattern )* ( "," rest )? ","? ;
+// rest     → "..." IDENTIFIER ;
+//
+// The opening bracket has already been consumed
+func (p *Parser) listPattern() expr.Pattern {
+	pattern := &expr.ListPattern{Bracket: p.previous(), Elements: []expr.Pattern{}}
+
+	for !p.check(token.RIGHT_BRACKET) {
+		if p.match(token.ELLIPSIS) {
+			pattern.Rest = p.consume(token.IDENTIFIER, "Expect name after '...' in pattern.")
+			p.match(token.COMMA)
+			break
+		}
+
+		pattern.Elements = append(pattern.Elements, p.pattern())
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHT_BRACKET, "Expect ']' after list pattern.")
+
+	return pattern
+}
+
+// Parses the fields of an instance pattern, maps to the CFG rule:
+// field → IDENTIFIER ( ":" pattern )? ;
+//
+// The class name, nil for patterns without one, and the opening brace have
+// already been consumed. A field without a pattern binds the property to a
+// variable with the same name
+func (p *Parser) instancePattern(class *token.Token) expr.Pattern {
+	pattern := &expr.InstancePattern{Brace: p.previous(), Fields: []*expr.FieldPattern{}}
+	if class != nil {
+		pattern.Class = &expr.Variable{Name: class}
+	}
+
+	for !p.check(token.RIGHT_BRACE) {
+		name := p.consume(token.IDENTIFIER, "Expect field name in pattern.")
+
+		field := &expr.FieldPattern{Name: name, Pattern: &expr.BindingPattern{Name: name}}
+		if p.match(token.COLON) {
+			field.Pattern = p.pattern()
+		}
+		pattern.Fields = append(pattern.Fields, field)
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHT_BRACE, "Expect '}' after instance pattern.")
+
+	return pattern
+}
+
+// Parses the elements of a list literal, allowing a trailing comma
+// The opening bracket has already been consumed
+func (p *Parser) list() expr.Expr {
+	elements := []expr.Expr{}
+
+	for !p.check(token.RIGHT_BRACKET) {
+		elements = append(elements, p.expression())
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHT_BRACKET, "Expect ']' after list elements.")
+
+	return &expr.List{Elements: elements}
+}
+
+// Parses the entries of a map literal, allowing a trailing comma
+// The opening brace has already been consumed
+func (p *Parser) mapLiteral() expr.Expr {
+	brace := p.previous()
+	keys, values := []expr.Expr{}, []expr.Expr{}
+
+	for !p.check(token.RIGHT_BRACE) {
+		keys = append(keys, p.expression())
+		p.consume(token.COLON, "Expect ':' after map key.")
+		values = append(values, p.expression())
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	p.consume(token.RIGHT_BRACE, "Expect '}' after map entries.")
+
+	return &expr.Map{Brace: brace, Keys: keys, Values: values}
+}
+
 // Check if the current token is any of the given types. If it does, consume it
 func (p *Parser) match(types ...token.Type) bool {
 	for _, t := range types {
@@ -219,6 +969,19 @@ func (p *Parser) check(t token.Type) bool {
 	return p.peek().Type == t
 }
 
+// Check if the token after the current one is of the given type
+func (p *Parser) checkNext(t token.Type) bool {
+	return p.checkAhead(1, t)
+}
+
+// Check if the token the given number of tokens after the current one is of the given type
+func (p *Parser) checkAhead(offset int, t token.Type) bool {
+	if p.isAtEnd() || p.current+offset >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[p.current+offset].Type == t
+}
+
 // Consume the current token and return it
 func (p *Parser) advance() *token.Token {
 	if !p.isAtEnd() {
@@ -227,6 +990,11 @@ func (p *Parser) advance() *token.Token {
 	return p.previous()
 }
 
+// AtEnd reports whether the parser has consumed all the tokens
+func (p *Parser) AtEnd() bool {
+	return p.isAtEnd()
+}
+
 // Check if we have reached the end of the token list
 func (p *Parser) isAtEnd() bool {
 	return p.peek().Type == token.EOF
@@ -257,7 +1025,8 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().Type {
-		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN:
+		case token.CLASS, token.TRAIT, token.FUN, token.VAR, token.CONST, token.LET, token.FOR, token.IF, token.WHILE,
+			token.PRINT, token.RETURN, token.THROW, token.TRY, token.YIELD, token.IMPORT, token.FROM:
 			return
 		}
 
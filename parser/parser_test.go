@@ -3,6 +3,7 @@ package parser
 import (
 	"golox/error"
 	"golox/expr"
+	"golox/stmt"
 	"golox/token"
 	"reflect"
 	"testing"
@@ -250,6 +251,318 @@ func TestParser_Expressions(t *testing.T) {
 				FalseBranch: &expr.Literal{Value: 3},
 			},
 		},
+		{
+			name: "Call Expression (f(1, x))",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "f"},
+				{Type: token.LEFT_PAREN, Literal: "("},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.COMMA, Literal: ","},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.RIGHT_PAREN, Literal: ")"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Call{
+				Callee: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "f"}},
+				Paren:  &token.Token{Type: token.RIGHT_PAREN, Literal: ")"},
+				Arguments: []expr.Expr{
+					&expr.Literal{Value: 1},
+					&expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+				},
+			},
+		},
+		{
+			name: "Assignment with Logical Operators (x = a or b and c)",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.EQUAL, Literal: "="},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.OR, Lexeme: "or"},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.AND, Lexeme: "and"},
+				{Type: token.IDENTIFIER, Lexeme: "c"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Assign{
+				Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"},
+				Value: &expr.Logical{
+					Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+					Operator: &token.Token{Type: token.OR, Lexeme: "or"},
+					Right: &expr.Logical{
+						Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}},
+						Operator: &token.Token{Type: token.AND, Lexeme: "and"},
+						Right:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "c"}},
+					},
+				},
+			},
+		},
+		{
+			name: "Right-associative power (-2 ** 3 ** 2)",
+			tokens: []token.Token{
+				{Type: token.MINUS, Lexeme: "-"},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.STAR_STAR, Lexeme: "**"},
+				{Type: token.NUMBER, Literal: 3},
+				{Type: token.STAR_STAR, Lexeme: "**"},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.EOF},
+			},
+			expected: &expr.Unary{
+				Operator: &token.Token{Type: token.MINUS, Lexeme: "-"},
+				Right: &expr.Binary{
+					Left:     &expr.Literal{Value: 2},
+					Operator: &token.Token{Type: token.STAR_STAR, Lexeme: "**"},
+					Right: &expr.Binary{
+						Left:     &expr.Literal{Value: 3},
+						Operator: &token.Token{Type: token.STAR_STAR, Lexeme: "**"},
+						Right:    &expr.Literal{Value: 2},
+					},
+				},
+			},
+		},
+		{
+			name: "Bitwise precedence (a | b ^ c & d << 1 + 1)",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.PIPE, Lexeme: "|"},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.CARET, Lexeme: "^"},
+				{Type: token.IDENTIFIER, Lexeme: "c"},
+				{Type: token.AMPERSAND, Lexeme: "&"},
+				{Type: token.IDENTIFIER, Lexeme: "d"},
+				{Type: token.LESS_LESS, Lexeme: "<<"},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.PLUS, Lexeme: "+"},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.EOF},
+			},
+			expected: &expr.Binary{
+				Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+				Operator: &token.Token{Type: token.PIPE, Lexeme: "|"},
+				Right: &expr.Binary{
+					Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}},
+					Operator: &token.Token{Type: token.CARET, Lexeme: "^"},
+					Right: &expr.Binary{
+						Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "c"}},
+						Operator: &token.Token{Type: token.AMPERSAND, Lexeme: "&"},
+						Right: &expr.Binary{
+							Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "d"}},
+							Operator: &token.Token{Type: token.LESS_LESS, Lexeme: "<<"},
+							Right: &expr.Binary{
+								Left:     &expr.Literal{Value: 1},
+								Operator: &token.Token{Type: token.PLUS, Lexeme: "+"},
+								Right:    &expr.Literal{Value: 1},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Range precedence (i < 0..<n | 1)",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "i"},
+				{Type: token.LESS, Lexeme: "<"},
+				{Type: token.NUMBER, Literal: 0},
+				{Type: token.DOT_DOT_LESS, Lexeme: "..<"},
+				{Type: token.IDENTIFIER, Lexeme: "n"},
+				{Type: token.PIPE, Lexeme: "|"},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.EOF},
+			},
+			expected: &expr.Binary{
+				Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "i"}},
+				Operator: &token.Token{Type: token.LESS, Lexeme: "<"},
+				Right: &expr.Binary{
+					Left:     &expr.Literal{Value: 0},
+					Operator: &token.Token{Type: token.DOT_DOT_LESS, Lexeme: "..<"},
+					Right: &expr.Binary{
+						Left:     &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "n"}},
+						Operator: &token.Token{Type: token.PIPE, Lexeme: "|"},
+						Right:    &expr.Literal{Value: 1},
+					},
+				},
+			},
+		},
+		{
+			name: "Arrow function ((a, b) => a)",
+			tokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Lambda{
+				Keyword: &token.Token{Type: token.ARROW, Lexeme: "=>"},
+				Params:  []*expr.Param{{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}}, {Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}}},
+				Body: &stmt.Block{Statements: []stmt.Stmt{&stmt.Return{
+					Keyword: &token.Token{Type: token.ARROW, Lexeme: "=>"},
+					Value:   &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+				}}},
+			},
+		},
+		{
+			name: "Annotated arrow function ((a: number): number => a)",
+			tokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COLON, Lexeme: ":"},
+				{Type: token.IDENTIFIER, Lexeme: "number"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.COLON, Lexeme: ":"},
+				{Type: token.IDENTIFIER, Lexeme: "number"},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Lambda{
+				Keyword: &token.Token{Type: token.ARROW, Lexeme: "=>"},
+				Params: []*expr.Param{{
+					Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"},
+					Type: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "number"}},
+				}},
+				ReturnType: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "number"}},
+				Body: &stmt.Block{Statements: []stmt.Stmt{&stmt.Return{
+					Keyword: &token.Token{Type: token.ARROW, Lexeme: "=>"},
+					Value:   &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+				}}},
+			},
+		},
+		{
+			name: "Lambda (fun () {})",
+			tokens: []token.Token{
+				{Type: token.FUN, Lexeme: "fun"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Lambda{
+				Keyword: &token.Token{Type: token.FUN, Lexeme: "fun"},
+				Params:  []*expr.Param{},
+				Body:    &stmt.Block{Statements: []stmt.Stmt{}},
+			},
+		},
+		{
+			name: "Lambda parameters (fun (a, b = 1, ...c) {})",
+			tokens: []token.Token{
+				{Type: token.FUN, Lexeme: "fun"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.EQUAL, Lexeme: "="},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.ELLIPSIS, Lexeme: "..."},
+				{Type: token.IDENTIFIER, Lexeme: "c"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Lambda{
+				Keyword: &token.Token{Type: token.FUN, Lexeme: "fun"},
+				Params: []*expr.Param{
+					{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+					{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}, Default: &expr.Literal{Value: 1}},
+					{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "c"}, Rest: true},
+				},
+				Body: &stmt.Block{Statements: []stmt.Stmt{}},
+			},
+		},
+		{
+			name: "Named arguments (f(1, b: 2))",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "f"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.COLON, Lexeme: ":"},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Call{
+				Callee:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "f"}},
+				Paren:     &token.Token{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				Arguments: []expr.Expr{&expr.Literal{Value: 1}},
+				Named: []*expr.NamedArgument{
+					{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}, Value: &expr.Literal{Value: 2}},
+				},
+			},
+		},
+		{
+			name: "Grouping is not an arrow function ((a))",
+			tokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Grouping{
+				Expression: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+			},
+		},
+		{
+			name: "Compound assignment (xs[0] += 2)",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "xs"},
+				{Type: token.LEFT_BRACKET, Lexeme: "["},
+				{Type: token.NUMBER, Literal: 0},
+				{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+				{Type: token.PLUS_EQUAL, Lexeme: "+="},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.EOF},
+			},
+			expected: &expr.CompoundAssign{
+				Target: &expr.Index{
+					Object:  &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "xs"}},
+					Bracket: &token.Token{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+					Index:   &expr.Literal{Value: 0},
+				},
+				Operator: &token.Token{Type: token.PLUS_EQUAL, Lexeme: "+="},
+				Value:    &expr.Literal{Value: 2},
+			},
+		},
+		{
+			name: "Prefix and postfix updates (-++a.b - c--)",
+			tokens: []token.Token{
+				{Type: token.MINUS, Lexeme: "-"},
+				{Type: token.PLUS_PLUS, Lexeme: "++"},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.DOT, Lexeme: "."},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.MINUS, Lexeme: "-"},
+				{Type: token.IDENTIFIER, Lexeme: "c"},
+				{Type: token.MINUS_MINUS, Lexeme: "--"},
+				{Type: token.EOF},
+			},
+			expected: &expr.Binary{
+				Left: &expr.Unary{
+					Operator: &token.Token{Type: token.MINUS, Lexeme: "-"},
+					Right: &expr.Update{
+						Target: &expr.Get{
+							Object: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}},
+							Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "b"},
+						},
+						Operator: &token.Token{Type: token.PLUS_PLUS, Lexeme: "++"},
+						Prefix:   true,
+					},
+				},
+				Operator: &token.Token{Type: token.MINUS, Lexeme: "-"},
+				Right: &expr.Update{
+					Target:   &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "c"}},
+					Operator: &token.Token{Type: token.MINUS_MINUS, Lexeme: "--"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,6 +623,104 @@ func TestParser_InvalidCode(t *testing.T) {
 			},
 			expectedErr: "Expect ':' after true branch of ternary expression.",
 		},
+		{
+			name: "Invalid assignment target (1 = 2)",
+			tokens: []token.Token{
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.EQUAL, Literal: "="},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.EOF},
+			},
+			expectedErr: "Invalid assignment target.",
+		},
+		{
+			name: "Invalid compound assignment target (a + b -= 1)",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.PLUS, Lexeme: "+"},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.MINUS_EQUAL, Lexeme: "-="},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.EOF},
+			},
+			expectedErr: "Invalid assignment target.",
+		},
+		{
+			name: "Invalid increment target (1++)",
+			tokens: []token.Token{
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.PLUS_PLUS, Lexeme: "++"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Invalid increment target.",
+		},
+		{
+			name: "Lambda without a body (fun (a))",
+			tokens: []token.Token{
+				{Type: token.FUN, Lexeme: "fun"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect '{' before lambda body.",
+		},
+		{
+			name: "Invalid arrow function parameter ((1) => 1)",
+			tokens: []token.Token{
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect parameter name.",
+		},
+		{
+			name: "Rest parameter not last (fun (...a, b) {})",
+			tokens: []token.Token{
+				{Type: token.FUN, Lexeme: "fun"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.ELLIPSIS, Lexeme: "..."},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Rest parameter must be the last parameter.",
+		},
+		{
+			name: "Required parameter after a default (fun (a = 1, b) {})",
+			tokens: []token.Token{
+				{Type: token.FUN, Lexeme: "fun"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.EQUAL, Lexeme: "="},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Parameter without a default value can't follow one with a default value.",
+		},
+		{
+			name: "Positional after named argument (f(a: 1, 2))",
+			tokens: []token.Token{
+				{Type: token.IDENTIFIER, Lexeme: "f"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COLON, Lexeme: ":"},
+				{Type: token.NUMBER, Literal: 1},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.NUMBER, Literal: 2},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Positional argument can't follow named arguments.",
+		},
 	}
 
 	for _, tt := range tests {
@@ -334,3 +745,1111 @@ func TestParser_InvalidCode(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Program(t *testing.T) {
+	tokens := []token.Token{
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.NUMBER, Literal: 1},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.WHILE, Lexeme: "while"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.PRINT, Lexeme: "print"},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Var{
+			Name:        &token.Token{Type: token.IDENTIFIER, Lexeme: "x"},
+			Initializer: &expr.Literal{Value: 1},
+		},
+		&stmt.While{
+			Condition: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+			Body: &stmt.Print{
+				Expression: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}},
+			},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_ForLoop(t *testing.T) {
+	// for (;i;i = 1) continue;
+	tokens := []token.Token{
+		{Type: token.FOR, Lexeme: "for"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.IDENTIFIER, Lexeme: "i"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.IDENTIFIER, Lexeme: "i"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.NUMBER, Literal: 1},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.CONTINUE, Lexeme: "continue"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.While{
+			Condition: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "i"}},
+			Body:      &stmt.Continue{Keyword: &token.Token{Type: token.CONTINUE, Lexeme: "continue"}},
+			Increment: &expr.Assign{
+				Name:  &token.Token{Type: token.IDENTIFIER, Lexeme: "i"},
+				Value: &expr.Literal{Value: 1},
+			},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_ForInLoop(t *testing.T) {
+	// for (var k, v in m) print v;
+	tokens := []token.Token{
+		{Type: token.FOR, Lexeme: "for"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "k"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.IDENTIFIER, Lexeme: "v"},
+		{Type: token.IN, Lexeme: "in"},
+		{Type: token.IDENTIFIER, Lexeme: "m"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.PRINT, Lexeme: "print"},
+		{Type: token.IDENTIFIER, Lexeme: "v"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.ForIn{
+			Keyword:  &token.Token{Type: token.IN, Lexeme: "in"},
+			Key:      &token.Token{Type: token.IDENTIFIER, Lexeme: "k"},
+			Name:     &token.Token{Type: token.IDENTIFIER, Lexeme: "v"},
+			Iterable: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "m"}},
+			Body:     &stmt.Print{Expression: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "v"}}},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_TryStatement(t *testing.T) {
+	// try { throw 1; } catch (e) {} finally {}
+	tokens := []token.Token{
+		{Type: token.TRY, Lexeme: "try"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.THROW, Lexeme: "throw"},
+		{Type: token.NUMBER, Literal: 1},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.CATCH, Lexeme: "catch"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.IDENTIFIER, Lexeme: "e"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.FINALLY, Lexeme: "finally"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Try{
+			Keyword: &token.Token{Type: token.TRY, Lexeme: "try"},
+			Body: []stmt.Stmt{&stmt.Throw{
+				Keyword: &token.Token{Type: token.THROW, Lexeme: "throw"},
+				Value:   &expr.Literal{Value: 1},
+			}},
+			CatchName: &token.Token{Type: token.IDENTIFIER, Lexeme: "e"},
+			Catch:     []stmt.Stmt{},
+			Finally:   []stmt.Stmt{},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_TryStatementErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      []token.Token
+		expectedErr string
+	}{
+		{
+			name: "Try without catch or finally (try {})",
+			tokens: []token.Token{
+				{Type: token.TRY, Lexeme: "try"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect 'catch' or 'finally' after try block.",
+		},
+		{
+			name: "Catch without a variable (try {} catch {})",
+			tokens: []token.Token{
+				{Type: token.TRY, Lexeme: "try"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.CATCH, Lexeme: "catch"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect '(' after 'catch'.",
+		},
+		{
+			name: "Throw without a value (throw;)",
+			tokens: []token.Token{
+				{Type: token.THROW, Lexeme: "throw"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect expression.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := New(tt.tokens).ParseProgram()
+
+			if len(errs) == 0 || errs[0].Message != tt.expectedErr {
+				t.Errorf("Expected error message '%s' but got %v", tt.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestParser_MatchStatement(t *testing.T) {
+	// match (x) { 1 | -2 => a, [b, ...c] if b => b, P{y} => {} }
+	tokens := []token.Token{
+		{Type: token.MATCH, Lexeme: "match"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.PIPE, Lexeme: "|"},
+		{Type: token.MINUS, Lexeme: "-"},
+		{Type: token.NUMBER, Lexeme: "2", Literal: 2.0},
+		{Type: token.ARROW, Lexeme: "=>"},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.ELLIPSIS, Lexeme: "..."},
+		{Type: token.IDENTIFIER, Lexeme: "c"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.IF, Lexeme: "if"},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.ARROW, Lexeme: "=>"},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.IDENTIFIER, Lexeme: "P"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.IDENTIFIER, Lexeme: "y"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.ARROW, Lexeme: "=>"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	identifier := func(name string) *token.Token {
+		return &token.Token{Type: token.IDENTIFIER, Lexeme: name}
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Expression{Expression: &expr.Match{
+			Keyword: &token.Token{Type: token.MATCH, Lexeme: "match"},
+			Subject: &expr.Variable{Name: identifier("x")},
+			Arms: []*expr.MatchArm{
+				{
+					Pattern: &expr.AlternativePattern{Alternatives: []expr.Pattern{
+						&expr.LiteralPattern{Token: &token.Token{Type: token.NUMBER, Lexeme: "1", Literal: 1.0}, Value: 1.0},
+						&expr.LiteralPattern{Token: &token.Token{Type: token.MINUS, Lexeme: "-"}, Value: -2.0},
+					}},
+					Body: &expr.Variable{Name: identifier("a")},
+				},
+				{
+					Pattern: &expr.ListPattern{
+						Bracket:  &token.Token{Type: token.LEFT_BRACKET, Lexeme: "["},
+						Elements: []expr.Pattern{&expr.BindingPattern{Name: identifier("b")}},
+						Rest:     identifier("c"),
+					},
+					Guard: &expr.Variable{Name: identifier("b")},
+					Body:  &expr.Variable{Name: identifier("b")},
+				},
+				{
+					Pattern: &expr.InstancePattern{
+						Class:  &expr.Variable{Name: identifier("P")},
+						Brace:  &token.Token{Type: token.LEFT_BRACE, Lexeme: "{"},
+						Fields: []*expr.FieldPattern{{Name: identifier("y"), Pattern: &expr.BindingPattern{Name: identifier("y")}}},
+					},
+					Block: &stmt.Block{Statements: []stmt.Stmt{}},
+				},
+			},
+		}},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_MatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      []token.Token
+		expectedErr string
+	}{
+		{
+			name: "No arms (match (x) {})",
+			tokens: []token.Token{
+				{Type: token.MATCH, Lexeme: "match"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect at least one match arm.",
+		},
+		{
+			name: "Missing arrow (match (x) { 1 1 })",
+			tokens: []token.Token{
+				{Type: token.MATCH, Lexeme: "match"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect '=>' after match pattern.",
+		},
+		{
+			name: "Expression as a pattern (match (x) { (1) => 1 })",
+			tokens: []token.Token{
+				{Type: token.MATCH, Lexeme: "match"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect pattern.",
+		},
+		{
+			name: "Missing comma between arms (match (x) { 1 => 1 2 => 2 })",
+			tokens: []token.Token{
+				{Type: token.MATCH, Lexeme: "match"},
+				{Type: token.LEFT_PAREN, Lexeme: "("},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				{Type: token.LEFT_BRACE, Lexeme: "{"},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.NUMBER, Lexeme: "2", Literal: 2.0},
+				{Type: token.ARROW, Lexeme: "=>"},
+				{Type: token.NUMBER, Lexeme: "2", Literal: 2.0},
+				{Type: token.RIGHT_BRACE, Lexeme: "}"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect '}' after match arms.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := New(tt.tokens).ParseProgram()
+
+			if len(errs) == 0 || errs[0].Message != tt.expectedErr {
+				t.Errorf("Expected error message '%s' but got %v", tt.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestParser_ImportStatement(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      []token.Token
+		expected    stmt.Stmt
+		expectedErr string
+	}{
+		{
+			name: "Import a module (import \"m\" as m;)",
+			tokens: []token.Token{
+				{Type: token.IMPORT, Lexeme: "import"},
+				{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				{Type: token.AS, Lexeme: "as"},
+				{Type: token.IDENTIFIER, Lexeme: "m"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expected: &stmt.Import{
+				Keyword: &token.Token{Type: token.IMPORT, Lexeme: "import"},
+				Path:    &token.Token{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				Alias:   &token.Token{Type: token.IDENTIFIER, Lexeme: "m"},
+			},
+		},
+		{
+			name: "Import names (from \"m\" import a, b;)",
+			tokens: []token.Token{
+				{Type: token.FROM, Lexeme: "from"},
+				{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				{Type: token.IMPORT, Lexeme: "import"},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.COMMA, Lexeme: ","},
+				{Type: token.IDENTIFIER, Lexeme: "b"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expected: &stmt.Import{
+				Keyword: &token.Token{Type: token.FROM, Lexeme: "from"},
+				Path:    &token.Token{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				Names: []*token.Token{
+					{Type: token.IDENTIFIER, Lexeme: "a"},
+					{Type: token.IDENTIFIER, Lexeme: "b"},
+				},
+			},
+		},
+		{
+			name: "Import without an alias (import \"m\";)",
+			tokens: []token.Token{
+				{Type: token.IMPORT, Lexeme: "import"},
+				{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect 'as' after module path.",
+		},
+		{
+			name: "Import without names (from \"m\" import;)",
+			tokens: []token.Token{
+				{Type: token.FROM, Lexeme: "from"},
+				{Type: token.STRING, Lexeme: "\"m\"", Literal: "m"},
+				{Type: token.IMPORT, Lexeme: "import"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect name to import.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, errs := New(tt.tokens).ParseProgram()
+
+			if tt.expectedErr != "" {
+				if len(errs) == 0 || errs[0].Message != tt.expectedErr {
+					t.Errorf("Expected error message '%s' but got %v", tt.expectedErr, errs)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatalf("Expected no errors but got %v", errs)
+			}
+
+			if !reflect.DeepEqual(statements, []stmt.Stmt{tt.expected}) {
+				t.Errorf("Expected: %#v\nGot: %#v", tt.expected, statements[0])
+			}
+		})
+	}
+}
+
+func TestParser_ClassMembers(t *testing.T) {
+	// class A { class s() {} g {} set g(v) {} m() {} }
+	tokens := []token.Token{
+		{Type: token.CLASS, Lexeme: "class"},
+		{Type: token.IDENTIFIER, Lexeme: "A"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.CLASS, Lexeme: "class"},
+		{Type: token.IDENTIFIER, Lexeme: "s"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.IDENTIFIER, Lexeme: "g"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.IDENTIFIER, Lexeme: "set"},
+		{Type: token.IDENTIFIER, Lexeme: "g"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.IDENTIFIER, Lexeme: "v"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.IDENTIFIER, Lexeme: "m"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	function := func(name string, params ...string) *stmt.Function {
+		f := &stmt.Function{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: name}, Params: []*expr.Param{}, Body: []stmt.Stmt{}}
+		for _, param := range params {
+			f.Params = append(f.Params, &expr.Param{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: param}})
+		}
+		return f
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Class{
+			Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "A"},
+			Traits: []*expr.Variable{},
+			Members: stmt.Members{
+				Methods:       []*stmt.Function{function("m")},
+				StaticMethods: []*stmt.Function{function("s")},
+				Getters:       []*stmt.Function{function("g")},
+				Setters:       []*stmt.Function{function("g", "v")},
+			},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_Traits(t *testing.T) {
+	// trait T { m() {} } class A < B with T, U {}
+	tokens := []token.Token{
+		{Type: token.TRAIT, Lexeme: "trait"},
+		{Type: token.IDENTIFIER, Lexeme: "T"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.IDENTIFIER, Lexeme: "m"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.CLASS, Lexeme: "class"},
+		{Type: token.IDENTIFIER, Lexeme: "A"},
+		{Type: token.LESS, Lexeme: "<"},
+		{Type: token.IDENTIFIER, Lexeme: "B"},
+		{Type: token.WITH, Lexeme: "with"},
+		{Type: token.IDENTIFIER, Lexeme: "T"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.IDENTIFIER, Lexeme: "U"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	empty := func() stmt.Members {
+		return stmt.Members{
+			Methods:       []*stmt.Function{},
+			StaticMethods: []*stmt.Function{},
+			Getters:       []*stmt.Function{},
+			Setters:       []*stmt.Function{},
+		}
+	}
+
+	trait := &stmt.Trait{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "T"}, Members: empty()}
+	trait.Members.Methods = []*stmt.Function{{
+		Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "m"},
+		Params: []*expr.Param{},
+		Body:   []stmt.Stmt{},
+	}}
+
+	expected := []stmt.Stmt{
+		trait,
+		&stmt.Class{
+			Name:       &token.Token{Type: token.IDENTIFIER, Lexeme: "A"},
+			Superclass: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "B"}},
+			Traits: []*expr.Variable{
+				{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "T"}},
+				{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "U"}},
+			},
+			Members: empty(),
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_SetterWithoutParameter(t *testing.T) {
+	// class A { set g() {} }
+	tokens := []token.Token{
+		{Type: token.CLASS, Lexeme: "class"},
+		{Type: token.IDENTIFIER, Lexeme: "A"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.IDENTIFIER, Lexeme: "set"},
+		{Type: token.IDENTIFIER, Lexeme: "g"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	_, errs := New(tokens).ParseProgram()
+
+	if len(errs) == 0 || errs[0].Message != "A setter must have exactly one parameter." {
+		t.Errorf("Expected a setter parameter error but got %v", errs)
+	}
+}
+
+func TestParser_ProgramErrorRecovery(t *testing.T) {
+	// var = 1; print 2
+	tokens := []token.Token{
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.NUMBER, Literal: 1},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.PRINT, Lexeme: "print"},
+		{Type: token.NUMBER, Literal: 2},
+		{Type: token.EOF},
+	}
+
+	_, errs := New(tokens).ParseProgram()
+
+	expected := []string{"Expect variable name.", "Expect ';' after value."}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors but got %v", len(expected), errs)
+	}
+
+	for idx, err := range errs {
+		if err.Message != expected[idx] {
+			t.Errorf("Expected error message '%s' but got '%s'", expected[idx], err.Message)
+		}
+	}
+}
+
+func TestParser_Destructuring(t *testing.T) {
+	identifier := func(name string) *token.Token {
+		return &token.Token{Type: token.IDENTIFIER, Lexeme: name}
+	}
+
+	// var [a, {b}] = x; [a, _] = [b, a];
+	tokens := []token.Token{
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.IDENTIFIER, Lexeme: "_"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	equals := &token.Token{Type: token.EQUAL, Lexeme: "="}
+	expected := []stmt.Stmt{
+		&stmt.Var{
+			Pattern: &expr.ListPattern{
+				Bracket: &token.Token{Type: token.LEFT_BRACKET, Lexeme: "["},
+				Elements: []expr.Pattern{
+					&expr.BindingPattern{Name: identifier("a")},
+					&expr.InstancePattern{
+						Brace:  &token.Token{Type: token.LEFT_BRACE, Lexeme: "{"},
+						Fields: []*expr.FieldPattern{{Name: identifier("b"), Pattern: &expr.BindingPattern{Name: identifier("b")}}},
+					},
+				},
+			},
+			Initializer: &expr.Variable{Name: identifier("x")},
+		},
+		&stmt.Expression{Expression: &expr.Destructure{
+			Pattern: &expr.ListPattern{
+				Bracket: equals,
+				Elements: []expr.Pattern{
+					&expr.TargetPattern{Target: &expr.Variable{Name: identifier("a")}},
+					&expr.WildcardPattern{Token: identifier("_")},
+				},
+			},
+			Equals: equals,
+			Value: &expr.List{
+				Elements: []expr.Expr{&expr.Variable{Name: identifier("b")}, &expr.Variable{Name: identifier("a")}},
+			},
+		}},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_DestructuringErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokens      []token.Token
+		expectedErr string
+	}{
+		{
+			name: "Missing initializer (var [a];)",
+			tokens: []token.Token{
+				{Type: token.VAR, Lexeme: "var"},
+				{Type: token.LEFT_BRACKET, Lexeme: "["},
+				{Type: token.IDENTIFIER, Lexeme: "a"},
+				{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Expect '=' after destructuring pattern.",
+		},
+		{
+			name: "Literal target ([1] = x;)",
+			tokens: []token.Token{
+				{Type: token.LEFT_BRACKET, Lexeme: "["},
+				{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+				{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+				{Type: token.EQUAL, Lexeme: "="},
+				{Type: token.IDENTIFIER, Lexeme: "x"},
+				{Type: token.SEMICOLON, Lexeme: ";"},
+				{Type: token.EOF},
+			},
+			expectedErr: "Invalid assignment target.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := New(tt.tokens).ParseProgram()
+
+			if len(errs) == 0 || errs[0].Message != tt.expectedErr {
+				t.Errorf("Expected error message '%s' but got %v", tt.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestParser_Spawn(t *testing.T) {
+	// spawn f(1);
+	tokens := []token.Token{
+		{Type: token.SPAWN, Lexeme: "spawn"},
+		{Type: token.IDENTIFIER, Lexeme: "f"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Expression{Expression: &expr.Spawn{
+			Keyword: &token.Token{Type: token.SPAWN, Lexeme: "spawn"},
+			Call: &expr.Call{
+				Callee:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "f"}},
+				Paren:     &token.Token{Type: token.RIGHT_PAREN, Lexeme: ")"},
+				Arguments: []expr.Expr{&expr.Literal{Value: 1.0}},
+			},
+		}},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+
+	// spawn f;
+	_, errs = New([]token.Token{
+		{Type: token.SPAWN, Lexeme: "spawn"},
+		{Type: token.IDENTIFIER, Lexeme: "f"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}).ParseProgram()
+
+	if len(errs) == 0 || errs[0].Message != "Expect a call after 'spawn'." {
+		t.Errorf("Expected a missing call error but got %v", errs)
+	}
+}
+
+func TestParser_Constants(t *testing.T) {
+	// const a = 1; let [b] = c;
+	tokens := []token.Token{
+		{Type: token.CONST, Lexeme: "const"},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.LET, Lexeme: "let"},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.IDENTIFIER, Lexeme: "c"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Var{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}, Initializer: &expr.Literal{Value: 1.0}, Constant: true},
+		&stmt.Var{
+			Pattern: &expr.ListPattern{
+				Bracket:  &token.Token{Type: token.LEFT_BRACKET, Lexeme: "["},
+				Elements: []expr.Pattern{&expr.BindingPattern{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}}},
+			},
+			Initializer: &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "c"}},
+			Constant:    true,
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+
+	// const a;
+	_, errs = New([]token.Token{
+		{Type: token.CONST, Lexeme: "const"},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}).ParseProgram()
+
+	if len(errs) == 0 || errs[0].Message != "Expect '=' after constant name." {
+		t.Errorf("Expected a missing initializer error but got %v", errs)
+	}
+}
+
+func TestParser_Generators(t *testing.T) {
+	// fun g() { yield 1; var f = fun () { return; }; yield; }
+	tokens := []token.Token{
+		{Type: token.FUN, Lexeme: "fun"},
+		{Type: token.IDENTIFIER, Lexeme: "g"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.YIELD, Lexeme: "yield"},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "f"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.FUN, Lexeme: "fun"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RETURN, Lexeme: "return"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.YIELD, Lexeme: "yield"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Function{
+			Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "g"},
+			Params: []*expr.Param{},
+			Body: []stmt.Stmt{
+				&stmt.Yield{Keyword: &token.Token{Type: token.YIELD, Lexeme: "yield"}, Value: &expr.Literal{Value: 1.0}},
+				&stmt.Var{
+					Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "f"},
+					Initializer: &expr.Lambda{
+						Keyword: &token.Token{Type: token.FUN, Lexeme: "fun"},
+						Params:  []*expr.Param{},
+						Body:    &stmt.Block{Statements: []stmt.Stmt{&stmt.Return{Keyword: &token.Token{Type: token.RETURN, Lexeme: "return"}}}},
+					},
+				},
+				&stmt.Yield{Keyword: &token.Token{Type: token.YIELD, Lexeme: "yield"}},
+			},
+			Generator: true,
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+}
+
+func TestParser_Async(t *testing.T) {
+	// async fun f() { return await g(); } var h = async fun () {};
+	tokens := []token.Token{
+		{Type: token.ASYNC, Lexeme: "async"},
+		{Type: token.FUN, Lexeme: "fun"},
+		{Type: token.IDENTIFIER, Lexeme: "f"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RETURN, Lexeme: "return"},
+		{Type: token.AWAIT, Lexeme: "await"},
+		{Type: token.IDENTIFIER, Lexeme: "g"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "h"},
+		{Type: token.EQUAL, Lexeme: "="},
+		{Type: token.ASYNC, Lexeme: "async"},
+		{Type: token.FUN, Lexeme: "fun"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Function{
+			Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "f"},
+			Params: []*expr.Param{},
+			Body: []stmt.Stmt{
+				&stmt.Return{
+					Keyword: &token.Token{Type: token.RETURN, Lexeme: "return"},
+					Value: &expr.Await{
+						Keyword: &token.Token{Type: token.AWAIT, Lexeme: "await"},
+						Value: &expr.Call{
+							Callee:    &expr.Variable{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "g"}},
+							Paren:     &token.Token{Type: token.RIGHT_PAREN, Lexeme: ")"},
+							Arguments: []expr.Expr{},
+						},
+					},
+				},
+			},
+			Async: true,
+		},
+		&stmt.Var{
+			Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "h"},
+			Initializer: &expr.Lambda{
+				Keyword: &token.Token{Type: token.FUN, Lexeme: "fun"},
+				Params:  []*expr.Param{},
+				Body:    &stmt.Block{Statements: []stmt.Stmt{}},
+				Async:   true,
+			},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+
+	// async 1;
+	_, errs = New([]token.Token{
+		{Type: token.ASYNC, Lexeme: "async"},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}).ParseProgram()
+
+	if len(errs) == 0 || errs[0].Message != "Expect function after 'async'." {
+		t.Errorf("Expected a missing function error but got %v", errs)
+	}
+}
+
+func TestParser_TypeAnnotations(t *testing.T) {
+	// var x: list[number] | null; fun f(a: string, ...b: any): bool {} class P { x: number; }
+	tokens := []token.Token{
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.IDENTIFIER, Lexeme: "list"},
+		{Type: token.LEFT_BRACKET, Lexeme: "["},
+		{Type: token.IDENTIFIER, Lexeme: "number"},
+		{Type: token.RIGHT_BRACKET, Lexeme: "]"},
+		{Type: token.PIPE, Lexeme: "|"},
+		{Type: token.NULL, Lexeme: "null"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.FUN, Lexeme: "fun"},
+		{Type: token.IDENTIFIER, Lexeme: "f"},
+		{Type: token.LEFT_PAREN, Lexeme: "("},
+		{Type: token.IDENTIFIER, Lexeme: "a"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.IDENTIFIER, Lexeme: "string"},
+		{Type: token.COMMA, Lexeme: ","},
+		{Type: token.ELLIPSIS, Lexeme: "..."},
+		{Type: token.IDENTIFIER, Lexeme: "b"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.IDENTIFIER, Lexeme: "any"},
+		{Type: token.RIGHT_PAREN, Lexeme: ")"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.IDENTIFIER, Lexeme: "bool"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.CLASS, Lexeme: "class"},
+		{Type: token.IDENTIFIER, Lexeme: "P"},
+		{Type: token.LEFT_BRACE, Lexeme: "{"},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.IDENTIFIER, Lexeme: "number"},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.RIGHT_BRACE, Lexeme: "}"},
+		{Type: token.EOF},
+	}
+
+	expected := []stmt.Stmt{
+		&stmt.Var{
+			Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"},
+			Type: &expr.Type{Alternatives: []*expr.Type{
+				{
+					Name:      &token.Token{Type: token.IDENTIFIER, Lexeme: "list"},
+					Arguments: []*expr.Type{{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "number"}}},
+				},
+				{Name: &token.Token{Type: token.NULL, Lexeme: "null"}},
+			}},
+		},
+		&stmt.Function{
+			Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "f"},
+			Params: []*expr.Param{
+				{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "a"}, Type: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "string"}}},
+				{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "b"}, Type: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "any"}}, Rest: true},
+			},
+			Body:       []stmt.Stmt{},
+			ReturnType: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "bool"}},
+		},
+		&stmt.Class{
+			Name:   &token.Token{Type: token.IDENTIFIER, Lexeme: "P"},
+			Traits: []*expr.Variable{},
+			Members: stmt.Members{
+				Methods:       []*stmt.Function{},
+				StaticMethods: []*stmt.Function{},
+				Getters:       []*stmt.Function{},
+				Setters:       []*stmt.Function{},
+				Fields: []*stmt.Field{
+					{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "x"}, Type: &expr.Type{Name: &token.Token{Type: token.IDENTIFIER, Lexeme: "number"}}},
+				},
+			},
+		},
+	}
+
+	statements, errs := New(tokens).ParseProgram()
+
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors but got %v", errs)
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected: %#v\nGot: %#v", expected, statements)
+	}
+
+	// var x: 1;
+	_, errs = New([]token.Token{
+		{Type: token.VAR, Lexeme: "var"},
+		{Type: token.IDENTIFIER, Lexeme: "x"},
+		{Type: token.COLON, Lexeme: ":"},
+		{Type: token.NUMBER, Lexeme: "1", Literal: 1.0},
+		{Type: token.SEMICOLON, Lexeme: ";"},
+		{Type: token.EOF},
+	}).ParseProgram()
+
+	if len(errs) == 0 || errs[0].Message != "Expect type." {
+		t.Errorf("Expected a missing type error but got %v", errs)
+	}
+}
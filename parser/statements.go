@@ -0,0 +1,606 @@
+package parser
+
+import (
+	"golox/error"
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+)
+
+// Maximum number of parameters a function can declare
+const maxParameters = 255
+
+// Parses a single declaration, recovering the parse error if one is raised
+func (p *Parser) safeDeclaration() (declaration stmt.Stmt, err *error.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			parseErr, ok := r.(*error.Error)
+			if !ok {
+				panic(r)
+			}
+			err = parseErr
+		}
+	}()
+
+	return p.declaration(), nil
+}
+
+// Declaration maps to the CFG rule:
+// declaration → classDecl | traitDecl | funDecl | varDecl | importDecl | statement ;
+//
+// Constants are declared like variables, with "const" or "let" instead of "var"
+func (p *Parser) declaration() stmt.Stmt {
+	switch {
+	case p.match(token.CLASS):
+		return p.classDeclaration()
+	case p.match(token.TRAIT):
+		return p.traitDeclaration()
+	case p.check(token.FUN) && p.checkNext(token.IDENTIFIER):
+		// A "fun" without a name starts a lambda expression statement
+		p.advance()
+		return p.function("function")
+	case p.check(token.ASYNC) && p.checkNext(token.FUN) && p.checkAhead(2, token.IDENTIFIER):
+		p.advance()
+		p.advance()
+		function := p.function("function")
+		function.Async = true
+		return function
+	case p.match(token.VAR, token.CONST, token.LET):
+		return p.varDeclaration()
+	case p.match(token.IMPORT):
+		return p.importDeclaration()
+	case p.match(token.FROM):
+		return p.fromImportDeclaration()
+	}
+
+	return p.statement()
+}
+
+// ClassDeclaration maps to the CFG rule:
+// classDecl → "class" IDENTIFIER ( "<" IDENTIFIER )? ( "with" IDENTIFIER ( "," IDENTIFIER )* )? "{" member* "}" ;
+func (p *Parser) classDeclaration() stmt.Stmt {
+	name := p.consume(token.IDENTIFIER, "Expect class name.")
+
+	var superclass *expr.Variable
+	if p.match(token.LESS) {
+		p.consume(token.IDENTIFIER, "Expect superclass name.")
+		superclass = &expr.Variable{Name: p.previous()}
+	}
+
+	traits := []*expr.Variable{}
+	if p.match(token.WITH) {
+		for {
+			traits = append(traits, &expr.Variable{Name: p.consume(token.IDENTIFIER, "Expect trait name.")})
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+
+	return &stmt.Class{Name: name, Superclass: superclass, Traits: traits, Members: p.members("class")}
+}
+
+// TraitDeclaration maps to the CFG rule: traitDecl → "trait" IDENTIFIER "{" member* "}" ;
+func (p *Parser) traitDeclaration() stmt.Stmt {
+	name := p.consume(token.IDENTIFIER, "Expect trait name.")
+
+	return &stmt.Trait{Name: name, Members: p.members("trait")}
+}
+
+// Members maps to the CFG rules:
+// member → "class"? "async"? function | getter | setter | field ;
+// getter → IDENTIFIER block ;
+// setter → "set" IDENTIFIER "(" IDENTIFIER ")" block ;
+// field  → IDENTIFIER ":" type ";" ;
+//
+// The kind tells whether we are parsing a class or a trait body, for the error
+// messages. The "set" of a setter is not a keyword, a method can still be called set
+func (p *Parser) members(kind string) stmt.Members {
+	p.consume(token.LEFT_BRACE, "Expect '{' before "+kind+" body.")
+
+	members := stmt.Members{
+		Methods:       []*stmt.Function{},
+		StaticMethods: []*stmt.Function{},
+		Getters:       []*stmt.Function{},
+		Setters:       []*stmt.Function{},
+	}
+
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		switch {
+		case p.match(token.CLASS):
+			async := p.match(token.ASYNC)
+			method := p.function("static method")
+			method.Async = async
+			members.StaticMethods = append(members.StaticMethods, method)
+		case p.match(token.ASYNC):
+			method := p.function("method")
+			method.Async = true
+			members.Methods = append(members.Methods, method)
+		case p.check(token.IDENTIFIER) && p.peek().Lexeme == "set" && p.checkNext(token.IDENTIFIER):
+			p.advance()
+			members.Setters = append(members.Setters, p.setter())
+		case p.check(token.IDENTIFIER) && p.checkNext(token.COLON):
+			name := p.advance()
+			p.advance()
+			members.Fields = append(members.Fields, &stmt.Field{Name: name, Type: p.typeAnnotation()})
+			p.consume(token.SEMICOLON, "Expect ';' after field type.")
+		case p.check(token.IDENTIFIER) && p.checkNext(token.LEFT_BRACE):
+			getter := p.advance()
+			p.advance()
+			body, generator := p.functionBody(p.block)
+			members.Getters = append(members.Getters, &stmt.Function{Name: getter, Params: []*expr.Param{}, Body: body, Generator: generator})
+		default:
+			members.Methods = append(members.Methods, p.function("method"))
+		}
+	}
+
+	p.consume(token.RIGHT_BRACE, "Expect '}' after "+kind+" body.")
+
+	return members
+}
+
+// Parses a setter after the "set", which must have exactly one parameter
+func (p *Parser) setter() *stmt.Function {
+	setter := p.function("setter")
+
+	if len(setter.Params) != 1 || setter.Params[0].Default != nil || setter.Params[0].Rest {
+		if err := parseError(setter.Name, "A setter must have exactly one parameter."); err != nil {
+			panic(err)
+		}
+	}
+
+	return setter
+}
+
+// Function maps to the CFG rule: function → IDENTIFIER "(" parameters? ")" ( ":" type )? block ;
+// The kind tells whether we are parsing a function or a method, for the error messages
+func (p *Parser) function(kind string) *stmt.Function {
+	name := p.consume(token.IDENTIFIER, "Expect "+kind+" name.")
+
+	p.consume(token.LEFT_PAREN, "Expect '(' after "+kind+" name.")
+	params := p.parameters()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	returnType := p.returnType()
+	p.consume(token.LEFT_BRACE, "Expect '{' before "+kind+" body.")
+
+	body, generator := p.functionBody(p.block)
+
+	return &stmt.Function{Name: name, Params: params, Body: body, Generator: generator, ReturnType: returnType}
+}
+
+// Parses the optional return type annotation after the parameters of a function
+func (p *Parser) returnType() *expr.Type {
+	if !p.match(token.COLON) {
+		return nil
+	}
+
+	return p.typeAnnotation()
+}
+
+// Parses the body of a function with the given rule. The function is a generator
+// if a yield statement appears in its body, outside of the functions nested in it
+func (p *Parser) functionBody(rule func() []stmt.Stmt) (body []stmt.Stmt, generator bool) {
+	enclosing := p.yielded
+	p.yielded = false
+	defer func() {
+		p.yielded = enclosing
+	}()
+
+	body = rule()
+
+	return body, p.yielded
+}
+
+// Parameters maps to the CFG rules:
+// parameters → parameter ( "," parameter )* ;
+// parameter  → "..." IDENTIFIER ( ":" type )? | IDENTIFIER ( ":" type )? ( "=" expression )? ;
+//
+// An empty list is returned when the closing parenthesis follows immediately.
+// Required parameters can't follow the ones with a default value, and the rest
+// parameter must be the last one
+func (p *Parser) parameters() []*expr.Param {
+	params := []*expr.Param{}
+	if p.check(token.RIGHT_PAREN) {
+		return params
+	}
+
+	hasDefault := false
+	for {
+		if len(params) >= maxParameters {
+			if err := parseError(p.peek(), "Can't have more than 255 parameters."); err != nil {
+				panic(err)
+			}
+		}
+
+		if p.match(token.ELLIPSIS) {
+			name := p.consume(token.IDENTIFIER, "Expect rest parameter name after '...'.")
+			params = append(params, &expr.Param{Name: name, Type: p.annotation(), Rest: true})
+
+			if !p.check(token.RIGHT_PAREN) {
+				if err := parseError(p.peek(), "Rest parameter must be the last parameter."); err != nil {
+					panic(err)
+				}
+			}
+			break
+		}
+
+		param := &expr.Param{Name: p.consume(token.IDENTIFIER, "Expect parameter name.")}
+		param.Type = p.annotation()
+		if p.match(token.EQUAL) {
+			param.Default = p.expression()
+			hasDefault = true
+		} else if hasDefault {
+			if err := parseError(param.Name, "Parameter without a default value can't follow one with a default value."); err != nil {
+				panic(err)
+			}
+		}
+		params = append(params, param)
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	return params
+}
+
+// Parses the optional type annotation after the name of a variable or a parameter
+func (p *Parser) annotation() *expr.Type {
+	if !p.match(token.COLON) {
+		return nil
+	}
+
+	return p.typeAnnotation()
+}
+
+// TypeAnnotation maps to the CFG rules:
+// type     → typeTerm ( "|" typeTerm )* ;
+// typeTerm → ( IDENTIFIER | "null" | "fun" ) ( "[" type ( "," type )* "]" )? ;
+//
+// The names are not checked here, the static checker reports the unknown ones
+func (p *Parser) typeAnnotation() *expr.Type {
+	annotation := p.typeTerm()
+	if !p.check(token.PIPE) {
+		return annotation
+	}
+
+	union := &expr.Type{Alternatives: []*expr.Type{annotation}}
+	for p.match(token.PIPE) {
+		union.Alternatives = append(union.Alternatives, p.typeTerm())
+	}
+
+	return union
+}
+
+func (p *Parser) typeTerm() *expr.Type {
+	if !p.match(token.IDENTIFIER, token.NULL, token.FUN) {
+		if err := parseError(p.peek(), "Expect type."); err != nil {
+			panic(err)
+		}
+	}
+
+	annotation := &expr.Type{Name: p.previous()}
+	if p.match(token.LEFT_BRACKET) {
+		for {
+			annotation.Arguments = append(annotation.Arguments, p.typeAnnotation())
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+		p.consume(token.RIGHT_BRACKET, "Expect ']' after type arguments.")
+	}
+
+	return annotation
+}
+
+// VarDeclaration maps to the CFG rule:
+// varDecl → ( "var" | "const" | "let" ) ( IDENTIFIER ( ":" type )? ( "=" expression )? | pattern "=" expression ) ";" ;
+//
+// A destructuring declaration starts with a list or object pattern, and must
+// have an initializer to destructure. So must a constant, which can't be
+// assigned later
+func (p *Parser) varDeclaration() stmt.Stmt {
+	constant := p.previous().Type != token.VAR
+
+	if p.check(token.LEFT_BRACKET) || p.check(token.LEFT_BRACE) || (p.check(token.IDENTIFIER) && p.checkNext(token.LEFT_BRACE)) {
+		pattern := p.pattern()
+		p.consume(token.EQUAL, "Expect '=' after destructuring pattern.")
+		initializer := p.expression()
+		p.consume(token.SEMICOLON, "Expect ';' after variable declaration.")
+
+		return &stmt.Var{Pattern: pattern, Initializer: initializer, Constant: constant}
+	}
+
+	name := p.consume(token.IDENTIFIER, "Expect variable name.")
+	annotation := p.annotation()
+
+	var initializer expr.Expr
+	if constant {
+		p.consume(token.EQUAL, "Expect '=' after constant name.")
+		initializer = p.expression()
+	} else if p.match(token.EQUAL) {
+		initializer = p.expression()
+	}
+
+	p.consume(token.SEMICOLON, "Expect ';' after variable declaration.")
+
+	return &stmt.Var{Name: name, Type: annotation, Initializer: initializer, Constant: constant}
+}
+
+// ImportDeclaration maps to the CFG rule: importDecl → "import" STRING "as" IDENTIFIER ";" ;
+func (p *Parser) importDeclaration() stmt.Stmt {
+	keyword := p.previous()
+	path := p.consume(token.STRING, "Expect module path after 'import'.")
+	p.consume(token.AS, "Expect 'as' after module path.")
+	alias := p.consume(token.IDENTIFIER, "Expect module name after 'as'.")
+	p.consume(token.SEMICOLON, "Expect ';' after import.")
+
+	return &stmt.Import{Keyword: keyword, Path: path, Alias: alias}
+}
+
+// FromImportDeclaration maps to the CFG rule:
+// importDecl → "from" STRING "import" IDENTIFIER ( "," IDENTIFIER )* ";" ;
+func (p *Parser) fromImportDeclaration() stmt.Stmt {
+	keyword := p.previous()
+	path := p.consume(token.STRING, "Expect module path after 'from'.")
+	p.consume(token.IMPORT, "Expect 'import' after module path.")
+
+	names := []*token.Token{p.consume(token.IDENTIFIER, "Expect name to import.")}
+	for p.match(token.COMMA) {
+		names = append(names, p.consume(token.IDENTIFIER, "Expect name to import."))
+	}
+	p.consume(token.SEMICOLON, "Expect ';' after import.")
+
+	return &stmt.Import{Keyword: keyword, Path: path, Names: names}
+}
+
+// Statement maps to the CFG rule:
+// statement → exprStmt | forStmt | ifStmt | printStmt | returnStmt | whileStmt | breakStmt | continueStmt
+// | throwStmt | tryStmt | block ;
+func (p *Parser) statement() stmt.Stmt {
+	switch {
+	case p.match(token.BREAK):
+		return p.breakStatement()
+	case p.match(token.CONTINUE):
+		return p.continueStatement()
+	case p.match(token.FOR):
+		return p.forStatement()
+	case p.match(token.IF):
+		return p.ifStatement()
+	case p.match(token.PRINT):
+		return p.printStatement()
+	case p.match(token.RETURN):
+		return p.returnStatement()
+	case p.match(token.THROW):
+		return p.throwStatement()
+	case p.match(token.TRY):
+		return p.tryStatement()
+	case p.match(token.WHILE):
+		return p.whileStatement()
+	case p.match(token.YIELD):
+		return p.yieldStatement()
+	case p.match(token.MATCH):
+		return p.matchStatement()
+	case p.match(token.LEFT_BRACE):
+		return &stmt.Block{Statements: p.block()}
+	}
+
+	return p.expressionStatement()
+}
+
+// MatchStatement maps to the CFG rule: matchStmt → match ";"? ;
+// A match used as a statement does not need a semicolon after its closing brace
+func (p *Parser) matchStatement() stmt.Stmt {
+	match := p.matchExpression()
+	p.match(token.SEMICOLON)
+
+	return &stmt.Expression{Expression: match}
+}
+
+// ForStatement maps to the CFG rule:
+// forStmt → "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement | forInStmt ;
+//
+// There is no for node in the AST, the loop is desugared into a while loop
+// wrapped in a block that holds the initializer. The increment is kept on the
+// while loop, so that a continue statement in the body still runs it. A loop
+// whose variable is followed by "in" or "," is a for-in loop instead
+func (p *Parser) forStatement() stmt.Stmt {
+	p.consume(token.LEFT_PAREN, "Expect '(' after 'for'.")
+
+	var initializer stmt.Stmt
+	switch {
+	case p.match(token.SEMICOLON):
+		initializer = nil
+	case p.match(token.VAR, token.CONST, token.LET):
+		if p.check(token.IDENTIFIER) && (p.checkNext(token.IN) || p.checkNext(token.COMMA)) {
+			return p.forInStatement()
+		}
+		initializer = p.varDeclaration()
+	default:
+		initializer = p.expressionStatement()
+	}
+
+	var condition expr.Expr
+	if !p.check(token.SEMICOLON) {
+		condition = p.expression()
+	}
+	p.consume(token.SEMICOLON, "Expect ';' after loop condition.")
+
+	var increment expr.Expr
+	if !p.check(token.RIGHT_PAREN) {
+		increment = p.expression()
+	}
+	p.consume(token.RIGHT_PAREN, "Expect ')' after for clauses.")
+
+	if condition == nil {
+		condition = &expr.Literal{Value: true}
+	}
+	var body stmt.Stmt = &stmt.While{Condition: condition, Body: p.statement(), Increment: increment}
+
+	if initializer != nil {
+		body = &stmt.Block{Statements: []stmt.Stmt{initializer, body}}
+	}
+
+	return body
+}
+
+// ForInStatement maps to the CFG rule:
+// forInStmt → "for" "(" ( "var" | "const" | "let" ) IDENTIFIER ( "," IDENTIFIER )? "in" expression ")" statement ;
+func (p *Parser) forInStatement() stmt.Stmt {
+	constant := p.previous().Type != token.VAR
+	name := p.consume(token.IDENTIFIER, "Expect variable name.")
+
+	var key *token.Token
+	if p.match(token.COMMA) {
+		key = name
+		name = p.consume(token.IDENTIFIER, "Expect variable name after ','.")
+	}
+
+	keyword := p.consume(token.IN, "Expect 'in' after loop variables.")
+	iterable := p.expression()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after for-in clause.")
+
+	return &stmt.ForIn{Keyword: keyword, Key: key, Name: name, Iterable: iterable, Body: p.statement(), Constant: constant}
+}
+
+// IfStatement maps to the CFG rule: ifStmt → "if" "(" expression ")" statement ( "else" statement )? ;
+func (p *Parser) ifStatement() stmt.Stmt {
+	p.consume(token.LEFT_PAREN, "Expect '(' after 'if'.")
+	condition := p.expression()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after if condition.")
+
+	thenBranch := p.statement()
+
+	var elseBranch stmt.Stmt
+	if p.match(token.ELSE) {
+		elseBranch = p.statement()
+	}
+
+	return &stmt.If{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
+}
+
+// PrintStatement maps to the CFG rule: printStmt → "print" expression ";" ;
+func (p *Parser) printStatement() stmt.Stmt {
+	value := p.expression()
+	p.consume(token.SEMICOLON, "Expect ';' after value.")
+
+	return &stmt.Print{Expression: value}
+}
+
+// ReturnStatement maps to the CFG rule: returnStmt → "return" expression? ";" ;
+func (p *Parser) returnStatement() stmt.Stmt {
+	keyword := p.previous()
+
+	var value expr.Expr
+	if !p.check(token.SEMICOLON) {
+		value = p.expression()
+	}
+	p.consume(token.SEMICOLON, "Expect ';' after return value.")
+
+	return &stmt.Return{Keyword: keyword, Value: value}
+}
+
+// WhileStatement maps to the CFG rule: whileStmt → "while" "(" expression ")" statement ;
+func (p *Parser) whileStatement() stmt.Stmt {
+	p.consume(token.LEFT_PAREN, "Expect '(' after 'while'.")
+	condition := p.expression()
+	p.consume(token.RIGHT_PAREN, "Expect ')' after condition.")
+
+	return &stmt.While{Condition: condition, Body: p.statement()}
+}
+
+// BreakStatement maps to the CFG rule: breakStmt → "break" ";" ;
+// Whether the statement is inside of a loop is checked by the resolver
+func (p *Parser) breakStatement() stmt.Stmt {
+	keyword := p.previous()
+	p.consume(token.SEMICOLON, "Expect ';' after 'break'.")
+
+	return &stmt.Break{Keyword: keyword}
+}
+
+// ContinueStatement maps to the CFG rule: continueStmt → "continue" ";" ;
+// Whether the statement is inside of a loop is checked by the resolver
+func (p *Parser) continueStatement() stmt.Stmt {
+	keyword := p.previous()
+	p.consume(token.SEMICOLON, "Expect ';' after 'continue'.")
+
+	return &stmt.Continue{Keyword: keyword}
+}
+
+// ThrowStatement maps to the CFG rule: throwStmt → "throw" expression ";" ;
+func (p *Parser) throwStatement() stmt.Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(token.SEMICOLON, "Expect ';' after thrown value.")
+
+	return &stmt.Throw{Keyword: keyword, Value: value}
+}
+
+// YieldStatement maps to the CFG rule: yieldStmt → "yield" expression? ";" ;
+// A yield makes the function it appears in a generator
+func (p *Parser) yieldStatement() stmt.Stmt {
+	keyword := p.previous()
+	p.yielded = true
+
+	var value expr.Expr
+	if !p.check(token.SEMICOLON) {
+		value = p.expression()
+	}
+	p.consume(token.SEMICOLON, "Expect ';' after yielded value.")
+
+	return &stmt.Yield{Keyword: keyword, Value: value}
+}
+
+// TryStatement maps to the CFG rule:
+// tryStmt → "try" block ( "catch" "(" IDENTIFIER ")" block )? ( "finally" block )? ;
+//
+// A try statement needs at least a catch or a finally block
+func (p *Parser) tryStatement() stmt.Stmt {
+	try := &stmt.Try{Keyword: p.previous()}
+
+	p.consume(token.LEFT_BRACE, "Expect '{' after 'try'.")
+	try.Body = p.block()
+
+	if p.match(token.CATCH) {
+		p.consume(token.LEFT_PAREN, "Expect '(' after 'catch'.")
+		try.CatchName = p.consume(token.IDENTIFIER, "Expect exception variable name.")
+		p.consume(token.RIGHT_PAREN, "Expect ')' after exception variable name.")
+		p.consume(token.LEFT_BRACE, "Expect '{' before catch body.")
+		try.Catch = p.block()
+	}
+
+	if p.match(token.FINALLY) {
+		p.consume(token.LEFT_BRACE, "Expect '{' after 'finally'.")
+		try.Finally = p.block()
+	}
+
+	if try.CatchName == nil && try.Finally == nil {
+		if err := parseError(p.peek(), "Expect 'catch' or 'finally' after try block."); err != nil {
+			panic(err)
+		}
+	}
+
+	return try
+}
+
+// Block maps to the CFG rule: block → "{" declaration* "}" ;
+// The opening brace has already been consumed by the caller
+func (p *Parser) block() []stmt.Stmt {
+	statements := []stmt.Stmt{}
+
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		statements = append(statements, p.declaration())
+	}
+
+	p.consume(token.RIGHT_BRACE, "Expect '}' after block.")
+
+	return statements
+}
+
+// ExpressionStatement maps to the CFG rule: exprStmt → expression ";" ;
+func (p *Parser) expressionStatement() stmt.Stmt {
+	expression := p.expression()
+	p.consume(token.SEMICOLON, "Expect ';' after expression.")
+
+	return &stmt.Expression{Expression: expression}
+}
@@ -71,6 +71,13 @@ func (a *AstPrinter) VisitCallExpr(call *expr.Call) interface{} {
 		}
 	}
 
+	for _, argument := range call.Named {
+		if args.Len() > 0 {
+			args.WriteString(",")
+		}
+		args.WriteString(argument.Name.Lexeme + ":" + argument.Value.Accept(a).(string))
+	}
+
 	if args.Len() > 0 {
 		return a.parenthesize("call", call.Callee, args.String())
 	}
@@ -103,6 +110,175 @@ func (a *AstPrinter) VisitTernaryExpr(e *expr.Ternary) interface{} {
 	return a.parenthesize("?", e.Condition, e.TrueBranch, e.FalseBranch)
 }
 
+// VisitListExpr implements the Visitor interface
+func (a *AstPrinter) VisitListExpr(e *expr.List) interface{} {
+	elements := make([]interface{}, len(e.Elements))
+	for idx, element := range e.Elements {
+		elements[idx] = element
+	}
+
+	return a.parenthesize("list", elements...)
+}
+
+// VisitIndexExpr implements the Visitor interface
+func (a *AstPrinter) VisitIndexExpr(e *expr.Index) interface{} {
+	return a.parenthesize("index", e.Object, e.Index)
+}
+
+// VisitSetIndexExpr implements the Visitor interface
+func (a *AstPrinter) VisitSetIndexExpr(e *expr.SetIndex) interface{} {
+	return a.parenthesize("setindex", e.Object, e.Index, e.Value)
+}
+
+// VisitSliceExpr implements the Visitor interface
+//
+// Omitted bounds of the slice are printed as "_"
+func (a *AstPrinter) VisitSliceExpr(e *expr.Slice) interface{} {
+	parts := []interface{}{e.Object, "_", "_"}
+
+	if e.Start != nil {
+		parts[1] = e.Start
+	}
+	if e.End != nil {
+		parts[2] = e.End
+	}
+
+	return a.parenthesize("slice", parts...)
+}
+
+// VisitMapExpr implements the Visitor interface
+func (a *AstPrinter) VisitMapExpr(e *expr.Map) interface{} {
+	entries := make([]interface{}, 0, 2*len(e.Keys))
+	for idx := range e.Keys {
+		entries = append(entries, e.Keys[idx], e.Values[idx])
+	}
+
+	return a.parenthesize("map", entries...)
+}
+
+// VisitCompoundAssignExpr implements the Visitor interface
+func (a *AstPrinter) VisitCompoundAssignExpr(e *expr.CompoundAssign) interface{} {
+	return a.parenthesize(e.Operator.Lexeme, e.Target, e.Value)
+}
+
+// VisitUpdateExpr implements the Visitor interface
+//
+// Prefix updates are printed as "pre++" and postfix ones as "post++"
+func (a *AstPrinter) VisitUpdateExpr(e *expr.Update) interface{} {
+	if e.Prefix {
+		return a.parenthesize("pre"+e.Operator.Lexeme, e.Target)
+	}
+
+	return a.parenthesize("post"+e.Operator.Lexeme, e.Target)
+}
+
+// VisitLambdaExpr implements the Visitor interface
+//
+// Only the parameters are printed, as the printer does not handle statements.
+// Default values are printed as "b=2" and the rest parameter as "...args".
+// Async lambdas are printed as "(async lambda ...)"
+func (a *AstPrinter) VisitLambdaExpr(e *expr.Lambda) interface{} {
+	params := make([]interface{}, len(e.Params))
+	for idx, param := range e.Params {
+		switch {
+		case param.Rest:
+			params[idx] = "..." + param.Name.Lexeme
+		case param.Default != nil:
+			params[idx] = param.Name.Lexeme + "=" + param.Default.Accept(a).(string)
+		default:
+			params[idx] = param.Name.Lexeme
+		}
+	}
+
+	if e.Async {
+		return a.parenthesize("async lambda", params...)
+	}
+
+	return a.parenthesize("lambda", params...)
+}
+
+// VisitMatchExpr implements the Visitor interface
+//
+// Every arm is printed as "(arm pattern body)", with "(if guard)" after the
+// pattern of guarded arms. Block bodies are printed as "{...}"
+func (a *AstPrinter) VisitMatchExpr(e *expr.Match) interface{} {
+	parts := make([]interface{}, 0, len(e.Arms)+1)
+	parts = append(parts, e.Subject)
+
+	for _, arm := range e.Arms {
+		armParts := []interface{}{a.pattern(arm.Pattern)}
+		if arm.Guard != nil {
+			armParts = append(armParts, a.parenthesize("if", arm.Guard))
+		}
+
+		if arm.Block != nil {
+			armParts = append(armParts, "{...}")
+		} else {
+			armParts = append(armParts, arm.Body)
+		}
+
+		parts = append(parts, a.parenthesize("arm", armParts...))
+	}
+
+	return a.parenthesize("match", parts...)
+}
+
+// VisitDestructureExpr implements the Visitor interface
+func (a *AstPrinter) VisitDestructureExpr(e *expr.Destructure) interface{} {
+	return a.parenthesize("=", a.pattern(e.Pattern), e.Value)
+}
+
+// VisitSpawnExpr implements the Visitor interface
+func (a *AstPrinter) VisitSpawnExpr(e *expr.Spawn) interface{} {
+	return a.parenthesize("spawn", e.Call)
+}
+
+// VisitAwaitExpr implements the Visitor interface
+func (a *AstPrinter) VisitAwaitExpr(e *expr.Await) interface{} {
+	return a.parenthesize("await", e.Value)
+}
+
+// Prints a pattern in the syntax of the language
+func (a *AstPrinter) pattern(pattern expr.Pattern) string {
+	switch p := pattern.(type) {
+	case *expr.LiteralPattern:
+		return a.VisitLiteralExpr(&expr.Literal{Value: p.Value}).(string)
+	case *expr.WildcardPattern:
+		return "_"
+	case *expr.BindingPattern:
+		return p.Name.Lexeme
+	case *expr.ListPattern:
+		elements := make([]string, 0, len(p.Elements)+1)
+		for _, element := range p.Elements {
+			elements = append(elements, a.pattern(element))
+		}
+		if p.Rest != nil {
+			elements = append(elements, "..."+p.Rest.Lexeme)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *expr.InstancePattern:
+		fields := make([]string, len(p.Fields))
+		for idx, field := range p.Fields {
+			fields[idx] = field.Name.Lexeme + ": " + a.pattern(field.Pattern)
+		}
+		class := ""
+		if p.Class != nil {
+			class = p.Class.Name.Lexeme
+		}
+		return class + "{" + strings.Join(fields, ", ") + "}"
+	case *expr.AlternativePattern:
+		alternatives := make([]string, len(p.Alternatives))
+		for idx, alternative := range p.Alternatives {
+			alternatives[idx] = a.pattern(alternative)
+		}
+		return strings.Join(alternatives, " | ")
+	case *expr.TargetPattern:
+		return p.Target.Accept(a).(string)
+	}
+
+	return ""
+}
+
 func (a *AstPrinter) parenthesize(name string, parts ...interface{}) string {
 	var str strings.Builder
 
@@ -2,6 +2,7 @@ package printer
 
 import (
 	"golox/expr"
+	"golox/stmt"
 	"golox/token"
 	"testing"
 )
@@ -113,6 +114,17 @@ func TestAstPrinter_SingleExprs(t *testing.T) {
 			},
 			expected: "(call func 1,2)",
 		},
+		{
+			name: "Call expression with named arguments",
+			expr: &expr.Call{
+				Callee:    &expr.Variable{Name: &token.Token{Lexeme: "func"}},
+				Arguments: []expr.Expr{&expr.Literal{Value: 1}},
+				Named: []*expr.NamedArgument{
+					{Name: &token.Token{Lexeme: "b"}, Value: &expr.Literal{Value: 2}},
+				},
+			},
+			expected: "(call func 1,b:2)",
+		},
 		{
 			name: "Get expression",
 			expr: &expr.Get{
@@ -154,6 +166,172 @@ func TestAstPrinter_SingleExprs(t *testing.T) {
 			expr:     &expr.Super{},
 			expected: "super",
 		},
+		{
+			name: "List expression",
+			expr: &expr.List{
+				Elements: []expr.Expr{&expr.Literal{Value: 1}, &expr.Literal{Value: 2}},
+			},
+			expected: "(list 1 2)",
+		},
+		{
+			name: "Map expression",
+			expr: &expr.Map{
+				Keys:   []expr.Expr{&expr.Literal{Value: "a"}, &expr.Literal{Value: "b"}},
+				Values: []expr.Expr{&expr.Literal{Value: 1}, &expr.Literal{Value: 2}},
+			},
+			expected: "(map a 1 b 2)",
+		},
+		{
+			name: "Power and bitwise expression",
+			expr: &expr.Binary{
+				Left:     &expr.Unary{Operator: &token.Token{Lexeme: "~"}, Right: &expr.Literal{Value: 1}},
+				Operator: &token.Token{Lexeme: "^"},
+				Right: &expr.Binary{
+					Left:     &expr.Literal{Value: 2},
+					Operator: &token.Token{Lexeme: "**"},
+					Right:    &expr.Literal{Value: 3},
+				},
+			},
+			expected: "(^ (~ 1) (** 2 3))",
+		},
+		{
+			name: "Lambda expression",
+			expr: &expr.Lambda{
+				Params: []*expr.Param{
+					{Name: &token.Token{Lexeme: "a"}},
+					{Name: &token.Token{Lexeme: "b"}, Default: &expr.Literal{Value: 2}},
+					{Name: &token.Token{Lexeme: "args"}, Rest: true},
+				},
+			},
+			expected: "(lambda a b=2 ...args)",
+		},
+		{
+			name: "Match expression",
+			expr: &expr.Match{
+				Subject: &expr.Variable{Name: &token.Token{Lexeme: "x"}},
+				Arms: []*expr.MatchArm{
+					{
+						Pattern: &expr.AlternativePattern{Alternatives: []expr.Pattern{
+							&expr.LiteralPattern{Value: 1},
+							&expr.LiteralPattern{Value: nil},
+						}},
+						Body: &expr.Literal{Value: "one"},
+					},
+					{
+						Pattern: &expr.ListPattern{
+							Elements: []expr.Pattern{&expr.BindingPattern{Name: &token.Token{Lexeme: "a"}}},
+							Rest:     &token.Token{Lexeme: "r"},
+						},
+						Guard: &expr.Variable{Name: &token.Token{Lexeme: "a"}},
+						Body:  &expr.Variable{Name: &token.Token{Lexeme: "r"}},
+					},
+					{
+						Pattern: &expr.InstancePattern{
+							Class: &expr.Variable{Name: &token.Token{Lexeme: "P"}},
+							Fields: []*expr.FieldPattern{
+								{Name: &token.Token{Lexeme: "y"}, Pattern: &expr.WildcardPattern{}},
+							},
+						},
+						Block: &stmt.Block{},
+					},
+				},
+			},
+			expected: "(match x (arm 1 | null one) (arm [a, ...r] (if a) r) (arm P{y: _} {...}))",
+		},
+		{
+			name: "Destructuring assignment",
+			expr: &expr.Destructure{
+				Pattern: &expr.ListPattern{Elements: []expr.Pattern{
+					&expr.TargetPattern{Target: &expr.Variable{Name: &token.Token{Lexeme: "a"}}},
+					&expr.ListPattern{Elements: []expr.Pattern{
+						&expr.TargetPattern{Target: &expr.Get{Object: &expr.Variable{Name: &token.Token{Lexeme: "o"}}, Name: &token.Token{Lexeme: "b"}}},
+						&expr.WildcardPattern{},
+					}},
+				}},
+				Value: &expr.Variable{Name: &token.Token{Lexeme: "x"}},
+			},
+			expected: "(= [a, [(get o b), _]] x)",
+		},
+		{
+			name: "Compound assignment expression",
+			expr: &expr.CompoundAssign{
+				Target:   &expr.Variable{Name: &token.Token{Lexeme: "x"}},
+				Operator: &token.Token{Lexeme: "*="},
+				Value:    &expr.Literal{Value: 2},
+			},
+			expected: "(*= x 2)",
+		},
+		{
+			name: "Prefix update expression",
+			expr: &expr.Update{
+				Target:   &expr.Variable{Name: &token.Token{Lexeme: "x"}},
+				Operator: &token.Token{Lexeme: "++"},
+				Prefix:   true,
+			},
+			expected: "(pre++ x)",
+		},
+		{
+			name: "Postfix update expression",
+			expr: &expr.Update{
+				Target:   &expr.Get{Object: &expr.Variable{Name: &token.Token{Lexeme: "p"}}, Name: &token.Token{Lexeme: "x"}},
+				Operator: &token.Token{Lexeme: "--"},
+			},
+			expected: "(post-- (get p x))",
+		},
+		{
+			name: "Index expression",
+			expr: &expr.Index{
+				Object: &expr.Variable{Name: &token.Token{Lexeme: "xs"}},
+				Index:  &expr.Literal{Value: 0},
+			},
+			expected: "(index xs 0)",
+		},
+		{
+			name: "Set index expression",
+			expr: &expr.SetIndex{
+				Object: &expr.Variable{Name: &token.Token{Lexeme: "xs"}},
+				Index:  &expr.Literal{Value: 0},
+				Value:  &expr.Literal{Value: 1},
+			},
+			expected: "(setindex xs 0 1)",
+		},
+		{
+			name: "Slice expression",
+			expr: &expr.Slice{
+				Object: &expr.Variable{Name: &token.Token{Lexeme: "xs"}},
+				Start:  &expr.Literal{Value: 1},
+			},
+			expected: "(slice xs 1 _)",
+		},
+		{
+			name: "Spawn expression",
+			expr: &expr.Spawn{
+				Keyword: &token.Token{Lexeme: "spawn"},
+				Call: &expr.Call{
+					Callee:    &expr.Variable{Name: &token.Token{Lexeme: "f"}},
+					Arguments: []expr.Expr{&expr.Literal{Value: 1}},
+				},
+			},
+			expected: "(spawn (call f 1))",
+		},
+		{
+			name: "Await expression",
+			expr: &expr.Await{
+				Keyword: &token.Token{Lexeme: "await"},
+				Value:   &expr.Variable{Name: &token.Token{Lexeme: "p"}},
+			},
+			expected: "(await p)",
+		},
+		{
+			name: "Async lambda",
+			expr: &expr.Lambda{
+				Keyword: &token.Token{Lexeme: "fun"},
+				Params:  []*expr.Param{{Name: &token.Token{Lexeme: "x"}}},
+				Body:    &stmt.Block{},
+				Async:   true,
+			},
+			expected: "(async lambda x)",
+		},
 	}
 
 	for _, tt := range tests {
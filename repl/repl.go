@@ -6,9 +6,7 @@ package repl
 import (
 	"bufio"
 	"fmt"
-	"golox/lexer"
-	"golox/parser"
-	"golox/printer"
+	"golox/golox"
 	"io"
 )
 
@@ -18,11 +16,12 @@ const PROMPT = "> "
 // Start starts the REPL
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
+	vm := golox.NewVM(golox.Options{Stdout: out, Stderr: out})
+	defer vm.Close()
 
 	for {
 		_, err := fmt.Fprint(out, PROMPT)
 		if err != nil {
-			fmt.Println("Error writing to output")
 			return
 		}
 
@@ -31,15 +30,16 @@ func Start(in io.Reader, out io.Writer) {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
+		// Errors are reported to the output by the VM
+		result, err := vm.Eval(scanner.Text())
+		if err == nil && result != nil {
+			str, err := vm.Interpreter().ToString(result)
+			if err != nil {
+				str = err.Error()
+			}
 
-		l.ScanTokens()
-
-		p := parser.New(l.Tokens)
-		expr := p.Parse()
-
-		prtr := printer.New()
-		fmt.Println(prtr.Print(expr))
+			//nolint:errcheck // The next prompt will notice a broken output
+			fmt.Fprintln(out, str)
+		}
 	}
 }
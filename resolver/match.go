@@ -0,0 +1,229 @@
+package resolver
+
+import (
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+)
+
+// VisitMatchExpr implements the expr.Visitor interface
+//
+// An arm is unreachable when an earlier arm without a guard matches every value
+// its pattern matches. Such arms are reported as warnings
+func (r *Resolver) VisitMatchExpr(e *expr.Match) interface{} {
+	r.resolveExpr(e.Subject)
+
+	for idx, arm := range e.Arms {
+		r.resolvePattern(arm.Pattern, make(map[string]bool), false)
+
+		r.beginScope()
+		r.declarePattern(arm.Pattern, false)
+		r.resolveExpr(arm.Guard)
+
+		if arm.Block != nil {
+			r.resolveStatements(stmt.Statements(arm.Block))
+		} else {
+			r.resolveExpr(arm.Body)
+		}
+		r.endScope()
+
+		for _, earlier := range e.Arms[:idx] {
+			if earlier.Guard == nil && covers(earlier.Pattern, arm.Pattern) {
+				r.warning(expr.PatternToken(arm.Pattern), "Unreachable match arm, an earlier arm matches all its values.")
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks the variables bound by a pattern. The bound names are collected in
+// bindings, to report the ones bound twice
+func (r *Resolver) resolvePattern(pattern expr.Pattern, bindings map[string]bool, inAlternative bool) {
+	bind := func(name *token.Token) {
+		switch {
+		case inAlternative:
+			r.error(name, "Alternatives of a pattern can't bind variables.")
+		case bindings[name.Lexeme]:
+			r.error(name, "Duplicate binding '"+name.Lexeme+"' in pattern.")
+		}
+		bindings[name.Lexeme] = true
+	}
+
+	switch p := pattern.(type) {
+	case *expr.BindingPattern:
+		bind(p.Name)
+	case *expr.ListPattern:
+		for _, element := range p.Elements {
+			r.resolvePattern(element, bindings, inAlternative)
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			bind(p.Rest)
+		}
+	case *expr.InstancePattern:
+		if p.Class != nil {
+			r.resolveExpr(p.Class)
+		}
+		for _, field := range p.Fields {
+			r.resolvePattern(field.Pattern, bindings, inAlternative)
+		}
+	case *expr.AlternativePattern:
+		for _, alternative := range p.Alternatives {
+			r.resolvePattern(alternative, bindings, true)
+		}
+	}
+}
+
+// Declares the variables bound by a pattern in the innermost scope
+func (r *Resolver) declarePattern(pattern expr.Pattern, constant bool) {
+	switch p := pattern.(type) {
+	case *expr.BindingPattern:
+		r.declare(p.Name, constant)
+	case *expr.ListPattern:
+		for _, element := range p.Elements {
+			r.declarePattern(element, constant)
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			r.declare(p.Rest, constant)
+		}
+	case *expr.InstancePattern:
+		for _, field := range p.Fields {
+			r.declarePattern(field.Pattern, constant)
+		}
+	}
+}
+
+// Reports whether every value matched by the later pattern is also matched by
+// the earlier one. Patterns that can't be compared statically are assumed not
+// to cover each other
+func covers(earlier, later expr.Pattern) bool {
+	switch e := earlier.(type) {
+	case *expr.WildcardPattern, *expr.BindingPattern:
+		return true
+	case *expr.AlternativePattern:
+		if l, ok := later.(*expr.AlternativePattern); ok {
+			for _, alternative := range l.Alternatives {
+				if !covers(e, alternative) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for _, alternative := range e.Alternatives {
+			if covers(alternative, later) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch l := later.(type) {
+	case *expr.AlternativePattern:
+		for _, alternative := range l.Alternatives {
+			if !covers(earlier, alternative) {
+				return false
+			}
+		}
+		return true
+	case *expr.LiteralPattern:
+		e, ok := earlier.(*expr.LiteralPattern)
+		return ok && e.Value == l.Value
+	case *expr.ListPattern:
+		e, ok := earlier.(*expr.ListPattern)
+		return ok && coversList(e, l)
+	case *expr.InstancePattern:
+		e, ok := earlier.(*expr.InstancePattern)
+		return ok && coversInstance(e, l)
+	}
+
+	return false
+}
+
+// Without a rest, the earlier pattern only covers lists of its own length.
+// With one, it covers every longer list whose first elements it covers
+func coversList(earlier, later *expr.ListPattern) bool {
+	if earlier.Rest == nil && (later.Rest != nil || len(later.Elements) != len(earlier.Elements)) {
+		return false
+	}
+
+	if len(later.Elements) < len(earlier.Elements) {
+		return false
+	}
+
+	for idx, element := range earlier.Elements {
+		if !covers(element, later.Elements[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The earlier pattern covers a pattern of the same class, or of any class if
+// the earlier one has none, that checks at least the same fields with covered
+// patterns
+func coversInstance(earlier, later *expr.InstancePattern) bool {
+	if earlier.Class != nil && (later.Class == nil || earlier.Class.Name.Lexeme != later.Class.Name.Lexeme) {
+		return false
+	}
+
+	for _, field := range earlier.Fields {
+		covered := false
+		for _, other := range later.Fields {
+			if other.Name.Lexeme == field.Name.Lexeme && covers(field.Pattern, other.Pattern) {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Checks the pattern of a destructuring declaration, which must match any value
+// of the right shape. Literals and alternatives would make it fail on values
+func (r *Resolver) resolveDeclarationPattern(pattern expr.Pattern) {
+	r.resolvePattern(pattern, make(map[string]bool), false)
+
+	var check func(pattern expr.Pattern)
+	check = func(pattern expr.Pattern) {
+		switch p := pattern.(type) {
+		case *expr.LiteralPattern, *expr.AlternativePattern:
+			r.error(expr.PatternToken(p), "Can only destructure into variables, lists and objects.")
+		case *expr.ListPattern:
+			for _, element := range p.Elements {
+				check(element)
+			}
+		case *expr.InstancePattern:
+			for _, field := range p.Fields {
+				check(field.Pattern)
+			}
+		}
+	}
+	check(pattern)
+}
+
+// VisitDestructureExpr implements the expr.Visitor interface
+func (r *Resolver) VisitDestructureExpr(e *expr.Destructure) interface{} {
+	r.resolveExpr(e.Value)
+	r.resolveTargets(e.Pattern)
+
+	return nil
+}
+
+func (r *Resolver) resolveTargets(pattern *expr.ListPattern) {
+	for _, element := range pattern.Elements {
+		switch e := element.(type) {
+		case *expr.TargetPattern:
+			r.resolveTarget(e.Target)
+		case *expr.ListPattern:
+			r.resolveTargets(e)
+		}
+	}
+}
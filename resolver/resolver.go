@@ -0,0 +1,575 @@
+/*
+Package resolver implements a static analysis pass over the GoLox AST.
+
+The resolver walks the whole program once before it is executed and reports the
+errors that can be found without running it:
+
+  - break and continue statements outside of a loop, and for-in loops binding
+    the same variable twice
+  - super expressions in the methods of a trait
+  - yield statements outside of a function, in an initializer or in an async
+    function
+  - return statements outside of a function, and with a value in a generator
+  - await expressions in functions that are not async, and async initializers
+  - patterns binding the same variable twice, or binding variables in alternatives
+  - literals and alternatives in the patterns of destructuring declarations
+  - assignments to constants, and constants declared again in the same scope
+
+All the errors are collected, so that they can be reported at once like the
+errors of the parser. The resolver also reports warnings for code that is valid
+but most likely a mistake, such as match arms that can never be reached.
+*/
+package resolver
+
+import (
+	loxerr "golox/error"
+	"golox/expr"
+	"golox/stmt"
+	"golox/token"
+)
+
+// The kinds of function a statement can be in
+type functionKind int
+
+const (
+	noFunction functionKind = iota
+	plainFunction
+	generatorFunction
+	asyncFunction
+	initializerFunction
+)
+
+// Resolver is a visitor that checks the AST for static errors
+type Resolver struct {
+	loopDepth int               // Number of loops enclosing the current statement in the current function
+	inTrait   bool              // Whether the current statement is in the body of a trait
+	function  functionKind      // The kind of the innermost function enclosing the current statement
+	scopes    []map[string]bool // The variables declared in the enclosing scopes, true for constants
+	errors    []*loxerr.Error
+	warnings  []*loxerr.Warning
+}
+
+// New creates a new Resolver
+func New() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve checks the given statements and returns the errors found in them
+func (r *Resolver) Resolve(statements []stmt.Stmt) []*loxerr.Error {
+	r.errors = nil
+	r.warnings = nil
+	r.scopes = []map[string]bool{{}}
+	r.resolveStatements(statements)
+
+	return r.errors
+}
+
+// Warnings returns the warnings found by the last call to Resolve
+func (r *Resolver) Warnings() []*loxerr.Warning {
+	return r.warnings
+}
+
+// VisitBlockStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitBlockStmt(s *stmt.Block) interface{} {
+	r.resolveBlock(s.Statements)
+	return nil
+}
+
+// VisitBreakStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitBreakStmt(s *stmt.Break) interface{} {
+	if r.loopDepth == 0 {
+		r.error(s.Keyword, "Can't use 'break' outside of a loop.")
+	}
+
+	return nil
+}
+
+// VisitClassStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitClassStmt(s *stmt.Class) interface{} {
+	r.declare(s.Name, false)
+
+	if s.Superclass != nil {
+		r.resolveExpr(s.Superclass)
+	}
+
+	for _, trait := range s.Traits {
+		r.resolveExpr(trait)
+	}
+
+	enclosingTrait := r.inTrait
+	r.inTrait = false
+	r.resolveMembers(s.Members)
+	r.inTrait = enclosingTrait
+
+	return nil
+}
+
+// VisitTraitStmt implements the stmt.Visitor interface
+//
+// A trait can be mixed into classes with different superclasses, so super has
+// no meaning in its methods
+func (r *Resolver) VisitTraitStmt(s *stmt.Trait) interface{} {
+	r.declare(s.Name, false)
+
+	enclosingTrait := r.inTrait
+	r.inTrait = true
+	r.resolveMembers(s.Members)
+	r.inTrait = enclosingTrait
+
+	return nil
+}
+
+func (r *Resolver) resolveMembers(members stmt.Members) {
+	for _, method := range members.Methods {
+		kind := kindOf(method.Generator, method.Async)
+		if method.Name.Lexeme == "init" {
+			if method.Async {
+				r.error(method.Name, "Can't make an initializer async.")
+			}
+			kind = initializerFunction
+		}
+		r.resolveFunction(kind, method.Params, method.Body)
+	}
+
+	for _, methods := range [][]*stmt.Function{members.StaticMethods, members.Getters, members.Setters} {
+		for _, method := range methods {
+			r.resolveFunction(kindOf(method.Generator, method.Async), method.Params, method.Body)
+		}
+	}
+}
+
+// VisitContinueStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitContinueStmt(s *stmt.Continue) interface{} {
+	if r.loopDepth == 0 {
+		r.error(s.Keyword, "Can't use 'continue' outside of a loop.")
+	}
+
+	return nil
+}
+
+// VisitExpressionStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitExpressionStmt(s *stmt.Expression) interface{} {
+	r.resolveExpr(s.Expression)
+	return nil
+}
+
+// VisitForInStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitForInStmt(s *stmt.ForIn) interface{} {
+	r.resolveExpr(s.Iterable)
+
+	if s.Key != nil && s.Key.Lexeme == s.Name.Lexeme {
+		r.error(s.Name, "Duplicate loop variable '"+s.Name.Lexeme+"'.")
+	}
+
+	r.beginScope()
+	if s.Key != nil {
+		r.declare(s.Key, s.Constant)
+	}
+	r.declare(s.Name, s.Constant)
+
+	r.loopDepth++
+	r.resolveStmt(s.Body)
+	r.loopDepth--
+	r.endScope()
+
+	return nil
+}
+
+// VisitFunctionStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitFunctionStmt(s *stmt.Function) interface{} {
+	r.declare(s.Name, false)
+	r.resolveFunction(kindOf(s.Generator, s.Async), s.Params, s.Body)
+	return nil
+}
+
+// VisitIfStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitIfStmt(s *stmt.If) interface{} {
+	r.resolveExpr(s.Condition)
+	r.resolveStmt(s.ThenBranch)
+	r.resolveStmt(s.ElseBranch)
+
+	return nil
+}
+
+// VisitImportStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitImportStmt(s *stmt.Import) interface{} {
+	if s.Alias != nil {
+		r.declare(s.Alias, false)
+	}
+
+	for _, name := range s.Names {
+		r.declare(name, false)
+	}
+
+	return nil
+}
+
+// VisitPrintStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitPrintStmt(s *stmt.Print) interface{} {
+	r.resolveExpr(s.Expression)
+	return nil
+}
+
+// VisitReturnStmt implements the stmt.Visitor interface
+//
+// A generator returns a generator when it is called, the return statements in
+// its body can only end it
+func (r *Resolver) VisitReturnStmt(s *stmt.Return) interface{} {
+	if r.function == noFunction {
+		r.error(s.Keyword, "Can't return from top-level code.")
+	} else if s.Value != nil && r.function == generatorFunction {
+		r.error(s.Keyword, "Can't return a value from a generator.")
+	}
+
+	r.resolveExpr(s.Value)
+	return nil
+}
+
+// VisitThrowStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitThrowStmt(s *stmt.Throw) interface{} {
+	r.resolveExpr(s.Value)
+	return nil
+}
+
+// VisitTryStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitTryStmt(s *stmt.Try) interface{} {
+	r.resolveBlock(s.Body)
+
+	if s.CatchName != nil {
+		r.beginScope()
+		r.declare(s.CatchName, false)
+		r.resolveStatements(s.Catch)
+		r.endScope()
+	}
+
+	r.resolveBlock(s.Finally)
+
+	return nil
+}
+
+// VisitVarStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitVarStmt(s *stmt.Var) interface{} {
+	r.resolveExpr(s.Initializer)
+
+	if s.Pattern != nil {
+		r.resolveDeclarationPattern(s.Pattern)
+		r.declarePattern(s.Pattern, s.Constant)
+		return nil
+	}
+
+	r.declare(s.Name, s.Constant)
+
+	return nil
+}
+
+// VisitWhileStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitWhileStmt(s *stmt.While) interface{} {
+	r.resolveExpr(s.Condition)
+	r.resolveExpr(s.Increment)
+
+	r.loopDepth++
+	r.resolveStmt(s.Body)
+	r.loopDepth--
+
+	return nil
+}
+
+// VisitYieldStmt implements the stmt.Visitor interface
+func (r *Resolver) VisitYieldStmt(s *stmt.Yield) interface{} {
+	switch r.function {
+	case noFunction:
+		r.error(s.Keyword, "Can't use 'yield' outside of a function.")
+	case initializerFunction:
+		r.error(s.Keyword, "Can't use 'yield' in an initializer.")
+	case asyncFunction:
+		r.error(s.Keyword, "Can't use 'yield' in an async function.")
+	}
+
+	r.resolveExpr(s.Value)
+	return nil
+}
+
+// VisitAssignExpr implements the expr.Visitor interface
+func (r *Resolver) VisitAssignExpr(e *expr.Assign) interface{} {
+	r.resolveExpr(e.Value)
+	r.checkAssignable(e.Name)
+
+	return nil
+}
+
+// VisitBinaryExpr implements the expr.Visitor interface
+func (r *Resolver) VisitBinaryExpr(e *expr.Binary) interface{} {
+	r.resolveExpr(e.Left)
+	r.resolveExpr(e.Right)
+
+	return nil
+}
+
+// VisitCallExpr implements the expr.Visitor interface
+func (r *Resolver) VisitCallExpr(e *expr.Call) interface{} {
+	r.resolveExpr(e.Callee)
+	r.resolveExprs(e.Arguments)
+
+	for _, argument := range e.Named {
+		r.resolveExpr(argument.Value)
+	}
+
+	return nil
+}
+
+// VisitSpawnExpr implements the expr.Visitor interface
+func (r *Resolver) VisitSpawnExpr(e *expr.Spawn) interface{} {
+	return r.VisitCallExpr(e.Call)
+}
+
+// VisitAwaitExpr implements the expr.Visitor interface
+//
+// The top level of a script can await, which runs the event loop until the
+// promise settles, but the other functions must be async to be suspended
+func (r *Resolver) VisitAwaitExpr(e *expr.Await) interface{} {
+	if r.function != noFunction && r.function != asyncFunction {
+		r.error(e.Keyword, "Can't use 'await' outside of an async function.")
+	}
+
+	r.resolveExpr(e.Value)
+	return nil
+}
+
+// VisitGetExpr implements the expr.Visitor interface
+func (r *Resolver) VisitGetExpr(e *expr.Get) interface{} {
+	r.resolveExpr(e.Object)
+	return nil
+}
+
+// VisitGroupingExpr implements the expr.Visitor interface
+func (r *Resolver) VisitGroupingExpr(e *expr.Grouping) interface{} {
+	r.resolveExpr(e.Expression)
+	return nil
+}
+
+// VisitLiteralExpr implements the expr.Visitor interface
+func (r *Resolver) VisitLiteralExpr(_ *expr.Literal) interface{} {
+	return nil
+}
+
+// VisitLogicalExpr implements the expr.Visitor interface
+func (r *Resolver) VisitLogicalExpr(e *expr.Logical) interface{} {
+	r.resolveExpr(e.Left)
+	r.resolveExpr(e.Right)
+
+	return nil
+}
+
+// VisitSetExpr implements the expr.Visitor interface
+func (r *Resolver) VisitSetExpr(e *expr.Set) interface{} {
+	r.resolveExpr(e.Object)
+	r.resolveExpr(e.Value)
+
+	return nil
+}
+
+// VisitSuperExpr implements the expr.Visitor interface
+func (r *Resolver) VisitSuperExpr(e *expr.Super) interface{} {
+	if r.inTrait {
+		r.error(e.Keyword, "Can't use 'super' in a trait.")
+	}
+
+	return nil
+}
+
+// VisitThisExpr implements the expr.Visitor interface
+func (r *Resolver) VisitThisExpr(_ *expr.This) interface{} {
+	return nil
+}
+
+// VisitUnaryExpr implements the expr.Visitor interface
+func (r *Resolver) VisitUnaryExpr(e *expr.Unary) interface{} {
+	r.resolveExpr(e.Right)
+	return nil
+}
+
+// VisitVariableExpr implements the expr.Visitor interface
+func (r *Resolver) VisitVariableExpr(_ *expr.Variable) interface{} {
+	return nil
+}
+
+// VisitTernaryExpr implements the expr.Visitor interface
+func (r *Resolver) VisitTernaryExpr(e *expr.Ternary) interface{} {
+	r.resolveExpr(e.Condition)
+	r.resolveExpr(e.TrueBranch)
+	r.resolveExpr(e.FalseBranch)
+
+	return nil
+}
+
+// VisitListExpr implements the expr.Visitor interface
+func (r *Resolver) VisitListExpr(e *expr.List) interface{} {
+	r.resolveExprs(e.Elements)
+	return nil
+}
+
+// VisitIndexExpr implements the expr.Visitor interface
+func (r *Resolver) VisitIndexExpr(e *expr.Index) interface{} {
+	r.resolveExpr(e.Object)
+	r.resolveExpr(e.Index)
+
+	return nil
+}
+
+// VisitSetIndexExpr implements the expr.Visitor interface
+func (r *Resolver) VisitSetIndexExpr(e *expr.SetIndex) interface{} {
+	r.resolveExpr(e.Object)
+	r.resolveExpr(e.Index)
+	r.resolveExpr(e.Value)
+
+	return nil
+}
+
+// VisitSliceExpr implements the expr.Visitor interface
+func (r *Resolver) VisitSliceExpr(e *expr.Slice) interface{} {
+	r.resolveExpr(e.Object)
+	r.resolveExpr(e.Start)
+	r.resolveExpr(e.End)
+
+	return nil
+}
+
+// VisitMapExpr implements the expr.Visitor interface
+func (r *Resolver) VisitMapExpr(e *expr.Map) interface{} {
+	r.resolveExprs(e.Keys)
+	r.resolveExprs(e.Values)
+
+	return nil
+}
+
+// VisitCompoundAssignExpr implements the expr.Visitor interface
+func (r *Resolver) VisitCompoundAssignExpr(e *expr.CompoundAssign) interface{} {
+	r.resolveTarget(e.Target)
+	r.resolveExpr(e.Value)
+
+	return nil
+}
+
+// VisitUpdateExpr implements the expr.Visitor interface
+func (r *Resolver) VisitUpdateExpr(e *expr.Update) interface{} {
+	r.resolveTarget(e.Target)
+	return nil
+}
+
+// VisitLambdaExpr implements the expr.Visitor interface
+func (r *Resolver) VisitLambdaExpr(e *expr.Lambda) interface{} {
+	r.resolveFunction(kindOf(e.Generator, e.Async), e.Params, stmt.Statements(e.Body))
+	return nil
+}
+
+// Resolves the default values of the parameters and the body of a function.
+// Loops around the declaration do not enclose the body, so break and continue
+// can not cross the function boundary
+func (r *Resolver) resolveFunction(kind functionKind, params []*expr.Param, body []stmt.Stmt) {
+	enclosingLoopDepth, enclosingFunction := r.loopDepth, r.function
+	r.loopDepth, r.function = 0, kind
+
+	r.beginScope()
+	for _, param := range params {
+		r.resolveExpr(param.Default)
+		r.declare(param.Name, false)
+	}
+	r.resolveStatements(body)
+	r.endScope()
+
+	r.loopDepth, r.function = enclosingLoopDepth, enclosingFunction
+}
+
+// Returns the kind of a function that is not an initializer. An async function
+// that yields is reported at its yield statements
+func kindOf(generator, async bool) functionKind {
+	switch {
+	case async:
+		return asyncFunction
+	case generator:
+		return generatorFunction
+	}
+
+	return plainFunction
+}
+
+// Resolves the statements of a block in a scope of their own
+func (r *Resolver) resolveBlock(statements []stmt.Stmt) {
+	r.beginScope()
+	r.resolveStatements(statements)
+	r.endScope()
+}
+
+func (r *Resolver) resolveStatements(statements []stmt.Stmt) {
+	for _, s := range statements {
+		r.resolveStmt(s)
+	}
+}
+
+func (r *Resolver) resolveStmt(s stmt.Stmt) {
+	if s != nil {
+		s.Accept(r)
+	}
+}
+
+func (r *Resolver) resolveExprs(expressions []expr.Expr) {
+	for _, e := range expressions {
+		r.resolveExpr(e)
+	}
+}
+
+func (r *Resolver) resolveExpr(e expr.Expr) {
+	if e != nil {
+		e.Accept(r)
+	}
+}
+
+// Resolves the target of an assignment, which is a variable, a property or an index
+func (r *Resolver) resolveTarget(target expr.Expr) {
+	if variable, ok := target.(*expr.Variable); ok {
+		r.checkAssignable(variable.Name)
+	}
+
+	r.resolveExpr(target)
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// Declares a variable in the innermost scope, shadowing the variables of the
+// enclosing scopes. A constant can't be declared again in the same scope
+func (r *Resolver) declare(name *token.Token, constant bool) {
+	scope := r.scopes[len(r.scopes)-1]
+	if scope[name.Lexeme] {
+		r.error(name, "Already a constant named '"+name.Lexeme+"' in this scope.")
+	}
+
+	scope[name.Lexeme] = constant
+}
+
+// Reports an assignment to a variable that resolves to a constant. Variables
+// that are not declared yet, like globals declared after the function assigning
+// them, are left to the interpreter
+func (r *Resolver) checkAssignable(name *token.Token) {
+	for idx := len(r.scopes) - 1; idx >= 0; idx-- {
+		if constant, ok := r.scopes[idx][name.Lexeme]; ok {
+			if constant {
+				r.error(name, "Can't assign to constant '"+name.Lexeme+"'.")
+			}
+			return
+		}
+	}
+}
+
+func (r *Resolver) error(t *token.Token, message string) {
+	r.errors = append(r.errors, loxerr.New(t, message))
+}
+
+func (r *Resolver) warning(t *token.Token, message string) {
+	r.warnings = append(r.warnings, loxerr.NewWarning(t, message))
+}
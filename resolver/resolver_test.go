@@ -0,0 +1,233 @@
+package resolver
+
+import (
+	"golox/lexer"
+	"golox/parser"
+	"testing"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         string
+		expectedErrors []string
+	}{
+		{name: "Break in while", source: "while (true) { if (true) break; }"},
+		{name: "Continue in for", source: "for (var i = 0; i < 3; i = i + 1) { continue; }"},
+		{name: "Break in a function in a loop", source: "while (true) { fun f() { while (true) break; } break; }"},
+		{name: "Break in a for-in loop", source: "for (var x in xs) { if (x) break; continue; }"},
+		{
+			name:           "Duplicate loop variable",
+			source:         "for (var x, x in xs) {}",
+			expectedErrors: []string{"[Pos 1:13] Error at 'x': Duplicate loop variable 'x'."},
+		},
+		{
+			name:           "Break and continue outside of a loop",
+			source:         "break;\n{ continue; }",
+			expectedErrors: []string{"[Pos 1:1] Error at 'break': Can't use 'break' outside of a loop.", "[Pos 2:3] Error at 'continue': Can't use 'continue' outside of a loop."},
+		},
+		{
+			name:           "Loops do not enclose functions",
+			source:         "while (true) { fun f() { break; } }",
+			expectedErrors: []string{"[Pos 1:26] Error at 'break': Can't use 'break' outside of a loop."},
+		},
+		{
+			name:           "Loops do not enclose lambdas",
+			source:         "while (true) { var f = () => { continue; }; }",
+			expectedErrors: []string{"[Pos 1:32] Error at 'continue': Can't use 'continue' outside of a loop."},
+		},
+		{
+			name:           "Loops do not enclose methods",
+			source:         "for (;;) { class A { m() { continue; } } }",
+			expectedErrors: []string{"[Pos 1:28] Error at 'continue': Can't use 'continue' outside of a loop."},
+		},
+		{
+			name:           "Super in a trait",
+			source:         "trait T { m() { return super.m(); } }",
+			expectedErrors: []string{"[Pos 1:24] Error at 'super': Can't use 'super' in a trait."},
+		},
+		{name: "Super in a class with traits", source: "class A {} trait T {} class B < A with T { m() { return super.m(); } }"},
+		{
+			name:           "Duplicate binding in a pattern",
+			source:         "match (1) { [a, [b, a]] => a };",
+			expectedErrors: []string{"[Pos 1:21] Error at 'a': Duplicate binding 'a' in pattern."},
+		},
+		{
+			name:           "Binding in alternatives",
+			source:         "match (1) { [a] | a => a };",
+			expectedErrors: []string{"[Pos 1:14] Error at 'a': Alternatives of a pattern can't bind variables.", "[Pos 1:19] Error at 'a': Alternatives of a pattern can't bind variables."},
+		},
+		{name: "Same binding in different arms", source: "match (1) { [a] => a, a => a };"},
+		{
+			name:           "Literal in a destructuring declaration",
+			source:         "var [a, 1] = [1, 1];",
+			expectedErrors: []string{"[Pos 1:9] Error at '1': Can only destructure into variables, lists and objects."},
+		},
+		{
+			name:           "Duplicate binding in a destructuring declaration",
+			source:         "var [a, {a}] = x;",
+			expectedErrors: []string{"[Pos 1:10] Error at 'a': Duplicate binding 'a' in pattern."},
+		},
+		{
+			name:           "Yield outside of a function",
+			source:         "yield 1;",
+			expectedErrors: []string{"[Pos 1:1] Error at 'yield': Can't use 'yield' outside of a function."},
+		},
+		{
+			name:           "Yield in an initializer",
+			source:         "class A { init() { yield 1; } }",
+			expectedErrors: []string{"[Pos 1:20] Error at 'yield': Can't use 'yield' in an initializer."},
+		},
+		{
+			name:           "Return outside of a function",
+			source:         "return 1;\n{ return; }",
+			expectedErrors: []string{"[Pos 1:1] Error at 'return': Can't return from top-level code.", "[Pos 2:3] Error at 'return': Can't return from top-level code."},
+		},
+		{
+			name:           "Return with a value from a generator",
+			source:         "fun g() { yield 1; return 2; }",
+			expectedErrors: []string{"[Pos 1:20] Error at 'return': Can't return a value from a generator."},
+		},
+		{name: "Return with a value from a function nested in a generator", source: "fun g() { yield () => 1; return; }"},
+		{name: "Yield in a method", source: "class A { each() { yield 1; } }"},
+		{name: "Await in async functions and at the top level", source: "async fun f() { await g(); var h = async () => await f(); } await f();"},
+		{
+			name:           "Await in a function that is not async",
+			source:         "async fun f() { fun g() { await f(); } }",
+			expectedErrors: []string{"[Pos 1:27] Error at 'await': Can't use 'await' outside of an async function."},
+		},
+		{
+			name:           "Await in a method that is not async",
+			source:         "class A { m() { return await 1; } }",
+			expectedErrors: []string{"[Pos 1:24] Error at 'await': Can't use 'await' outside of an async function."},
+		},
+		{
+			name:           "Yield in an async function",
+			source:         "async fun f() { yield 1; }",
+			expectedErrors: []string{"[Pos 1:17] Error at 'yield': Can't use 'yield' in an async function."},
+		},
+		{
+			name:           "Async initializer",
+			source:         "class A { async init() {} }",
+			expectedErrors: []string{"[Pos 1:17] Error at 'init': Can't make an initializer async."},
+		},
+		{
+			name:           "Assignment to a constant",
+			source:         "const x = 1;\nx = 2;",
+			expectedErrors: []string{"[Pos 2:1] Error at 'x': Can't assign to constant 'x'."},
+		},
+		{
+			name:           "Updates of constants",
+			source:         "let x = 1; x += 1; x++; --x; fun f() { x = 2; }",
+			expectedErrors: []string{"[Pos 1:12] Error at 'x': Can't assign to constant 'x'.", "[Pos 1:20] Error at 'x': Can't assign to constant 'x'.", "[Pos 1:27] Error at 'x': Can't assign to constant 'x'.", "[Pos 1:40] Error at 'x': Can't assign to constant 'x'."},
+		},
+		{
+			name:           "Destructuring into constants",
+			source:         "const [a, {b}] = [1, {b: 2}]; [a, c] = [b, a];",
+			expectedErrors: []string{"[Pos 1:32] Error at 'a': Can't assign to constant 'a'."},
+		},
+		{
+			name:           "Constant loop variable",
+			source:         "for (const k, v in m) { v = 1; }",
+			expectedErrors: []string{"[Pos 1:25] Error at 'v': Can't assign to constant 'v'."},
+		},
+		{
+			name:   "Shadowed constants",
+			source: "const x = 1; { var x = 2; x = 3; } fun f(x) { x = 1; } try {} catch (x) { x = 1; } match (1) { x => { x = 2; } } for (var x in xs) x = 1;",
+		},
+		{name: "Constant declared after the assignment", source: "fun f() { x = 2; } const x = 1;"},
+		{name: "Constant in another scope", source: "{ const x = 1; } x = 2;"},
+		{
+			name:           "Constant declared twice",
+			source:         "const x = 1; var x = 2; fun x() {}",
+			expectedErrors: []string{"[Pos 1:18] Error at 'x': Already a constant named 'x' in this scope."},
+		},
+		{
+			name:           "Break in a block arm outside of a loop",
+			source:         "match (1) { _ => { break; } }",
+			expectedErrors: []string{"[Pos 1:20] Error at 'break': Can't use 'break' outside of a loop."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.source)
+			l.ScanTokens()
+
+			statements, parseErrors := parser.New(l.Tokens).ParseProgram()
+			if len(parseErrors) > 0 {
+				t.Fatalf("Parse errors: %v", parseErrors)
+			}
+
+			errs := New().Resolve(statements)
+			if len(errs) != len(tt.expectedErrors) {
+				t.Fatalf("Expected %d errors but got %v", len(tt.expectedErrors), errs)
+			}
+
+			for idx, err := range errs {
+				if err.Error() != tt.expectedErrors[idx] {
+					t.Errorf("Expected error '%s' but got '%s'", tt.expectedErrors[idx], err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestResolver_Warnings(t *testing.T) {
+	tests := []struct {
+		name             string
+		source           string
+		expectedWarnings []string
+	}{
+		{name: "Reachable arms", source: "match (1) { 1 | 2 => 1, [a, b, ...c] => 2, [a] => 3, P{x: 1} => 4, P{x} => 5, _ => 6 };"},
+		{
+			name:             "Arm after a wildcard",
+			source:           "match (1) { _ => 1, 2 => 2, n => 3 };",
+			expectedWarnings: []string{"[Pos 1:21] Warning at '2': Unreachable match arm, an earlier arm matches all its values.", "[Pos 1:29] Warning at 'n': Unreachable match arm, an earlier arm matches all its values."},
+		},
+		{name: "Arm after a guarded binding", source: "match (1) { n if n > 0 => 1, 2 => 2 };"},
+		{
+			name:             "Repeated literal",
+			source:           "match (1) { 1 | 2 => 1, 2 => 2 };",
+			expectedWarnings: []string{"[Pos 1:25] Warning at '2': Unreachable match arm, an earlier arm matches all its values."},
+		},
+		{
+			name:             "List covered by a rest",
+			source:           "match (1) { [1, ...r] => 1, [1, 2] => 2 };",
+			expectedWarnings: []string{"[Pos 1:29] Warning at '[': Unreachable match arm, an earlier arm matches all its values."},
+		},
+		{
+			name:             "Instance covered by fewer fields",
+			source:           "match (1) { P{x} => 1, P{x: 1, y} => 2 };",
+			expectedWarnings: []string{"[Pos 1:24] Warning at 'P': Unreachable match arm, an earlier arm matches all its values."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.source)
+			l.ScanTokens()
+
+			statements, parseErrors := parser.New(l.Tokens).ParseProgram()
+			if len(parseErrors) > 0 {
+				t.Fatalf("Parse errors: %v", parseErrors)
+			}
+
+			r := New()
+			if errs := r.Resolve(statements); len(errs) > 0 {
+				t.Fatalf("Expected no errors but got %v", errs)
+			}
+
+			warnings := r.Warnings()
+			if len(warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("Expected %d warnings but got %v", len(tt.expectedWarnings), warnings)
+			}
+
+			for idx, warning := range warnings {
+				if warning.String() != tt.expectedWarnings[idx] {
+					t.Errorf("Expected warning '%s' but got '%s'", tt.expectedWarnings[idx], warning.String())
+				}
+			}
+		})
+	}
+}
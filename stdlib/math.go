@@ -0,0 +1,88 @@
+package stdlib
+
+import (
+	loxerr "golox/error"
+	"golox/interpreter"
+	"math"
+	"math/rand"
+	"time"
+)
+
+func registerMath(i *interpreter.Interpreter) {
+	i.RegisterNative("sqrt", 1, unary("sqrt", math.Sqrt))
+	i.RegisterNative("floor", 1, unary("floor", math.Floor))
+	i.RegisterNative("ceil", 1, unary("ceil", math.Ceil))
+	i.RegisterNative("abs", 1, unary("abs", math.Abs))
+	i.RegisterNative("pow", 2, pow)
+	i.RegisterNative("min", interpreter.Variadic, extreme("min", math.Min))
+	i.RegisterNative("max", interpreter.Variadic, extreme("max", math.Max))
+
+	// Every interpreter has its own generator, so seeding it does not affect others
+	//nolint:gosec // Lox programs do not need a cryptographically secure generator
+	generator := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	i.RegisterNative("random", 0, func(_ []interpreter.Value) (interpreter.Value, error) {
+		return generator.Float64(), nil
+	})
+	i.RegisterNative("seed", 1, func(args []interpreter.Value) (interpreter.Value, error) {
+		seed, err := intArg("seed", args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		generator.Seed(int64(seed))
+
+		return nil, nil
+	})
+}
+
+// Wraps a math function of a single number into a native function
+func unary(name string, fn func(float64) float64) interpreter.NativeFunc {
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		n, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(n), nil
+	}
+}
+
+// pow(x, y) returns x to the power of y
+func pow(args []interpreter.Value) (interpreter.Value, error) {
+	x, err := numberArg("pow", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := numberArg("pow", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return math.Pow(x, y), nil
+}
+
+// Creates min and max, which pick one of any number of numbers
+func extreme(name string, pick func(float64, float64) float64) interpreter.NativeFunc {
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		if len(args) == 0 {
+			return nil, loxerr.Errorf(loxerr.TypeError, "%s: expected at least 1 argument", name)
+		}
+
+		result, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := 1; idx < len(args); idx++ {
+			n, err := numberArg(name, args, idx)
+			if err != nil {
+				return nil, err
+			}
+			result = pick(result, n)
+		}
+
+		return result, nil
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Package stdlib implements the standard library of the GoLox language.
+
+The standard library is a set of native functions that are registered into the
+globals of an interpreter:
+
+  - core functions: clock, type, str, num, len, list, freeze and isFrozen
+  - math functions: sqrt, floor, ceil, abs, pow, min, max, random and seed
+  - string functions: substring, indexOf, upper, lower, split and trim
+
+Every function checks the number and the types of its arguments. Errors are
+reported as runtime errors at the position of the call, arguments of the wrong
+type as TypeErrors and indices out of bounds as IndexErrors.
+*/
+package stdlib
+
+import (
+	"fmt"
+	loxerr "golox/error"
+	"golox/interpreter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Register defines all the standard library functions in the globals of the interpreter
+func Register(i *interpreter.Interpreter) {
+	registerCore(i)
+	registerMath(i)
+	registerStrings(i)
+}
+
+func registerCore(i *interpreter.Interpreter) {
+	i.RegisterNative("clock", 0, clock(i))
+	i.RegisterNative("type", 1, typeOf)
+	i.RegisterNative("str", 1, str(i))
+	i.RegisterNative("num", 1, num)
+	i.RegisterNative("len", 1, length)
+	i.RegisterNative("list", 1, list(i))
+	i.RegisterNative("freeze", 1, freeze)
+	i.RegisterNative("isFrozen", 1, isFrozen)
+}
+
+// clock() returns the number of seconds since the Unix epoch, told by the clock
+// of the interpreter that the timers wait on
+func clock(i *interpreter.Interpreter) interpreter.NativeFunc {
+	return func(_ []interpreter.Value) (interpreter.Value, error) {
+		return float64(i.Clock().Now().UnixNano()) / float64(time.Second), nil
+	}
+}
+
+// type(x) returns the name of the type of x
+func typeOf(args []interpreter.Value) (interpreter.Value, error) {
+	return interpreter.TypeName(args[0]), nil
+}
+
+// str(x) returns the string representation of x, given by the __str method
+// for instances that define one
+func str(i *interpreter.Interpreter) interpreter.NativeFunc {
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		s, err := i.ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// num(s) parses a number from a string. Numbers are returned as is
+func num(args []interpreter.Value) (interpreter.Value, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("num: cannot convert '%s' to a number", v)
+		}
+		return n, nil
+	}
+
+	return nil, typeError("num", 1, "a string", args[0])
+}
+
+// len(x) returns the number of characters in a string, elements in a list or
+// entries in a map
+func length(args []interpreter.Value) (interpreter.Value, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(len([]rune(v))), nil
+	case *interpreter.List:
+		return float64(len(v.Elements)), nil
+	case *interpreter.Map:
+		return float64(v.Len()), nil
+	}
+
+	return nil, typeError("len", 1, "a string, a list or a map", args[0])
+}
+
+// list(x) returns a list of the elements of an iterable, such as the values
+// yielded by a generator
+func list(i *interpreter.Interpreter) interpreter.NativeFunc {
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		if !interpreter.IsIterable(args[0]) {
+			return nil, typeError("list", 1, "an iterable", args[0])
+		}
+
+		elements, err := i.Collect(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return elements, nil
+	}
+}
+
+// freeze(x) makes an instance, a list or a map read-only and returns it. Other
+// values are returned as they are
+func freeze(args []interpreter.Value) (interpreter.Value, error) {
+	return interpreter.Freeze(args[0]), nil
+}
+
+// isFrozen(x) returns whether x can't be modified
+func isFrozen(args []interpreter.Value) (interpreter.Value, error) {
+	return interpreter.IsFrozen(args[0]), nil
+}
+
+// Returns the error for an argument of the wrong type
+func typeError(function string, position int, expected string, actual interpreter.Value) error {
+	return loxerr.Errorf(loxerr.TypeError, "%s: argument %d must be %s but got %s", function, position, expected, interpreter.TypeName(actual))
+}
+
+// Returns the argument at the given index as a number
+func numberArg(function string, args []interpreter.Value, idx int) (float64, error) {
+	if n, ok := args[idx].(float64); ok {
+		return n, nil
+	}
+
+	return 0, typeError(function, idx+1, "a number", args[idx])
+}
+
+// Returns the argument at the given index as an integer
+func intArg(function string, args []interpreter.Value, idx int) (int, error) {
+	n, err := numberArg(function, args, idx)
+	if err != nil {
+		return 0, err
+	}
+
+	if n != float64(int(n)) {
+		return 0, typeError(function, idx+1, "an integer", args[idx])
+	}
+
+	return int(n), nil
+}
+
+// Returns the argument at the given index as a string
+func stringArg(function string, args []interpreter.Value, idx int) (string, error) {
+	if s, ok := args[idx].(string); ok {
+		return s, nil
+	}
+
+	return "", typeError(function, idx+1, "a string", args[idx])
+}
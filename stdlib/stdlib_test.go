@@ -0,0 +1,92 @@
+package stdlib_test
+
+import (
+	"golox/golox"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStdlib(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected golox.Value
+	}{
+		{name: "type of a number", source: "type(1)", expected: "number"},
+		{name: "type of a function", source: "type(clock)", expected: "function"},
+		{name: "str", source: "str(1.5) + str(true) + str(null)", expected: "1.5truenull"},
+		{name: "str of an instance with __str", source: "class P { __str() { return \"p\"; } } str([P()]);", expected: "[p]"},
+		{name: "num", source: "num(\" 42 \") + 1", expected: 43.0},
+		{name: "len of a string", source: "len(\"héllo\")", expected: 5.0},
+		{name: "len of a list", source: "len(split(\"a,b,c\", \",\"))", expected: 3.0},
+		{name: "len of a map", source: "len({\"a\": 1, \"b\": 2})", expected: 2.0},
+		{name: "clock", source: "clock() > 0", expected: true},
+		{name: "sqrt", source: "sqrt(16)", expected: 4.0},
+		{name: "floor and ceil", source: "floor(1.5) + ceil(1.5)", expected: 3.0},
+		{name: "abs", source: "abs(-3)", expected: 3.0},
+		{name: "pow", source: "pow(2, 10)", expected: 1024.0},
+		{name: "min", source: "min(3, 1, 2)", expected: 1.0},
+		{name: "max", source: "max(3, 1, 2)", expected: 3.0},
+		{name: "random is in range", source: "var r = random(); r >= 0 and r < 1;", expected: true},
+		{name: "seeded random is repeatable", source: "seed(7); var a = random(); seed(7); a == random();", expected: true},
+		{name: "substring", source: "substring(\"héllo\", 1, 3)", expected: "él"},
+		{name: "indexOf", source: "indexOf(\"héllo\", \"l\")", expected: 2.0},
+		{name: "indexOf not found", source: "indexOf(\"hello\", \"x\")", expected: -1.0},
+		{name: "upper and lower", source: "upper(\"ab\") + lower(\"CD\")", expected: "ABcd"},
+		{name: "trim", source: "trim(\"  lox \")", expected: "lox"},
+		{name: "split", source: "str(split(\"a b\", \" \"))", expected: "[a, b]"},
+		{name: "list of a generator", source: "fun g() { yield 1; yield 2; } str(list(g()));", expected: "[1, 2]"},
+		{name: "list of a string", source: "str(list(\"ab\"))", expected: "[a, b]"},
+		{name: "freeze returns its argument", source: "var xs = [1]; freeze(xs) == xs;", expected: true},
+		{name: "Wrong type is a TypeError", source: "var e; try { sqrt(\"x\"); } catch (error) { e = error; } str(e);", expected: "TypeError instance"},
+		{name: "len of a number is a TypeError", source: "var e; try { len(1); } catch (error) { e = error; } str(e);", expected: "TypeError instance"},
+		{name: "Out of bounds is an IndexError", source: "var e; try { substring(\"abc\", 1, 4); } catch (error) { e = error; } str(e);", expected: "IndexError instance"},
+		{name: "Invalid number is a RuntimeError", source: "var e; try { num(\"abc\"); } catch (error) { e = error; } str(e);", expected: "RuntimeError instance"},
+		{name: "isFrozen", source: "var xs = [1]; var before = isFrozen(xs); freeze(xs); str([before, isFrozen(xs), isFrozen(1)]);", expected: "[false, true, true]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := golox.NewVM(golox.Options{Stdout: io.Discard, Stderr: io.Discard})
+
+			actual, err := vm.Eval(tt.source)
+			if err != nil {
+				t.Fatalf("Eval() returned an error: %v", err)
+			}
+
+			if actual != tt.expected {
+				t.Errorf("Eval() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStdlib_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		expectedErr string
+	}{
+		{name: "Wrong arity", source: "sqrt(1, 2)", expectedErr: "[Pos 1:10] Error at ')': Expected 1 arguments but got 2."},
+		{name: "Wrong type", source: "sqrt(\"4\")", expectedErr: "[Pos 1:9] Error at ')': sqrt: argument 1 must be a number but got string"},
+		{name: "Not an integer", source: "substring(\"abc\", 0.5, 1)", expectedErr: "substring: argument 2 must be an integer but got number"},
+		{name: "Out of bounds", source: "substring(\"abc\", 1, 4)", expectedErr: "substring: range [1, 4) is out of bounds for a string of length 3"},
+		{name: "Invalid number", source: "num(\"abc\")", expectedErr: "num: cannot convert 'abc' to a number"},
+		{name: "No arguments", source: "max()", expectedErr: "max: expected at least 1 argument"},
+		{name: "len of a number", source: "len(1)", expectedErr: "len: argument 1 must be a string, a list or a map but got number"},
+		{name: "list of a number", source: "list(1)", expectedErr: "list: argument 1 must be an iterable but got number"},
+		{name: "list of a failing generator", source: "fun g() { yield 1; throw \"boom\"; } list(g());", expectedErr: "Uncaught boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := golox.NewVM(golox.Options{Stdout: io.Discard, Stderr: io.Discard})
+
+			_, err := vm.Eval(tt.source)
+			if err == nil || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error '%s' but got '%v'", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package stdlib
+
+import (
+	loxerr "golox/error"
+	"golox/interpreter"
+	"strings"
+)
+
+// String functions work on characters instead of bytes, so the indices are
+// consistent with len()
+func registerStrings(i *interpreter.Interpreter) {
+	i.RegisterNative("substring", 3, substring)
+	i.RegisterNative("indexOf", 2, indexOf)
+	i.RegisterNative("upper", 1, transform("upper", strings.ToUpper))
+	i.RegisterNative("lower", 1, transform("lower", strings.ToLower))
+	i.RegisterNative("trim", 1, transform("trim", strings.TrimSpace))
+	i.RegisterNative("split", 2, split)
+}
+
+// substring(s, start, end) returns the characters of s from start up to, but not including, end
+func substring(args []interpreter.Value) (interpreter.Value, error) {
+	s, err := stringArg("substring", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := intArg("substring", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := intArg("substring", args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	if start < 0 || end > len(runes) || start > end {
+		return nil, loxerr.Errorf(loxerr.IndexError, "substring: range [%d, %d) is out of bounds for a string of length %d", start, end, len(runes))
+	}
+
+	return string(runes[start:end]), nil
+}
+
+// indexOf(s, sub) returns the index of the first occurrence of sub in s, or -1
+func indexOf(args []interpreter.Value) (interpreter.Value, error) {
+	s, err := stringArg("indexOf", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := stringArg("indexOf", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := strings.Index(s, sub)
+	if idx < 0 {
+		return -1.0, nil
+	}
+
+	return float64(len([]rune(s[:idx]))), nil
+}
+
+// split(s, sep) splits s around every occurrence of sep into a list of strings
+func split(args []interpreter.Value) (interpreter.Value, error) {
+	s, err := stringArg("split", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := stringArg("split", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, sep)
+
+	elements := make([]interpreter.Value, len(parts))
+	for idx, part := range parts {
+		elements[idx] = part
+	}
+
+	return interpreter.NewList(elements), nil
+}
+
+// Wraps a string function into a native function
+func transform(name string, fn func(string) string) interpreter.NativeFunc {
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		s, err := stringArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(s), nil
+	}
+}
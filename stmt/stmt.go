@@ -16,14 +16,22 @@ type Stmt interface {
 // Visitor is the interface that all visitors must implement
 type Visitor interface {
 	VisitBlockStmt(stmt *Block) interface{}
+	VisitBreakStmt(stmt *Break) interface{}
 	VisitClassStmt(stmt *Class) interface{}
+	VisitContinueStmt(stmt *Continue) interface{}
 	VisitExpressionStmt(stmt *Expression) interface{}
+	VisitForInStmt(stmt *ForIn) interface{}
 	VisitFunctionStmt(stmt *Function) interface{}
 	VisitIfStmt(stmt *If) interface{}
+	VisitImportStmt(stmt *Import) interface{}
 	VisitPrintStmt(stmt *Print) interface{}
 	VisitReturnStmt(stmt *Return) interface{}
+	VisitThrowStmt(stmt *Throw) interface{}
+	VisitTraitStmt(stmt *Trait) interface{}
+	VisitTryStmt(stmt *Try) interface{}
 	VisitVarStmt(stmt *Var) interface{}
 	VisitWhileStmt(stmt *While) interface{}
+	VisitYieldStmt(stmt *Yield) interface{}
 }
 
 // Block represents a block statement
@@ -36,11 +44,52 @@ func (s *Block) Accept(v Visitor) interface{} {
 	return v.VisitBlockStmt(s)
 }
 
-// Class represents a class statement
+// BlockNode implements the expr.Block interface
+func (s *Block) BlockNode() {}
+
+// Statements returns the statements of a block in the body of a lambda or of a
+// match arm
+func Statements(block expr.Block) []Stmt {
+	return block.(*Block).Statements
+}
+
+// Break represents a break statement, leaving the innermost loop
+type Break struct {
+	Keyword *token.Token
+}
+
+// Accept implements the Stmt interface
+func (s *Break) Accept(v Visitor) interface{} {
+	return v.VisitBreakStmt(s)
+}
+
+// Class represents a class statement. The members of the traits are mixed into
+// the class, between its own members and the ones of the superclass
 type Class struct {
 	Name       *token.Token
 	Superclass *expr.Variable
-	Methods    []*Function
+	Traits     []*expr.Variable
+	Members
+}
+
+// Members are the methods declared in the body of a class or a trait. Static
+// methods are called on the class itself, getters have no parameters and run
+// when the property is read, setters have a single parameter and run when the
+// property is assigned. Fields only declare the types of the properties of the
+// instances, for the static checker
+type Members struct {
+	Methods       []*Function
+	StaticMethods []*Function
+	Getters       []*Function
+	Setters       []*Function
+	Fields        []*Field
+}
+
+// Field declares the type of a property of the instances of a class, such as
+// "x: number;". The interpreter ignores it
+type Field struct {
+	Name *token.Token
+	Type *expr.Type
 }
 
 // Accept implements the Stmt interface
@@ -48,6 +97,17 @@ func (s *Class) Accept(v Visitor) interface{} {
 	return v.VisitClassStmt(s)
 }
 
+// Continue represents a continue statement, skipping to the next iteration of
+// the innermost loop
+type Continue struct {
+	Keyword *token.Token
+}
+
+// Accept implements the Stmt interface
+func (s *Continue) Accept(v Visitor) interface{} {
+	return v.VisitContinueStmt(s)
+}
+
 // Expression represents an expression statement
 type Expression struct {
 	Expression expr.Expr
@@ -58,11 +118,32 @@ func (s *Expression) Accept(v Visitor) interface{} {
 	return v.VisitExpressionStmt(s)
 }
 
+// ForIn represents a for-in statement, running the body once for every element
+// of an iterable. With a single variable, Name is bound to the element. With two
+// variables, Key is bound to the key of a map entry or to the index of the
+// element, and Name to the value
+type ForIn struct {
+	Keyword  *token.Token // The "in" keyword, where errors of the iteration are reported
+	Key      *token.Token
+	Name     *token.Token
+	Iterable expr.Expr
+	Body     Stmt
+	Constant bool // Whether the loop variables are declared with "const" or "let"
+}
+
+// Accept implements the Stmt interface
+func (s *ForIn) Accept(v Visitor) interface{} {
+	return v.VisitForInStmt(s)
+}
+
 // Function represents a function statement
 type Function struct {
-	Name   *token.Token
-	Params []*token.Token
-	Body   []Stmt
+	Name       *token.Token
+	Params     []*expr.Param
+	Body       []Stmt
+	Generator  bool       // Whether the body yields, calling the function then creates a generator
+	Async      bool       // Whether the function is async, calling it then returns a promise
+	ReturnType *expr.Type // The annotated return type, nil if the function has none
 }
 
 // Accept implements the Stmt interface
@@ -82,6 +163,20 @@ func (s *If) Accept(v Visitor) interface{} {
 	return v.VisitIfStmt(s)
 }
 
+// Import represents an import statement. Either the whole module is bound to
+// the alias, or the exports listed in names are bound to the same names
+type Import struct {
+	Keyword *token.Token
+	Path    *token.Token
+	Alias   *token.Token
+	Names   []*token.Token
+}
+
+// Accept implements the Stmt interface
+func (s *Import) Accept(v Visitor) interface{} {
+	return v.VisitImportStmt(s)
+}
+
 // Print represents a print statement
 type Print struct {
 	Expression expr.Expr
@@ -103,10 +198,52 @@ func (s *Return) Accept(v Visitor) interface{} {
 	return v.VisitReturnStmt(s)
 }
 
-// Var represents a var statement
+// Throw represents a throw statement, raising an exception with the given value
+type Throw struct {
+	Keyword *token.Token
+	Value   expr.Expr
+}
+
+// Accept implements the Stmt interface
+func (s *Throw) Accept(v Visitor) interface{} {
+	return v.VisitThrowStmt(s)
+}
+
+// Trait represents a trait statement, declaring members to be mixed into classes
+type Trait struct {
+	Name *token.Token
+	Members
+}
+
+// Accept implements the Stmt interface
+func (s *Trait) Accept(v Visitor) interface{} {
+	return v.VisitTraitStmt(s)
+}
+
+// Try represents a try statement. At least one of the catch and finally blocks
+// is set, the catch block binds the caught exception to CatchName
+type Try struct {
+	Keyword   *token.Token
+	Body      []Stmt
+	CatchName *token.Token
+	Catch     []Stmt
+	Finally   []Stmt
+}
+
+// Accept implements the Stmt interface
+func (s *Try) Accept(v Visitor) interface{} {
+	return v.VisitTryStmt(s)
+}
+
+// Var represents a var statement. A destructuring declaration, such as
+// "var [a, b] = pair;", has a Pattern binding the variables instead of a Name.
+// Variables declared with "const" or "let" are constants, which can't be assigned
 type Var struct {
 	Name        *token.Token
+	Type        *expr.Type // The annotated type, nil if the variable has none
+	Pattern     expr.Pattern
 	Initializer expr.Expr
+	Constant    bool
 }
 
 // Accept implements the Stmt interface
@@ -114,13 +251,28 @@ func (s *Var) Accept(v Visitor) interface{} {
 	return v.VisitVarStmt(s)
 }
 
-// While represents a while statement
+// While represents a while statement. The increment is only set for loops
+// desugared from a for statement, it is evaluated after every iteration of the
+// body, including the ones ended by a continue statement
 type While struct {
 	Condition expr.Expr
 	Body      Stmt
+	Increment expr.Expr
 }
 
 // Accept implements the Stmt interface
 func (s *While) Accept(v Visitor) interface{} {
 	return v.VisitWhileStmt(s)
 }
+
+// Yield represents a yield statement, suspending the generator it appears in
+// with the given value
+type Yield struct {
+	Keyword *token.Token
+	Value   expr.Expr
+}
+
+// Accept implements the Stmt interface
+func (s *Yield) Accept(v Visitor) interface{} {
+	return v.VisitYieldStmt(s)
+}
@@ -20,29 +20,51 @@ type Token struct {
 //nolint:revive,stylecheck // Constants are in uppercase
 const (
 	// Single-character tokens
-	LEFT_PAREN  = "("
-	RIGHT_PAREN = ")"
-	LEFT_BRACE  = "{"
-	RIGHT_BRACE = "}"
-	COMMA       = ","
-	DOT         = "."
-	MINUS       = "-"
-	PLUS        = "+"
-	SEMICOLON   = ";"
-	SLASH       = "/"
-	STAR        = "*"
-	QUESTION    = "?"
-	COLON       = ":"
+	LEFT_PAREN    = "("
+	RIGHT_PAREN   = ")"
+	LEFT_BRACE    = "{"
+	RIGHT_BRACE   = "}"
+	LEFT_BRACKET  = "["
+	RIGHT_BRACKET = "]"
+	COMMA         = ","
+	DOT           = "."
+	MINUS         = "-"
+	PLUS          = "+"
+	SEMICOLON     = ";"
+	SLASH         = "/"
+	STAR          = "*"
+	PERCENT       = "%"
+	AMPERSAND     = "&"
+	PIPE          = "|"
+	CARET         = "^"
+	TILDE         = "~"
+	QUESTION      = "?"
+	COLON         = ":"
 
 	// One or two character tokens
-	BANG          = "!"
-	BANG_EQUAL    = "!="
-	EQUAL         = "="
-	EQUAL_EQUAL   = "=="
-	GREATER       = ">"
-	GREATER_EQUAL = ">="
-	LESS          = "<"
-	LESS_EQUAL    = "<="
+	BANG            = "!"
+	BANG_EQUAL      = "!="
+	EQUAL           = "="
+	EQUAL_EQUAL     = "=="
+	GREATER         = ">"
+	GREATER_EQUAL   = ">="
+	LESS            = "<"
+	LESS_EQUAL      = "<="
+	PLUS_EQUAL      = "+="
+	MINUS_EQUAL     = "-="
+	STAR_EQUAL      = "*="
+	SLASH_EQUAL     = "/="
+	PERCENT_EQUAL   = "%="
+	PLUS_PLUS       = "++"
+	MINUS_MINUS     = "--"
+	SLASH_SLASH     = "//"
+	STAR_STAR       = "**"
+	LESS_LESS       = "<<"
+	GREATER_GREATER = ">>"
+	ARROW           = "=>"
+	ELLIPSIS        = "..."
+	DOT_DOT         = ".."
+	DOT_DOT_LESS    = "..<"
 
 	// Literals
 	IDENTIFIER = "IDENTIFIER"
@@ -50,22 +72,41 @@ const (
 	NUMBER     = "NUMBER"
 
 	// Keywords
-	AND    = "AND"
-	CLASS  = "CLASS"
-	ELSE   = "ELSE"
-	FALSE  = "FALSE"
-	FUN    = "FUN"
-	FOR    = "FOR"
-	IF     = "IF"
-	NULL   = "NULL"
-	OR     = "OR"
-	PRINT  = "PRINT"
-	RETURN = "RETURN"
-	SUPER  = "SUPER"
-	THIS   = "THIS"
-	TRUE   = "TRUE"
-	VAR    = "VAR"
-	WHILE  = "WHILE"
+	AND      = "AND"
+	AS       = "AS"
+	ASYNC    = "ASYNC"
+	AWAIT    = "AWAIT"
+	BREAK    = "BREAK"
+	CATCH    = "CATCH"
+	CLASS    = "CLASS"
+	CONST    = "CONST"
+	CONTINUE = "CONTINUE"
+	ELSE     = "ELSE"
+	FALSE    = "FALSE"
+	FINALLY  = "FINALLY"
+	FROM     = "FROM"
+	FUN      = "FUN"
+	FOR      = "FOR"
+	IF       = "IF"
+	IMPORT   = "IMPORT"
+	IN       = "IN"
+	LET      = "LET"
+	MATCH    = "MATCH"
+	NULL     = "NULL"
+	OR       = "OR"
+	PRINT    = "PRINT"
+	RETURN   = "RETURN"
+	SPAWN    = "SPAWN"
+	SUPER    = "SUPER"
+	THIS     = "THIS"
+	THROW    = "THROW"
+	TRAIT    = "TRAIT"
+	TRUE     = "TRUE"
+	TRY      = "TRY"
+	VAR      = "VAR"
+	WHILE    = "WHILE"
+	WITH     = "WITH"
+	YIELD    = "YIELD"
 
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
@@ -73,22 +114,41 @@ const (
 
 // Keywords is a map of all the reserved keywords in the language
 var Keywords = map[string]Type{
-	"and":    AND,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"for":    FOR,
-	"fun":    FUN,
-	"if":     IF,
-	"null":   NULL,
-	"or":     OR,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+	"and":      AND,
+	"as":       AS,
+	"async":    ASYNC,
+	"await":    AWAIT,
+	"break":    BREAK,
+	"catch":    CATCH,
+	"class":    CLASS,
+	"const":    CONST,
+	"continue": CONTINUE,
+	"else":     ELSE,
+	"false":    FALSE,
+	"finally":  FINALLY,
+	"for":      FOR,
+	"from":     FROM,
+	"fun":      FUN,
+	"if":       IF,
+	"import":   IMPORT,
+	"in":       IN,
+	"let":      LET,
+	"match":    MATCH,
+	"null":     NULL,
+	"or":       OR,
+	"print":    PRINT,
+	"return":   RETURN,
+	"spawn":    SPAWN,
+	"super":    SUPER,
+	"this":     THIS,
+	"throw":    THROW,
+	"trait":    TRAIT,
+	"true":     TRUE,
+	"try":      TRY,
+	"var":      VAR,
+	"while":    WHILE,
+	"with":     WITH,
+	"yield":    YIELD,
 }
 
 // New creates a new token